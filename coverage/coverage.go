@@ -1,4 +1,5 @@
-// Package coverage is for writing coverage reports.
+// Package coverage is for loading Go coverprofiles, measuring code coverage,
+// and writing coverage reports.
 package coverage
 
 import (
@@ -21,6 +22,8 @@ import (
 
 var generatedFileRegexp = regexp.MustCompile(`(?m:^// Code generated .* DO NOT EDIT\.$)`)
 
+// Coverage is the code coverage of a Go coverprofile, excluding generated
+// files.
 type Coverage struct {
 	profiles []*cover.Profile
 	modPaths map[string]string
@@ -90,20 +93,90 @@ func (cov *Coverage) XML(outPath string) error {
 // value returned will always be between 0 and 1. If there are no statements
 // then 1 is returned.
 func (cov *Coverage) Ratio() float64 {
-	statementCnt := 0
-	statementHit := 0
+	return ratio(cov.profiles)
+}
+
+// PackageRatios returns the Ratio of each package, keyed by import path.
+func (cov *Coverage) PackageRatios() map[string]float64 {
+	ratios := make(map[string]float64)
+	for pkg, count := range cov.PackageStatements() {
+		ratios[pkg] = count.Ratio()
+	}
+	return ratios
+}
+
+// StatementCount is the number of covered statements and the number of all
+// statements in some code.
+type StatementCount struct {
+	Covered int
+	Total   int
+}
+
+// Ratio returns the ratio of covered statements over all statements, or 1
+// if there are no statements.
+func (c StatementCount) Ratio() float64 {
+	if c.Total == 0 {
+		return 1
+	}
+	return float64(c.Covered) / float64(c.Total)
+}
+
+// PackageStatements returns the StatementCount of each package, keyed by
+// import path.
+func (cov *Coverage) PackageStatements() map[string]StatementCount {
+	return cov.groupStatements(func(profile *cover.Profile) string { return path.Dir(profile.FileName) })
+}
+
+// FileStatements returns the StatementCount of each file, keyed by the
+// import path of its package followed by its name (e.g.
+// "example.com/pkg/foo.go").
+func (cov *Coverage) FileStatements() map[string]StatementCount {
+	return cov.groupStatements(func(profile *cover.Profile) string { return profile.FileName })
+}
+
+// groupStatements returns the StatementCount of the profiles with each key.
+func (cov *Coverage) groupStatements(key func(*cover.Profile) string) map[string]StatementCount {
+	groups := make(map[string][]*cover.Profile)
 	for _, profile := range cov.profiles {
+		k := key(profile)
+		groups[k] = append(groups[k], profile)
+	}
+	counts := make(map[string]StatementCount, len(groups))
+	for k, profiles := range groups {
+		covered, total := statements(profiles)
+		counts[k] = StatementCount{Covered: covered, Total: total}
+	}
+	return counts
+}
+
+// Statements returns the number of covered statements and the number of
+// all statements.
+func (cov *Coverage) Statements() (covered, total int) {
+	return statements(cov.profiles)
+}
+
+// ratio returns the ratio of covered statements over all statements in the
+// provided profiles, or 1 if there are no statements.
+func ratio(profiles []*cover.Profile) float64 {
+	covered, total := statements(profiles)
+	if total == 0 {
+		return 1
+	}
+	return float64(covered) / float64(total)
+}
+
+// statements returns the number of covered statements and the number of
+// all statements in the provided profiles.
+func statements(profiles []*cover.Profile) (covered, total int) {
+	for _, profile := range profiles {
 		for _, block := range profile.Blocks {
-			statementCnt += block.NumStmt
+			total += block.NumStmt
 			if block.Count > 0 {
-				statementHit += block.NumStmt
+				covered += block.NumStmt
 			}
 		}
 	}
-	if statementCnt == 0 {
-		return 1
-	}
-	return float64(statementHit) / float64(statementCnt)
+	return covered, total
 }
 
 // isGenerated checks if the provided file was generated or not. The file
@@ -24,15 +24,15 @@ func Test_Load(t *testing.T) {
 	for i, profile := range result.profiles {
 		files[i] = profile.FileName
 	}
-	require.Contains(t, files, "oss.indeed.com/go/go-opine/internal/coverage/testdata/not_generated.go")
-	require.NotContains(t, files, "oss.indeed.com/go/go-opine/internal/coverage/testdata/generated.go")
+	require.Contains(t, files, "oss.indeed.com/go/go-opine/coverage/testdata/not_generated.go")
+	require.NotContains(t, files, "oss.indeed.com/go/go-opine/coverage/testdata/generated.go")
 
 	// Check that the modPaths is populated and correct.
 	expectedModPath, err := filepath.Abs("./testdata")
 	require.NoError(t, err)
 	require.Equal(
 		t,
-		map[string]string{"oss.indeed.com/go/go-opine/internal/coverage/testdata": expectedModPath},
+		map[string]string{"oss.indeed.com/go/go-opine/coverage/testdata": expectedModPath},
 		result.modPaths,
 	)
 }
@@ -72,7 +72,7 @@ func Test_XML(t *testing.T) {
 	require.NoError(t, err)
 	cov := &Coverage{
 		profiles: profiles,
-		modPaths: map[string]string{"oss.indeed.com/go/go-opine/internal/coverage/testdata": testdata},
+		modPaths: map[string]string{"oss.indeed.com/go/go-opine/coverage/testdata": testdata},
 	}
 	err = cov.XML(outPath)
 	require.NoError(t, err)
@@ -99,6 +99,61 @@ func Test_Ratio_100percent(t *testing.T) {
 	ratio := cov.Ratio()
 	require.Equal(t, 1.0, ratio)
 }
+
+func Test_Statements(t *testing.T) {
+	inPath := filepath.Join("testdata", "cover.out")
+	profiles, err := cover.ParseProfiles(inPath)
+	require.NoError(t, err)
+	cov := &Coverage{profiles: profiles}
+	covered, total := cov.Statements()
+	require.Equal(t, 1, covered)
+	require.Equal(t, 2, total)
+}
+
+func Test_PackageRatios(t *testing.T) {
+	cov := &Coverage{profiles: []*cover.Profile{
+		{FileName: "example.com/a/a.go", Blocks: []cover.ProfileBlock{{NumStmt: 3, Count: 1}, {NumStmt: 1, Count: 0}}},
+		{FileName: "example.com/a/b.go", Blocks: []cover.ProfileBlock{{NumStmt: 4, Count: 0}}},
+		{FileName: "example.com/b/b.go", Blocks: []cover.ProfileBlock{{NumStmt: 2, Count: 2}}},
+		{FileName: "example.com/c/c.go"},
+	}}
+	require.Equal(
+		t,
+		map[string]float64{"example.com/a": 0.375, "example.com/b": 1, "example.com/c": 1},
+		cov.PackageRatios(),
+	)
+}
+
+func Test_PackageStatements_FileStatements(t *testing.T) {
+	cov := &Coverage{profiles: []*cover.Profile{
+		{FileName: "example.com/a/a.go", Blocks: []cover.ProfileBlock{{NumStmt: 3, Count: 1}, {NumStmt: 1, Count: 0}}},
+		{FileName: "example.com/a/b.go", Blocks: []cover.ProfileBlock{{NumStmt: 4, Count: 0}}},
+		{FileName: "example.com/b/b.go", Blocks: []cover.ProfileBlock{{NumStmt: 2, Count: 2}}},
+	}}
+	require.Equal(
+		t,
+		map[string]StatementCount{
+			"example.com/a": {Covered: 3, Total: 8},
+			"example.com/b": {Covered: 2, Total: 2},
+		},
+		cov.PackageStatements(),
+	)
+	require.Equal(
+		t,
+		map[string]StatementCount{
+			"example.com/a/a.go": {Covered: 3, Total: 4},
+			"example.com/a/b.go": {Covered: 0, Total: 4},
+			"example.com/b/b.go": {Covered: 2, Total: 2},
+		},
+		cov.FileStatements(),
+	)
+}
+
+func Test_StatementCount_Ratio(t *testing.T) {
+	require.Equal(t, 0.25, StatementCount{Covered: 1, Total: 4}.Ratio())
+	require.Equal(t, 1.0, StatementCount{}.Ratio())
+}
+
 func Test_isGeneratedReader_veryLongLine(t *testing.T) {
 	veryLongLine := "package foo\n\n" +
 		"// " + strings.Repeat("a", bufio.MaxScanTokenSize+1) +
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// testCountBaseline is the number of tests each package ran, as recorded
+// in the baseline file (see the -test-count-baseline flag).
+type testCountBaseline struct {
+	// Packages are the numbers of tests, including subtests and skipped
+	// tests, keyed by import path.
+	Packages map[string]int `json:"packages"`
+}
+
+// checkTestCounts writes the packages that ran fewer tests than recorded
+// in the baseline, including packages that were not tested at all, and
+// returns a policy failure if there are any. Packages that failed are not
+// checked, since their failure is reported already. If the
+// -update-test-count-baseline flag is set the baseline is written with the
+// test counts instead. Otherwise the test-count-baseline check, with the
+// number of packages that ran fewer tests, is added to sum if it is not
+// nil.
+func (t *testCmd) checkTestCounts(w io.Writer, palette printing.Palette, counts []gotest.TestCount, sum *summary.Summary) []error {
+	baseline, err := readTestCountBaseline(t.testCountBaseline)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && t.updateBaseline:
+		baseline = &testCountBaseline{Packages: make(map[string]int)}
+	case errors.Is(err, os.ErrNotExist):
+		return []error{categorize(errUsage, fmt.Errorf("the test count baseline %s does not exist; create it with -update-test-count-baseline", t.testCountBaseline))}
+	case err != nil:
+		return []error{categorize(errUsage, fmt.Errorf("failed to read the test count baseline: %w", err))}
+	}
+
+	if t.updateBaseline {
+		updated := &testCountBaseline{Packages: make(map[string]int, len(counts))}
+		for _, c := range counts {
+			if c.Outcome != "fail" {
+				updated.Packages[c.Package] = c.Tests
+			} else if n, ok := baseline.Packages[c.Package]; ok {
+				updated.Packages[c.Package] = n
+			}
+		}
+		if err := writeTestCountBaseline(updated, t.testCountBaseline); err != nil {
+			return []error{categorize(errReportFailed, fmt.Errorf("failed to write the test count baseline: %w", err))}
+		}
+		_, _ = fmt.Fprintf(w, "Updated the test count baseline %s.\n", t.testCountBaseline)
+		return nil
+	}
+
+	tested := make(map[string]gotest.TestCount, len(counts))
+	for _, c := range counts {
+		tested[c.Package] = c
+	}
+	var dropped []string
+	increased := 0
+	for _, pkg := range slices.Sorted(maps.Keys(baseline.Packages)) {
+		want := baseline.Packages[pkg]
+		c, ok := tested[pkg]
+		switch {
+		case !ok:
+			dropped = append(dropped, fmt.Sprintf("  %s: not tested (baseline %d)", pkg, want))
+		case c.Outcome == "fail":
+		case c.Tests < want:
+			dropped = append(dropped, fmt.Sprintf("  %s: %d tests (baseline %d)", pkg, c.Tests, want))
+		case c.Tests > want:
+			increased++
+		}
+	}
+	for _, c := range counts {
+		if _, ok := baseline.Packages[c.Package]; !ok && c.Tests > 0 {
+			increased++
+		}
+	}
+	addCountCheck(sum, "test-count-baseline", len(dropped))
+	if increased > 0 {
+		_, _ = fmt.Fprintf(w, "%s ran more tests than in the test count baseline; set -update-test-count-baseline to raise it.\n", countOf(increased, "package"))
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintln(w, palette.Red("Fewer tests ran than in the test count baseline:"))
+	for _, line := range dropped {
+		_, _ = fmt.Fprintln(w, line)
+	}
+	_, _ = fmt.Fprintln(w, "If the tests were removed on purpose, set -update-test-count-baseline to lower the baseline.")
+	return []error{categorize(errPolicyFailed, fmt.Errorf("%s ran fewer tests than in the test count baseline", countOf(len(dropped), "package")))}
+}
+
+// readTestCountBaseline reads the test count baseline at the path.
+func readTestCountBaseline(path string) (*testCountBaseline, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline testCountBaseline
+	if err := json.Unmarshal(in, &baseline); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if baseline.Packages == nil {
+		baseline.Packages = make(map[string]int)
+	}
+	return &baseline, nil
+}
+
+// writeTestCountBaseline writes the test count baseline as JSON to the
+// path, with the packages sorted so that changes are easy to review.
+func writeTestCountBaseline(baseline *testCountBaseline, path string) error {
+	out, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+	return os.WriteFile(path, out, 0666) //nolint:gosec
+}
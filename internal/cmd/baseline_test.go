@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+func Test_ReportCmd_impl_testCountBaseline(t *testing.T) {
+	baselinePath := filepath.Join(t.TempDir(), "test-counts.json")
+	summaryPath := filepath.Join(t.TempDir(), "summary.json")
+	newReportCmd := func(out io.Writer, update bool) reportCmd {
+		return reportCmd{
+			testCmd: testCmd{
+				out:               out,
+				summaryJSON:       summaryPath,
+				maxSkipPercent:    defaultMaxSkipPercent,
+				testCountBaseline: baselinePath,
+				updateBaseline:    update,
+			},
+			input: writeSkipEvents(t),
+		}
+	}
+
+	tested := newReportCmd(io.Discard, false)
+	err := tested.impl()
+	require.ErrorContains(t, err, "create it with -update-test-count-baseline")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+
+	tested = newReportCmd(io.Discard, true)
+	require.NoError(t, tested.impl())
+	baseline, err := os.ReadFile(baselinePath)
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"packages\": {\n    \"example.com/a\": 3\n  }\n}\n", string(baseline))
+	// The baseline is not checked while it is updated.
+	require.Equal(t, []summary.Check{}, readSummary(t, summaryPath).Checks)
+
+	require.NoError(t, os.WriteFile(baselinePath, []byte(`{"packages": {"example.com/a": 2}}`), 0600))
+	var out strings.Builder
+	tested = newReportCmd(&out, false)
+	require.NoError(t, tested.impl())
+	require.Contains(t, out.String(), "1 package ran more tests than in the test count baseline")
+	require.Equal(t, []summary.Check{{Name: "test-count-baseline", Threshold: 0, Actual: 0, Passed: true}}, readSummary(t, summaryPath).Checks)
+
+	require.NoError(t, os.WriteFile(baselinePath, []byte(`{"packages": {"example.com/a": 4, "example.com/b": 1}}`), 0600))
+	out.Reset()
+	tested = newReportCmd(&out, false)
+	err = tested.impl()
+	require.EqualError(t, err, "2 packages ran fewer tests than in the test count baseline")
+	require.Equal(t, exitPolicyFailed, exitStatus(err))
+	require.Contains(
+		t,
+		out.String(),
+		"Fewer tests ran than in the test count baseline:\n"+
+			"  example.com/a: 3 tests (baseline 4)\n"+
+			"  example.com/b: not tested (baseline 1)\n",
+	)
+	require.Equal(t, []summary.Check{{Name: "test-count-baseline", Threshold: 0, Actual: 2, Passed: false}}, readSummary(t, summaryPath).Checks)
+}
+
+func Test_ReportCmd_impl_updateBaselineWithoutBaseline(t *testing.T) {
+	tested := reportCmd{
+		testCmd: testCmd{out: io.Discard, updateBaseline: true},
+		input:   writeSkipEvents(t),
+	}
+	err := tested.impl()
+	require.EqualError(t, err, "the -update-test-count-baseline flag requires -test-count-baseline")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"oss.indeed.com/go/go-opine/internal/config"
+)
+
+// envPrefix is the prefix of the environment variables that set flags
+// (e.g. GO_OPINE_MIN_COVERAGE for -min-coverage).
+const envPrefix = "GO_OPINE_"
+
+// ConfigCmd returns a subcommand that validates the configuration file.
+func ConfigCmd() subcommands.Command {
+	return &configCmd{out: os.Stdout}
+}
+
+type configCmd struct {
+	out io.Writer
+
+	configPath string
+}
+
+func (*configCmd) Name() string {
+	return "config"
+}
+
+func (*configCmd) Synopsis() string {
+	return "validate the configuration file"
+}
+
+func (*configCmd) Usage() string {
+	return `config validate [-config <path>]:
+  Validate the configuration file (` + config.FileName + ` at the root of the module).
+`
+}
+
+func (c *configCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.configPath, "config", "", "validate this configuration file instead of "+config.FileName+" at the root of the module")
+}
+
+//revive:disable:unused-parameter
+func (c *configCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	if f.NArg() != 1 || f.Arg(0) != "validate" {
+		_, _ = fmt.Fprintln(f.Output(), "expected the \"validate\" command")
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+	err := c.validate()
+	if err != nil {
+		_, _ = fmt.Fprintln(f.Output(), err)
+	}
+	return exitStatus(err)
+}
+
+// validate validates the configuration file.
+func (c *configCmd) validate() error {
+	path := c.configPath
+	if path == "" {
+		var err error
+		if path, err = config.Find("."); err != nil {
+			return err
+		}
+		if path == "" {
+			return categorize(errUsage, fmt.Errorf("there is no %s at the root of the module", config.FileName))
+		}
+	}
+	if _, err := config.Load(path); err != nil {
+		return categorize(errUsage, err)
+	}
+	_, _ = fmt.Fprintf(c.out, "%s is valid\n", path)
+	return nil
+}
+
+// withConfig returns a function that configures the subcommand (see
+// configure) before calling impl.
+func (t *testCmd) withConfig(f *flag.FlagSet, impl func() error) func() error {
+	return func() error {
+		if err := t.configure(f); err != nil {
+			return err
+		}
+		return impl()
+	}
+}
+
+// configure sets the flags that were not set on the command line from the
+// GO_OPINE_* environment variables, or else from the configuration file.
+// The configuration that has no flags (e.g. of "go test" and of the
+// quarantined tests) is read from the configuration file too.
+func (t *testCmd) configure(f *flag.FlagSet) error {
+	set := make(map[string]bool)
+	f.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	path := t.configPath
+	if !set["config"] {
+		path = os.Getenv(envName("config"))
+	}
+	if path == "" {
+		var err error
+		if path, err = config.Find("."); err != nil {
+			return fmt.Errorf("failed to find the configuration file: %w", err)
+		}
+	}
+	values := make(map[string][]string)
+	if path != "" {
+		cfg, err := config.Load(path)
+		if err != nil {
+			return categorize(errUsage, err)
+		}
+		values = configFlagValues(cfg)
+		t.goTestFlags = cfg.Test.Flags
+		t.goTestEnv = nil
+		for _, name := range slices.Sorted(maps.Keys(cfg.Test.Env)) {
+			t.goTestEnv = append(t.goTestEnv, name+"="+cfg.Test.Env[name])
+		}
+		t.exclude = cfg.Test.Exclude
+		t.quarantine = cfg.Quarantine
+	}
+
+	var err error
+	f.VisitAll(func(fl *flag.Flag) {
+		if err != nil || set[fl.Name] || fl.Name == "config" {
+			return
+		}
+		source := "the configuration file"
+		vs := values[fl.Name]
+		if v, ok := os.LookupEnv(envName(fl.Name)); ok {
+			source = envName(fl.Name)
+			vs = []string{v}
+		}
+		for _, v := range vs {
+			if setErr := f.Set(fl.Name, v); setErr != nil {
+				err = categorize(errUsage, fmt.Errorf("invalid value %q for -%s from %s: %w", v, fl.Name, source, setErr))
+				return
+			}
+		}
+	})
+	return err
+}
+
+// configFlagValues returns the values of the flags set by the
+// configuration, keyed by flag name.
+func configFlagValues(cfg *config.Config) map[string][]string {
+	values := make(map[string][]string)
+	setString := func(name, value string) {
+		if value != "" {
+			values[name] = []string{value}
+		}
+	}
+	if cfg.Coverage.Min != nil {
+		values["min-coverage"] = []string{strconv.FormatFloat(*cfg.Coverage.Min, 'f', -1, 64)}
+	}
+	setString("junit", cfg.Reports.JUnit)
+	setString("tap", cfg.Reports.TAP)
+	setString("xmlcov", cfg.Reports.XMLCov)
+	setString("coverprofile", cfg.Reports.CoverProfile)
+	setString("json-out", cfg.Reports.JSONOut)
+	setString("summary-json", cfg.Reports.SummaryJSON)
+	if cfg.Reports.BuildWarnings != nil {
+		values["build-warnings"] = []string{strconv.FormatBool(*cfg.Reports.BuildWarnings)}
+	}
+	for _, r := range cfg.Reporters {
+		name := "reporter"
+		if r.Optional {
+			name = "optional-reporter"
+		}
+		values[name] = append(values[name], r.Command)
+	}
+	if cfg.Test.Race != nil {
+		values["norace"] = []string{strconv.FormatBool(!*cfg.Test.Race)}
+	}
+	if cfg.Skips.RequireReason != nil {
+		values["require-skip-reason"] = []string{strconv.FormatBool(*cfg.Skips.RequireReason)}
+	}
+	if cfg.Skips.MaxPercent != nil {
+		values["max-skip-percent"] = []string{strconv.FormatFloat(*cfg.Skips.MaxPercent, 'f', -1, 64)}
+	}
+	setString("skip-reason-pattern", cfg.Skips.ReasonPattern)
+	if cfg.Test.RequireTests != nil {
+		values["require-tests"] = []string{strconv.FormatBool(*cfg.Test.RequireTests)}
+	}
+	values["allow-untested"] = cfg.Test.AllowUntested
+	setString("test-count-baseline", cfg.Test.CountBaseline)
+	setString("color", cfg.Color)
+	return values
+}
+
+// envName returns the name of the environment variable that sets the
+// flag.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// listPackages returns the packages (./...) except those matching the
+// exclude patterns, as listed by "go list" with the additional
+// environment variables.
+func listPackages(exclude, env []string) ([]string, error) {
+	all, err := goList(env, "./...")
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := goList(env, exclude...)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []string
+	for _, pkg := range all {
+		if !slices.Contains(excluded, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, errors.New("all packages are excluded")
+	}
+	return pkgs, nil
+}
+
+// goList returns the import paths of the packages matching the patterns.
+func goList(env []string, patterns ...string) ([]string, error) {
+	cmd := exec.Command("go", append([]string{"list"}, patterns...)...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/config"
+)
+
+// writeModule writes a module with the configuration file to a temporary
+// directory and returns its path.
+func writeModule(t *testing.T, cfg string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte(cfg), 0600))
+	return dir
+}
+
+// parseFlags returns the flags of the testCmd parsed from the arguments.
+func parseFlags(t *testing.T, tested *testCmd, args ...string) *flag.FlagSet {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.SetOutput(io.Discard)
+	tested.SetFlags(f)
+	require.NoError(t, f.Parse(args))
+	return f
+}
+
+func Test_testCmd_configure(t *testing.T) {
+	dir := writeModule(t, `coverage:
+  min: 80
+reports:
+  junit: junit.xml
+  tap: results.tap
+  build-warnings: true
+reporters:
+  - command: ./required.sh
+  - command: ./optional.sh
+    optional: true
+test:
+  race: false
+  flags: [-tags=integration]
+  exclude: [./gen/...]
+  env:
+    B: "2"
+    A: "1"
+  require-tests: true
+  allow-untested: [example.com/m/gen/..., example.com/m/version]
+  count-baseline: test-counts.json
+skips:
+  require-reason: true
+  max-percent: 25
+  reason-pattern: "[A-Z]+-[0-9]+"
+color: never
+`)
+	popd := pushd(t, dir)
+	defer popd()
+	t.Setenv(envName("tap"), "from-env.tap")
+	t.Setenv(envName("junit"), "from-env.xml")
+
+	var tested testCmd
+	f := parseFlags(t, &tested, "-junit", "from-cli.xml")
+	require.NoError(t, tested.configure(f))
+
+	require.Equal(t, 80.0, tested.minCovPercent)
+	require.Equal(t, "from-cli.xml", tested.junit)
+	require.Equal(t, "from-env.tap", tested.tap)
+	require.True(t, tested.buildWarnings)
+	require.Equal(t, stringsFlag{"./required.sh"}, tested.reporters)
+	require.Equal(t, stringsFlag{"./optional.sh"}, tested.optionalReporters)
+	require.True(t, tested.norace)
+	require.True(t, tested.requireSkipReason)
+	require.Equal(t, 25.0, tested.maxSkipPercent)
+	require.Equal(t, "[A-Z]+-[0-9]+", tested.skipReasonPattern)
+	require.True(t, tested.requireTests)
+	require.Equal(t, stringsFlag{"example.com/m/gen/...", "example.com/m/version"}, tested.allowUntested)
+	require.Equal(t, "test-counts.json", tested.testCountBaseline)
+	require.Equal(t, "never", tested.color)
+	require.Equal(t, []string{"-tags=integration"}, tested.goTestFlags)
+	require.Equal(t, []string{"A=1", "B=2"}, tested.goTestEnv)
+	require.Equal(t, []string{"./gen/..."}, tested.exclude)
+}
+
+func Test_testCmd_configure_configFlag(t *testing.T) {
+	dir := writeModule(t, "color: never\n")
+	other := filepath.Join(t.TempDir(), "other.yaml")
+	require.NoError(t, os.WriteFile(other, []byte("color: always\n"), 0600))
+	popd := pushd(t, dir)
+	defer popd()
+
+	var tested testCmd
+	f := parseFlags(t, &tested, "-config", other)
+	require.NoError(t, tested.configure(f))
+	require.Equal(t, "always", tested.color)
+
+	t.Setenv(envName("config"), other)
+	tested = testCmd{}
+	f = parseFlags(t, &tested)
+	require.NoError(t, tested.configure(f))
+	require.Equal(t, "always", tested.color)
+}
+
+func Test_testCmd_configure_noConfig(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	t.Setenv(envName("min-coverage"), "90")
+
+	var tested testCmd
+	f := parseFlags(t, &tested)
+	require.NoError(t, tested.configure(f))
+	require.Equal(t, 90.0, tested.minCovPercent)
+	require.Nil(t, tested.goTestFlags)
+}
+
+func Test_testCmd_configure_invalidConfig(t *testing.T) {
+	dir := writeModule(t, "coverage:\n  minimum: 80\n")
+	popd := pushd(t, dir)
+	defer popd()
+
+	var tested testCmd
+	f := parseFlags(t, &tested)
+	err := tested.configure(f)
+	require.ErrorContains(t, err, config.FileName+":2:3: unknown key \"minimum\" in coverage")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
+
+func Test_testCmd_configure_invalidEnv(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	t.Setenv(envName("min-coverage"), "lots")
+
+	var tested testCmd
+	f := parseFlags(t, &tested)
+	err := tested.configure(f)
+	require.ErrorContains(t, err, `invalid value "lots" for -min-coverage from GO_OPINE_MIN_COVERAGE`)
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
+
+func Test_TestCmd_impl_exclude(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	summaryPath := filepath.Join(t.TempDir(), "summary.json")
+	tested := testCmd{
+		out:         io.Discard,
+		exclude:     []string{"./testonly"},
+		summaryJSON: summaryPath,
+	}
+	require.NoError(t, tested.impl())
+	out, err := os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "go-library/library")
+	require.NotContains(t, string(out), "go-library/testonly")
+
+	tested.exclude = []string{"./..."}
+	err = tested.impl()
+	require.EqualError(t, err, "failed to exclude packages: all packages are excluded")
+}
+
+func Test_configCmd_validate(t *testing.T) {
+	dir := writeModule(t, "color: never\n")
+	popd := pushd(t, dir)
+	defer popd()
+
+	var out bytes.Buffer
+	tested := configCmd{out: &out}
+	require.NoError(t, tested.validate())
+	require.Equal(t, filepath.Join(dir, config.FileName)+" is valid\n", out.String())
+
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("color: sometimes\n"), 0600))
+	tested.configPath = invalid
+	err := tested.validate()
+	require.EqualError(t, err, invalid+":1:8: color: must be auto, always, or never")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
+
+func Test_configCmd_validate_noConfig(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	tested := configCmd{out: io.Discard}
+	require.EqualError(t, tested.validate(), "there is no "+config.FileName+" at the root of the module")
+}
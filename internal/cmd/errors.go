@@ -2,7 +2,25 @@ package cmd
 
 import (
 	"errors"
+	"slices"
 	"strings"
+
+	"github.com/google/subcommands"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+)
+
+// The exit statuses of the subcommands, in addition to
+// subcommands.ExitSuccess (0), subcommands.ExitFailure (1) for internal
+// errors, and subcommands.ExitUsageError (2). These are documented in the
+// README and must not change.
+const (
+	exitTestsFailed         subcommands.ExitStatus = 3
+	exitBuildFailed         subcommands.ExitStatus = 4
+	exitNoTests             subcommands.ExitStatus = 5
+	exitCoverageCheckFailed subcommands.ExitStatus = 6
+	exitReportFailed        subcommands.ExitStatus = 7
+	exitPolicyFailed        subcommands.ExitStatus = 8
 )
 
 var (
@@ -12,8 +30,162 @@ var (
 
 	// errNoTests is returned by the "test" subcommand when there are no tests.
 	errNoTests = errors.New("no tests")
+
+	// errTestsFailed, errBuildFailed, errReportFailed, errPolicyFailed, and
+	// errUsage are the categories of other errors (see categorize).
+	errTestsFailed  = errors.New("tests failed")
+	errBuildFailed  = errors.New("build failed")
+	errReportFailed = errors.New("report writing failed")
+	errPolicyFailed = errors.New("test policy failed")
+	errUsage        = errors.New("usage error")
 )
 
+// exitStatusCategories are the names of the categories of the exit
+// statuses, as used in the JSON summary.
+var exitStatusCategories = map[subcommands.ExitStatus]string{
+	exitBuildFailed:            "build-failed",
+	exitTestsFailed:            "tests-failed",
+	subcommands.ExitFailure:    "internal-error",
+	subcommands.ExitUsageError: "usage-error",
+	exitNoTests:                "no-tests",
+	exitReportFailed:           "report-failed",
+	exitCoverageCheckFailed:    "coverage-check-failed",
+	exitPolicyFailed:           "policy-failed",
+}
+
+// exitStatusPriority orders the exit statuses from most to least
+// important.
+var exitStatusPriority = []subcommands.ExitStatus{
+	exitBuildFailed,
+	exitTestsFailed,
+	subcommands.ExitFailure,
+	subcommands.ExitUsageError,
+	exitNoTests,
+	exitPolicyFailed,
+	exitReportFailed,
+	exitCoverageCheckFailed,
+}
+
+// exitStatus returns the exit status for an error returned by a
+// subcommand. If the error combines several errors (see CombineErrors) the
+// exit status of the most important one is returned, so that e.g. a test
+// failure is never reported as only a coverage check failure.
+func exitStatus(err error) subcommands.ExitStatus {
+	if err == nil {
+		return subcommands.ExitSuccess
+	}
+	leaves := leafErrors(err)
+	status := errorExitStatus(leaves[0])
+	for _, leaf := range leaves[1:] {
+		if s := errorExitStatus(leaf); slices.Index(exitStatusPriority, s) < slices.Index(exitStatusPriority, status) {
+			status = s
+		}
+	}
+	return status
+}
+
+// errorExitStatus returns the exit status for the category of the error.
+// An error without a category is an internal error.
+func errorExitStatus(err error) subcommands.ExitStatus {
+	switch {
+	case errors.Is(err, errBuildFailed):
+		return exitBuildFailed
+	case errors.Is(err, errTestsFailed):
+		return exitTestsFailed
+	case errors.Is(err, errUsage):
+		return subcommands.ExitUsageError
+	case errors.Is(err, errNoTests):
+		return exitNoTests
+	case errors.Is(err, errReportFailed):
+		return exitReportFailed
+	case errors.Is(err, errCoverageCheckFailed):
+		return exitCoverageCheckFailed
+	case errors.Is(err, errPolicyFailed):
+		return exitPolicyFailed
+	default:
+		return subcommands.ExitFailure
+	}
+}
+
+// leafErrors returns the errors combined by CombineErrors (recursively), or
+// the error itself if it does not combine errors.
+func leafErrors(err error) []error {
+	var m multiError
+	if !errors.As(err, &m) {
+		return []error{err}
+	}
+	var leaves []error
+	for _, err := range m {
+		leaves = append(leaves, leafErrors(err)...)
+	}
+	return leaves
+}
+
+// categorizedError is an error that matches (see errors.Is) the category
+// it is in, in addition to the errors it wraps.
+type categorizedError struct {
+	err      error
+	category error
+}
+
+func (e categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e categorizedError) Unwrap() []error {
+	return []error{e.err, e.category}
+}
+
+// categorize returns the error in the category (e.g. errReportFailed),
+// without changing its message.
+func categorize(category, err error) error {
+	return categorizedError{err: err, category: category}
+}
+
+// categorizeTestErr returns the error returned by gotest.Run or
+// gotest.Report in the category of the failure. Errors other than test and
+// build failures are internal errors, which have no category.
+func categorizeTestErr(err error) error {
+	switch {
+	case errors.Is(err, gotest.ErrBuildFailed):
+		return categorize(errBuildFailed, err)
+	case errors.Is(err, gotest.ErrTestsFailed):
+		return categorize(errTestsFailed, err)
+	default:
+		return err
+	}
+}
+
+// appendErrors combines err with the other errors (see CombineErrors).
+// The errors err combines are combined individually, so that the result
+// is not nested.
+func appendErrors(err error, errs ...error) error {
+	if err == nil {
+		return CombineErrors(errs)
+	}
+	return CombineErrors(append(leafErrors(err), errs...))
+}
+
+// multiError is an error that combines several errors. Like the error
+// errors.Join returns it matches (see errors.Is and errors.As) each of
+// them.
+type multiError []error
+
+func (m multiError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("multiple errors occurred:\n")
+	for _, err := range m {
+		sb.WriteString("  * " + err.Error() + "\n")
+	}
+	return sb.String()
+}
+
+func (m multiError) Unwrap() []error {
+	return m
+}
+
+// CombineErrors combines the errors into one. It returns nil if there are
+// no errors and the error itself if there is only one.
 func CombineErrors(errs []error) error {
 	if len(errs) == 0 {
 		return nil
@@ -21,10 +193,5 @@ func CombineErrors(errs []error) error {
 	if len(errs) == 1 {
 		return errs[0]
 	}
-	var sb strings.Builder
-	sb.WriteString("multiple errors occurred:\n")
-	for _, err := range errs {
-		sb.WriteString("  * " + err.Error() + "\n")
-	}
-	return errors.New(sb.String())
+	return multiError(errs)
 }
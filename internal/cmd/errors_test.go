@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+)
+
+func Test_exitStatus(t *testing.T) {
+	testsFailed := categorizeTestErr(fmt.Errorf("unit tests failed: %w", testsFailedErr(t, false)))
+	buildFailed := categorizeTestErr(fmt.Errorf("unit tests failed: %w", testsFailedErr(t, true)))
+	reportFailed := categorize(errReportFailed, errors.New("failed to write JUnit XML"))
+	policyFailed := categorize(errPolicyFailed, errors.New("quarantine expired"))
+	internal := errors.New("failed to load coverage")
+	for _, tc := range []struct {
+		err      error
+		expected subcommands.ExitStatus
+	}{
+		{nil, subcommands.ExitSuccess},
+		{internal, subcommands.ExitFailure},
+		{categorize(errUsage, errors.New("bad flag")), subcommands.ExitUsageError},
+		{testsFailed, exitTestsFailed},
+		{buildFailed, exitBuildFailed},
+		{errNoTests, exitNoTests},
+		{errCoverageCheckFailed, exitCoverageCheckFailed},
+		{reportFailed, exitReportFailed},
+		{policyFailed, exitPolicyFailed},
+		{CombineErrors([]error{reportFailed, errCoverageCheckFailed}), exitReportFailed},
+		{CombineErrors([]error{reportFailed, policyFailed}), exitPolicyFailed},
+		{CombineErrors([]error{policyFailed, testsFailed}), exitTestsFailed},
+		{CombineErrors([]error{errCoverageCheckFailed, testsFailed}), exitTestsFailed},
+		{CombineErrors([]error{testsFailed, buildFailed}), exitBuildFailed},
+		{CombineErrors([]error{errCoverageCheckFailed, internal}), subcommands.ExitFailure},
+		{CombineErrors([]error{internal, testsFailed}), exitTestsFailed},
+		{CombineErrors([]error{testsFailed, errNoTests}), exitTestsFailed},
+		{fmt.Errorf("wrapped: %w", CombineErrors([]error{internal, reportFailed})), subcommands.ExitFailure},
+	} {
+		require.Equal(t, tc.expected, exitStatus(tc.err), "%v", tc.err)
+	}
+}
+
+// testsFailedErr returns the error gotest.Report returns when a package
+// failed, because a build failed if build is true.
+func testsFailedErr(t *testing.T, build bool) error {
+	events := `{"Action":"fail","Package":"pkg"}` + "\n"
+	if build {
+		events = `{"Action":"fail","Package":"pkg","FailedBuild":"pkg"}` + "\n"
+	}
+	err := gotest.Report(strings.NewReader(events))
+	require.ErrorIs(t, err, gotest.ErrTestsFailed)
+	return err
+}
+
+func Test_categorize(t *testing.T) {
+	inner := errors.New("blah")
+	err := categorize(errReportFailed, fmt.Errorf("failed to write: %w", inner))
+	require.EqualError(t, err, "failed to write: blah")
+	require.ErrorIs(t, err, errReportFailed)
+	require.ErrorIs(t, err, inner)
+	require.NotErrorIs(t, err, errTestsFailed)
+}
+
+func Test_categorizeTestErr(t *testing.T) {
+	internal := errors.New("go test failed: exit status 2")
+	require.Equal(t, internal, categorizeTestErr(internal))
+	require.ErrorIs(t, categorizeTestErr(testsFailedErr(t, false)), errTestsFailed)
+	require.NotErrorIs(t, categorizeTestErr(testsFailedErr(t, false)), errBuildFailed)
+	require.ErrorIs(t, categorizeTestErr(testsFailedErr(t, true)), errBuildFailed)
+}
+
+func Test_CombineErrors(t *testing.T) {
+	require.NoError(t, CombineErrors(nil))
+
+	single := errors.New("single")
+	require.Equal(t, single, CombineErrors([]error{single}))
+
+	err := CombineErrors([]error{errors.New("first"), errCoverageCheckFailed, errNoTests})
+	require.EqualError(t, err, "multiple errors occurred:\n  * first\n  * coverage check failed\n  * no tests\n")
+	require.ErrorIs(t, err, errCoverageCheckFailed)
+	require.ErrorIs(t, err, errNoTests)
+	require.NotErrorIs(t, err, errTestsFailed)
+}
+
+func Test_appendErrors(t *testing.T) {
+	require.NoError(t, appendErrors(nil))
+	require.Equal(t, errNoTests, appendErrors(nil, errNoTests))
+	require.Equal(t, errNoTests, appendErrors(errNoTests))
+
+	combined := CombineErrors([]error{errors.New("first"), errNoTests})
+	require.Equal(
+		t,
+		multiError{errors.New("first"), errNoTests, errCoverageCheckFailed},
+		appendErrors(combined, errCoverageCheckFailed),
+	)
+}
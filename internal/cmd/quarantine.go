@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/config"
+	"oss.indeed.com/go/go-opine/internal/gotest"
+)
+
+// quarantineOption returns the option that quarantines the tests of the
+// quarantines that have not expired by now, and a policy failure for each
+// that has. The failures of the tests of expired quarantines fail the run
+// as usual.
+func quarantineOption(quarantines []config.Quarantine, now time.Time) (gotest.Option, []error) {
+	var (
+		tests []gotest.QuarantinedTest
+		errs  []error
+	)
+	for _, q := range quarantines {
+		test := gotest.QuarantinedTest{
+			Package: q.Package,
+			Test:    q.Test,
+			Owner:   q.Owner,
+			Reason:  q.Reason,
+			Expires: q.ExpiryDate(),
+		}
+		if test.Expired(now) {
+			errs = append(errs, categorize(errPolicyFailed, fmt.Errorf("the quarantine of %s expired (%s)", quarantineName(q), test)))
+			continue
+		}
+		tests = append(tests, test)
+	}
+	return gotest.Quarantine(tests...), errs
+}
+
+// quarantineName returns the name of the tests of the quarantine (e.g.
+// "example.com/pkg.TestFoo", or "example.com/pkg" for all its tests).
+func quarantineName(q config.Quarantine) string {
+	if q.Test == "" {
+		return q.Package
+	}
+	return q.Package + "." + q.Test
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/config"
+)
+
+func Test_quarantineOption(t *testing.T) {
+	quarantines := []config.Quarantine{
+		{Package: "example.com/a", Test: "TestFoo", Owner: "@a", Reason: "flaky", Expires: "2026-10-17"},
+		{Package: "example.com/b", Owner: "@b", Reason: "broken", Expires: "2026-10-18"},
+	}
+	_, errs := quarantineOption(quarantines, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "the quarantine of example.com/a.TestFoo expired (owner @a, expires 2026-10-17: flaky)")
+	require.Equal(t, exitPolicyFailed, exitStatus(errs[0]))
+}
+
+func Test_ReportCmd_impl_quarantine(t *testing.T) {
+	eventsPath := filepath.Join(t.TempDir(), "events.json")
+	events := `{"Action":"run","Package":"example.com/a","Test":"TestFoo"}
+{"Action":"output","Package":"example.com/a","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestFoo","Output":"--- FAIL: TestFoo (0.00s)\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestFoo"}
+{"Action":"output","Package":"example.com/a","Output":"FAIL\texample.com/a\t0.01s\n"}
+{"Action":"fail","Package":"example.com/a"}
+`
+	require.NoError(t, os.WriteFile(eventsPath, []byte(events), 0600))
+	quarantine := config.Quarantine{Package: "example.com/a", Test: "TestFoo", Owner: "@a", Reason: "flaky", Expires: "2999-12-31"}
+
+	var out strings.Builder
+	tested := reportCmd{
+		testCmd: testCmd{out: &out, quarantine: []config.Quarantine{quarantine}},
+		input:   eventsPath,
+	}
+	require.NoError(t, tested.impl())
+	require.Contains(t, out.String(), "--- QUARANTINED: TestFoo (owner @a, expires 2999-12-31: flaky)\nok  \texample.com/a\t0.01s\n")
+
+	quarantine.Expires = "2000-01-01"
+	tested = reportCmd{
+		testCmd: testCmd{out: io.Discard, quarantine: []config.Quarantine{quarantine}},
+		input:   eventsPath,
+	}
+	err := tested.impl()
+	require.ErrorContains(t, err, "the quarantine of example.com/a.TestFoo expired")
+	require.ErrorContains(t, err, "unit tests failed: 1 package failed")
+	require.Equal(t, exitTestsFailed, exitStatus(err))
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/subcommands"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+	"oss.indeed.com/go/go-opine/internal/printing"
+)
+
+// ReportCmd returns a subcommand that reports the results of tests that
+// were already run.
+func ReportCmd() subcommands.Command {
+	return &reportCmd{
+		testCmd: testCmd{
+			out:            os.Stdout,
+			errOut:         os.Stderr,
+			minCovPercent:  defaultMinCoverage,
+			maxSkipPercent: defaultMaxSkipPercent,
+			color:          printing.ColorAuto,
+		},
+	}
+}
+
+// reportCmd reports test results like testCmd, except that they are read
+// from saved "go test -json" output instead of running the tests.
+type reportCmd struct {
+	testCmd
+
+	input             string
+	inputCoverprofile string
+}
+
+func (*reportCmd) Name() string {
+	return "report"
+}
+
+func (*reportCmd) Synopsis() string {
+	return "report the results of Go tests from saved \"go test -json\" output"
+}
+
+func (*reportCmd) Usage() string {
+	return `report -input <path> [-input-coverprofile <path>] [-min-coverage <percent>] [-junit <path>] [-tap <path|->] [-xmlcov <path>] [-coverprofile <path>] [-summary-json <path>] [-reporter <command>]... [-optional-reporter <command>]... [-build-warnings] [-require-skip-reason] [-max-skip-percent <percent>] [-skip-reason-pattern <regexp>] [-require-tests] [-allow-untested <package>]... [-test-count-baseline <path> [-update-test-count-baseline]] [-color auto|always|never] [-config <path>]:
+  Report the results of Go tests from saved "go test -json" output in an
+  opinionated way. Coverage is only checked when -input-coverprofile is set.
+  Flags that are not set are read from GO_OPINE_* environment variables or
+  the configuration file (see the README).
+`
+}
+
+func (r *reportCmd) SetFlags(f *flag.FlagSet) {
+	r.setReportFlags(f)
+	f.StringVar(&r.input, "input", "", "read \"go test -json\" output, gzip-compressed if the path ends with \".gz\" (\"-\" for stdin)")
+	f.StringVar(&r.inputCoverprofile, "input-coverprofile", "", "read Go coverprofile coverage of the tests")
+}
+
+//revive:disable:unused-parameter
+func (r *reportCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	return executeNoArgs(f, r.withConfig(f, r.impl))
+}
+
+func (r *reportCmd) impl() error {
+	if r.input == "" {
+		return categorize(errUsage, errors.New("the -input flag is required"))
+	}
+	var in io.Reader = os.Stdin
+	if r.input != "-" {
+		f, err := openFile(r.input)
+		if err != nil {
+			return categorize(errUsage, fmt.Errorf("failed to open input: %w", err))
+		}
+		defer f.Close()
+		in = f
+	}
+	if r.xmlcov != "" || r.coverprofile != "" {
+		if r.inputCoverprofile == "" {
+			return categorize(errUsage, errors.New("the -xmlcov and -coverprofile flags require -input-coverprofile"))
+		}
+	}
+	if r.inputCoverprofile != "" {
+		if _, err := os.Stat(r.inputCoverprofile); err != nil {
+			return categorize(errUsage, fmt.Errorf("failed to open input coverprofile: %w", err))
+		}
+	}
+	return r.report(func(reporters ...gotest.Option) error {
+		return gotest.Report(in, reporters...)
+	}, r.inputCoverprofile)
+}
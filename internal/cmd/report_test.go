@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/plugin"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// saveGoTestJSON runs "go test -json" in the current directory and saves
+// its output and coverprofile to a temporary directory.
+func saveGoTestJSON(t *testing.T) (eventsPath, covPath string) {
+	dir := t.TempDir()
+	eventsPath = filepath.Join(dir, "events.json")
+	covPath = filepath.Join(dir, "cover.out")
+	events, err := exec.Command("go", "test", "-json", "-coverprofile="+covPath, "-coverpkg=./...", "./...").Output()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(eventsPath, events, 0666))
+	return eventsPath, covPath
+}
+
+func Test_ReportCmd_impl(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	eventsPath, covPath := saveGoTestJSON(t)
+
+	junitPath := filepath.Join(t.TempDir(), "junit.xml")
+	var out bytes.Buffer
+	tested := reportCmd{testCmd: testCmd{
+		out:           &out,
+		junit:         junitPath,
+		minCovPercent: 5,
+		color:         printing.ColorNever,
+	}}
+	tested.input = eventsPath
+	tested.inputCoverprofile = covPath
+	err := tested.impl()
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "ok  \toss.indeed.com/go/go-opine-test/go-library/library\t")
+	require.Contains(t, out.String(), "Test coverage sufficient (50.0% >= 5.0%)\n")
+
+	junitBytes, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	require.Contains(t, string(junitBytes), "\"Test_Library\"")
+}
+
+func Test_ReportCmd_impl_reporters(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	eventsPath, covPath := saveGoTestJSON(t)
+
+	dir := t.TempDir()
+	reporter := filepath.Join(dir, "reporter.sh")
+	require.NoError(t, os.WriteFile(reporter, []byte("#!/bin/sh\ncat > \"$1\"\n"), 0755)) //nolint:gosec
+	failing := filepath.Join(dir, "failing.sh")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\nexit 3\n"), 0755)) //nolint:gosec
+	outPath := filepath.Join(dir, "out.json")
+
+	var out bytes.Buffer
+	tested := reportCmd{testCmd: testCmd{
+		out:               &out,
+		reporters:         stringsFlag{reporter + " " + outPath},
+		optionalReporters: stringsFlag{failing},
+		minCovPercent:     5,
+		color:             printing.ColorNever,
+	}}
+	tested.input = eventsPath
+	tested.inputCoverprofile = covPath
+	require.NoError(t, tested.impl())
+	require.Contains(t, out.String(), "Ignoring the failure of an optional reporter: reporter \""+failing+"\" failed: exit status 3\n")
+
+	f, err := os.Open(outPath)
+	require.NoError(t, err)
+	defer f.Close()
+	var messages []plugin.Message
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var m plugin.Message
+		require.NoError(t, dec.Decode(&m))
+		messages = append(messages, m)
+	}
+	require.Equal(t, plugin.Message{Type: plugin.StartMessage, ProtocolVersion: plugin.ProtocolVersion, GoOpineVersion: goOpineVersion()}, messages[0])
+	var tests, packages []string
+	for _, m := range messages[1 : len(messages)-1] {
+		switch m.Type {
+		case plugin.TestMessage:
+			tests = append(tests, m.Test.Name)
+		case plugin.PackageMessage:
+			packages = append(packages, m.Package.ImportPath)
+		}
+	}
+	require.Contains(t, tests, "Test_Library")
+	require.Contains(t, packages, "oss.indeed.com/go/go-opine-test/go-library/library")
+	last := messages[len(messages)-1]
+	require.Equal(t, plugin.CoverageMessage, last.Type)
+	require.Equal(t, 50.0, last.Coverage.Percent)
+}
+
+func Test_ReportCmd_impl_requiredReporterFailed(t *testing.T) {
+	eventsPath := filepath.Join(t.TempDir(), "events.json")
+	events := `{"Action":"run","Package":"pkg","Test":"TestFoo"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"pass","Package":"pkg","Test":"TestFoo"}
+{"Action":"pass","Package":"pkg"}
+`
+	require.NoError(t, os.WriteFile(eventsPath, []byte(events), 0666))
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tested := reportCmd{testCmd: testCmd{out: io.Discard, reporters: stringsFlag{missing}}}
+	tested.input = eventsPath
+	err := tested.impl()
+	require.ErrorContains(t, err, "reporter \""+missing+"\" failed: ")
+	require.ErrorIs(t, err, errReportFailed)
+	require.Equal(t, exitReportFailed, exitStatus(err))
+}
+
+func Test_ReportCmd_impl_insufficientCoverage(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	eventsPath, covPath := saveGoTestJSON(t)
+
+	tested := reportCmd{testCmd: testCmd{out: io.Discard, minCovPercent: 51}}
+	tested.input = eventsPath
+	tested.inputCoverprofile = covPath
+	err := tested.impl()
+	require.Equal(t, errCoverageCheckFailed, err)
+}
+
+func Test_ReportCmd_impl_withoutCoverage(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	eventsPath, _ := saveGoTestJSON(t)
+
+	var out bytes.Buffer
+	tested := reportCmd{testCmd: testCmd{out: &out, minCovPercent: 51}}
+	tested.input = eventsPath
+	err := tested.impl()
+	require.NoError(t, err)
+	require.NotContains(t, out.String(), "coverage")
+}
+
+func Test_ReportCmd_impl_jsonOutFromTest(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	jsonOutPath := filepath.Join(t.TempDir(), "events.json.gz")
+	test := testCmd{out: io.Discard, jsonOut: jsonOutPath, minCovPercent: 5, color: printing.ColorNever}
+	require.NoError(t, test.impl())
+
+	var reportOut bytes.Buffer
+	tested := reportCmd{testCmd: testCmd{out: &reportOut, color: printing.ColorNever}}
+	tested.input = jsonOutPath
+	require.NoError(t, tested.impl())
+	require.Contains(t, reportOut.String(), "ok  \toss.indeed.com/go/go-opine-test/go-library/library\t")
+	require.Contains(t, reportOut.String(), "ok  \toss.indeed.com/go/go-opine-test/go-library/testonly\t")
+}
+
+func Test_ReportCmd_impl_failed(t *testing.T) {
+	eventsPath := filepath.Join(t.TempDir(), "events.json")
+	events := `{"Action":"run","Package":"pkg","Test":"TestFoo"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"fail","Package":"pkg","Test":"TestFoo"}
+{"Action":"fail","Package":"pkg"}
+`
+	require.NoError(t, os.WriteFile(eventsPath, []byte(events), 0666))
+
+	tested := reportCmd{testCmd: testCmd{out: io.Discard}}
+	tested.input = eventsPath
+	err := tested.impl()
+	require.EqualError(t, err, "unit tests failed: 1 package failed")
+	require.ErrorIs(t, err, errTestsFailed)
+	require.Equal(t, exitTestsFailed, exitStatus(err))
+}
+
+// readSummary reads the JSON summary at the path.
+func readSummary(t *testing.T, path string) summary.Summary {
+	out, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var sum summary.Summary
+	require.NoError(t, json.Unmarshal(out, &sum))
+	return sum
+}
+
+func Test_ReportCmd_impl_failedSummaryJSON(t *testing.T) {
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, "events.json")
+	events := `{"Action":"run","Package":"pkg","Test":"TestFoo"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"fail","Package":"pkg","Test":"TestFoo","Elapsed":0.5}
+{"Action":"fail","Package":"pkg","Elapsed":1}
+`
+	require.NoError(t, os.WriteFile(eventsPath, []byte(events), 0666))
+	summaryPath := filepath.Join(dir, "summary.json")
+
+	tested := reportCmd{testCmd: testCmd{out: io.Discard, summaryJSON: summaryPath, maxSkipPercent: defaultMaxSkipPercent}}
+	tested.input = eventsPath
+	require.ErrorIs(t, tested.impl(), errTestsFailed)
+
+	sum := readSummary(t, summaryPath)
+	require.Empty(t, sum.GoVersion)
+	require.Empty(t, sum.GoTestArgs)
+	require.Nil(t, sum.Coverage)
+	require.Equal(
+		t,
+		[]summary.Package{{
+			ImportPath:     "pkg",
+			Outcome:        "fail",
+			ElapsedSeconds: 1,
+			Tests:          []summary.Test{{Name: "TestFoo", Outcome: "fail", ElapsedSeconds: 0.5, FailedItself: true}},
+		}},
+		sum.Packages,
+	)
+	require.Equal(t, []summary.Check{}, sum.Checks)
+	require.Equal(t, []summary.Error{{Message: "unit tests failed: 1 package failed", Category: "tests-failed"}}, sum.Errors)
+	require.Equal(t, int(exitTestsFailed), sum.ExitStatus)
+}
+
+func Test_ReportCmd_impl_buildWarnings(t *testing.T) {
+	eventsPath := filepath.Join(t.TempDir(), "events.json")
+	events := `{"ImportPath":"pkg.test","Action":"build-output","Output":"# pkg.test\nld: warning: odd\n"}
+{"Action":"run","Package":"pkg","Test":"TestFoo"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"pass","Package":"pkg","Test":"TestFoo"}
+{"Action":"pass","Package":"pkg"}
+`
+	require.NoError(t, os.WriteFile(eventsPath, []byte(events), 0666))
+
+	for _, buildWarnings := range []bool{false, true} {
+		var out bytes.Buffer
+		tested := reportCmd{testCmd: testCmd{out: &out, buildWarnings: buildWarnings}}
+		tested.input = eventsPath
+		require.NoError(t, tested.impl())
+		if buildWarnings {
+			require.Contains(t, out.String(), "ld: warning: odd\n")
+		} else {
+			require.NotContains(t, out.String(), "ld: warning")
+		}
+	}
+}
+
+func Test_ReportCmd_impl_noInput(t *testing.T) {
+	tested := reportCmd{testCmd: testCmd{out: io.Discard}}
+	err := tested.impl()
+	require.EqualError(t, err, "the -input flag is required")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
+
+func Test_ReportCmd_impl_missingInput(t *testing.T) {
+	tested := reportCmd{testCmd: testCmd{out: io.Discard}}
+	tested.input = filepath.Join(t.TempDir(), "missing.json")
+	err := tested.impl()
+	require.ErrorContains(t, err, "failed to open input: ")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
+
+func Test_ReportCmd_impl_missingInputCoverprofile(t *testing.T) {
+	tested := reportCmd{testCmd: testCmd{out: io.Discard}}
+	tested.input = "-"
+	tested.inputCoverprofile = filepath.Join(t.TempDir(), "missing.out")
+	err := tested.impl()
+	require.ErrorContains(t, err, "failed to open input coverprofile: ")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
+
+func Test_ReportCmd_impl_invalidInputCoverprofile(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	eventsPath, _ := saveGoTestJSON(t)
+	covPath := filepath.Join(t.TempDir(), "cov.out")
+	require.NoError(t, os.WriteFile(covPath, []byte("not a coverprofile\n"), 0600))
+
+	tested := reportCmd{testCmd: testCmd{out: io.Discard}}
+	tested.input = eventsPath
+	tested.inputCoverprofile = covPath
+	err := tested.impl()
+	require.ErrorContains(t, err, "failed to load coverage: ")
+	require.Equal(t, exitReportFailed, exitStatus(err))
+}
+
+func Test_ReportCmd_impl_coverageOutputWithoutCoverage(t *testing.T) {
+	tested := reportCmd{testCmd: testCmd{out: io.Discard, xmlcov: "cov.xml"}}
+	tested.input = "-"
+	err := tested.impl()
+	require.EqualError(t, err, "the -xmlcov and -coverprofile flags require -input-coverprofile")
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// checkSkips writes how many of the tests were skipped, if any, and the
+// skipped tests that violate the skip policies (see the
+// -require-skip-reason, -max-skip-percent, and -skip-reason-pattern
+// flags). A policy failure is returned for each policy that is violated.
+// The max-skip-percent check is added to sum if it is not nil and the
+// policy is enabled.
+func (t *testCmd) checkSkips(
+	w io.Writer,
+	palette printing.Palette,
+	counts []gotest.TestCount,
+	reasonPattern *regexp.Regexp,
+	sum *summary.Summary,
+) []error {
+	var (
+		tests, skipped      int
+		noReason, unmatched int
+		tooMany             int
+		maxPercent          float64
+		violations          []string
+	)
+	for _, c := range counts {
+		tests += c.Tests
+		skipped += len(c.Skipped)
+		for _, s := range c.Skipped {
+			name := c.Package + "." + s.Test
+			switch {
+			case s.Reason == "" && t.requireSkipReason:
+				noReason++
+				violations = append(violations, name+" was skipped without a reason")
+			case reasonPattern != nil && !reasonPattern.MatchString(s.Reason):
+				unmatched++
+				violations = append(violations, fmt.Sprintf("%s was skipped with a reason that does not match %s: %q", name, reasonPattern, s.Reason))
+			}
+		}
+		if c.Tests == 0 {
+			continue
+		}
+		percent := float64(len(c.Skipped)) / float64(c.Tests) * 100
+		maxPercent = max(maxPercent, percent)
+		if percent > t.maxSkipPercent {
+			tooMany++
+			violations = append(violations, fmt.Sprintf("%s skipped %d of %d tests (%.1f%% > %.1f%%)", c.Package, len(c.Skipped), c.Tests, percent, t.maxSkipPercent))
+		}
+	}
+
+	if sum != nil && t.maxSkipPercent < defaultMaxSkipPercent {
+		sum.Checks = append(sum.Checks, summary.Check{
+			Name:      "max-skip-percent",
+			Threshold: t.maxSkipPercent,
+			Actual:    maxPercent,
+			Passed:    tooMany == 0,
+		})
+	}
+	if skipped > 0 {
+		_, _ = fmt.Fprintln(w, palette.Yellow(fmt.Sprintf("Skipped %d of %s.", skipped, countOf(tests, "test"))))
+	}
+	for _, v := range violations {
+		_, _ = fmt.Fprintln(w, palette.Red(v))
+	}
+	if len(violations) > 0 {
+		_, _ = fmt.Fprintln(w, "Set the -require-skip-reason, -max-skip-percent, and -skip-reason-pattern flags to configure skip policies.")
+	}
+
+	var errs []error
+	if noReason > 0 {
+		errs = append(errs, categorize(errPolicyFailed, fmt.Errorf("%s skipped without a reason", countOf(noReason, "test"))))
+	}
+	if unmatched > 0 {
+		errs = append(errs, categorize(errPolicyFailed, fmt.Errorf("%s skipped with a reason that does not match %s", countOf(unmatched, "test"), reasonPattern)))
+	}
+	if tooMany > 0 {
+		errs = append(errs, categorize(errPolicyFailed, fmt.Errorf("%s skipped more than %.1f%% of their tests", countOf(tooMany, "package"), t.maxSkipPercent)))
+	}
+	return errs
+}
+
+// addCountCheck adds a check to sum, if it is not nil, that passed iff
+// there are no violations of the policy (e.g. "require-tests").
+func addCountCheck(sum *summary.Summary, name string, violations int) {
+	if sum == nil {
+		return
+	}
+	sum.Checks = append(sum.Checks, summary.Check{
+		Name:      name,
+		Threshold: 0,
+		Actual:    float64(violations),
+		Passed:    violations == 0,
+	})
+}
+
+// countOf returns the count followed by the noun, pluralized unless the
+// count is 1 (e.g. "1 test" or "2 tests").
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
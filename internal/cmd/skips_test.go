@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/require"
+)
+
+const skipEvents = `{"Action":"run","Package":"example.com/a","Test":"TestA"}
+{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"--- PASS: TestA (0.00s)\n"}
+{"Action":"pass","Package":"example.com/a","Test":"TestA"}
+{"Action":"run","Package":"example.com/a","Test":"TestB"}
+{"Action":"output","Package":"example.com/a","Test":"TestB","Output":"=== RUN   TestB\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestB","Output":"--- SKIP: TestB (0.00s)\n"}
+{"Action":"skip","Package":"example.com/a","Test":"TestB"}
+{"Action":"run","Package":"example.com/a","Test":"TestC"}
+{"Action":"output","Package":"example.com/a","Test":"TestC","Output":"=== RUN   TestC\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestC","Output":"    a_test.go:5: flaky, see ABC-12\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestC","Output":"--- SKIP: TestC (0.00s)\n"}
+{"Action":"skip","Package":"example.com/a","Test":"TestC"}
+{"Action":"output","Package":"example.com/a","Output":"ok  \texample.com/a\t0.01s\n"}
+{"Action":"pass","Package":"example.com/a"}
+`
+
+func writeSkipEvents(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "events.json")
+	require.NoError(t, os.WriteFile(path, []byte(skipEvents), 0600))
+	return path
+}
+
+func Test_ReportCmd_impl_skips(t *testing.T) {
+	var out strings.Builder
+	tested := reportCmd{
+		testCmd: testCmd{out: &out, maxSkipPercent: defaultMaxSkipPercent},
+		input:   writeSkipEvents(t),
+	}
+	require.NoError(t, tested.impl())
+	require.Contains(t, out.String(), "Skipped 2 of 3 tests.\n")
+}
+
+func Test_ReportCmd_impl_skipPolicies(t *testing.T) {
+	var out strings.Builder
+	tested := reportCmd{
+		testCmd: testCmd{
+			out:               &out,
+			requireSkipReason: true,
+			maxSkipPercent:    50,
+			skipReasonPattern: `[A-Z]+-[0-9]+`,
+		},
+		input: writeSkipEvents(t),
+	}
+	err := tested.impl()
+	require.EqualError(
+		t,
+		err,
+		"multiple errors occurred:\n"+
+			"  * 1 test skipped without a reason\n"+
+			"  * 1 package skipped more than 50.0% of their tests\n",
+	)
+	require.Equal(t, exitPolicyFailed, exitStatus(err))
+	require.Contains(
+		t,
+		out.String(),
+		"Skipped 2 of 3 tests.\n"+
+			"example.com/a.TestB was skipped without a reason\n"+
+			"example.com/a skipped 2 of 3 tests (66.7% > 50.0%)\n",
+	)
+
+	tested = reportCmd{
+		testCmd: testCmd{out: &out, maxSkipPercent: defaultMaxSkipPercent, skipReasonPattern: `^JIRA-`},
+		input:   writeSkipEvents(t),
+	}
+	err = tested.impl()
+	require.Error(t, err)
+	require.Equal(t, exitPolicyFailed, exitStatus(err))
+	require.Contains(t, out.String(), "example.com/a.TestB was skipped with a reason that does not match ^JIRA-: \"\"\n")
+	require.Contains(t, out.String(), "example.com/a.TestC was skipped with a reason that does not match ^JIRA-: \"flaky, see ABC-12\"\n")
+	require.Contains(t, err.Error(), "2 tests skipped with a reason that does not match ^JIRA-")
+}
+
+func Test_ReportCmd_impl_invalidSkipReasonPattern(t *testing.T) {
+	tested := reportCmd{
+		testCmd: testCmd{out: io.Discard, maxSkipPercent: defaultMaxSkipPercent, skipReasonPattern: "("},
+		input:   writeSkipEvents(t),
+	}
+	err := tested.impl()
+	require.ErrorContains(t, err, "invalid -skip-reason-pattern")
+	require.Equal(t, subcommands.ExitUsageError, exitStatus(err))
+}
@@ -3,22 +3,41 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/subcommands"
 
-	"oss.indeed.com/go/go-opine/internal/coverage"
+	"oss.indeed.com/go/go-opine/coverage"
+	"oss.indeed.com/go/go-opine/internal/config"
+	"oss.indeed.com/go/go-opine/internal/github"
 	"oss.indeed.com/go/go-opine/internal/gotest"
 	"oss.indeed.com/go/go-opine/internal/junit"
+	"oss.indeed.com/go/go-opine/internal/plugin"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
+	"oss.indeed.com/go/go-opine/internal/teamcity"
 )
 
 const (
 	defaultMinCoverage = 50.0
+
+	// defaultMaxSkipPercent allows all tests of a package to be skipped.
+	defaultMaxSkipPercent = 100.0
+
+	// slowTestThreshold is how long a test must run before it is listed
+	// in the progress status line.
+	slowTestThreshold = 10 * time.Second
 )
 
 // hasATestRegexp will match any "go test" output that has at least one
@@ -28,19 +47,45 @@ var hasATestRegexp = regexp.MustCompile(`(?m)^=== RUN\b`)
 // TestCmd returns a subcommand that tests a go project.
 func TestCmd() subcommands.Command {
 	return &testCmd{
-		out:           os.Stdout,
-		minCovPercent: defaultMinCoverage,
+		out:            os.Stdout,
+		errOut:         os.Stderr,
+		minCovPercent:  defaultMinCoverage,
+		maxSkipPercent: defaultMaxSkipPercent,
+		color:          printing.ColorAuto,
 	}
 }
 
 type testCmd struct {
-	out io.Writer
+	out    io.Writer
+	errOut io.Writer
 
-	junit         string
-	xmlcov        string
-	coverprofile  string
-	norace        bool
-	minCovPercent float64
+	junit             string
+	tap               string
+	summaryJSON       string
+	reporters         stringsFlag
+	optionalReporters stringsFlag
+	buildWarnings     bool
+	jsonOut           string
+	xmlcov            string
+	coverprofile      string
+	norace            bool
+	minCovPercent     float64
+	requireSkipReason bool
+	maxSkipPercent    float64
+	skipReasonPattern string
+	requireTests      bool
+	allowUntested     stringsFlag
+	testCountBaseline string
+	updateBaseline    bool
+	color             string
+	configPath        string
+
+	// goTestFlags, goTestEnv, exclude, and quarantine are set from the
+	// configuration file (see configure).
+	goTestFlags []string
+	goTestEnv   []string
+	exclude     []string
+	quarantine  []config.Quarantine
 }
 
 func (*testCmd) Name() string {
@@ -52,22 +97,44 @@ func (*testCmd) Synopsis() string {
 }
 
 func (*testCmd) Usage() string {
-	return `test [-min-coverage <percent>] [-junit <path>] [-xmlcov <path>] [-coverprofile <path>]:
-  Run Go tests in an opinionated way.
+	return `test [-min-coverage <percent>] [-junit <path>] [-tap <path|->] [-xmlcov <path>] [-coverprofile <path>] [-json-out <path>] [-summary-json <path>] [-reporter <command>]... [-optional-reporter <command>]... [-build-warnings] [-require-skip-reason] [-max-skip-percent <percent>] [-skip-reason-pattern <regexp>] [-require-tests] [-allow-untested <package>]... [-test-count-baseline <path> [-update-test-count-baseline]] [-color auto|always|never] [-config <path>]:
+  Run Go tests in an opinionated way. Flags that are not set are read from
+  GO_OPINE_* environment variables or the configuration file (see the README).
 `
 }
 
 func (t *testCmd) SetFlags(f *flag.FlagSet) {
+	t.setReportFlags(f)
+	f.BoolVar(&t.norace, "norace", false, "compile tests with race detector disabled")
+	f.StringVar(&t.jsonOut, "json-out", "", "write the unmodified \"go test -json\" output, gzip-compressed if the path ends with \".gz\"")
+}
+
+// setReportFlags sets the flags that control how test results are
+// reported, which are shared with the "report" subcommand.
+func (t *testCmd) setReportFlags(f *flag.FlagSet) {
 	f.Float64Var(&t.minCovPercent, "min-coverage", defaultMinCoverage, "minimum code test coverage to enforce")
 	f.StringVar(&t.junit, "junit", "", "write JUnit XML test results")
+	f.StringVar(&t.summaryJSON, "summary-json", "", "write a JSON summary of the run (see the README for the schema)")
+	f.Var(&t.reporters, "reporter", "run the reporter `command`, which is sent the results as JSON lines on stdin (see the README), failing the run if it fails; may be repeated")
+	f.Var(&t.optionalReporters, "optional-reporter", "like -reporter, except that the run does not fail if the reporter `command` fails; may be repeated")
+	f.StringVar(&t.tap, "tap", "", "write TAP version 14 test results (\"-\" for stdout, in which case all other output is written to stderr)")
 	f.StringVar(&t.xmlcov, "xmlcov", "", "write Cobertura XML coverage")
 	f.StringVar(&t.coverprofile, "coverprofile", "", "write Go coverprofile coverage")
-	f.BoolVar(&t.norace, "norace", false, "compile tests with race detector disabled")
+	f.BoolVar(&t.buildWarnings, "build-warnings", false, "report build warnings (e.g. from the linker) instead of dropping them")
+	f.BoolVar(&t.requireSkipReason, "require-skip-reason", false, "fail if a test is skipped without a reason (e.g. with t.SkipNow)")
+	f.Float64Var(&t.maxSkipPercent, "max-skip-percent", defaultMaxSkipPercent, "maximum percentage of the tests of a package that may be skipped")
+	f.StringVar(&t.skipReasonPattern, "skip-reason-pattern", "", "fail if a test is skipped with a reason that does not match this regular `expression` (e.g. a ticket like [A-Z]+-[0-9]+)")
+	f.BoolVar(&t.requireTests, "require-tests", false, "fail if a package with code that is neither generated nor of package main runs no tests")
+	f.Var(&t.allowUntested, "allow-untested", "allow the `package` (an import path, or a pattern ending in /...) to run no tests with -require-tests; may be repeated")
+	f.StringVar(&t.testCountBaseline, "test-count-baseline", "", "fail if a package runs fewer tests than recorded in the baseline file at this path")
+	f.BoolVar(&t.updateBaseline, "update-test-count-baseline", false, "write the number of tests each package ran to the -test-count-baseline file instead of checking it")
+	f.StringVar(&t.color, "color", printing.ColorAuto, "colorize output: auto (if stdout is a terminal and NO_COLOR is not set), always, or never")
+	f.StringVar(&t.configPath, "config", "", "read the configuration file at this path instead of "+config.FileName+" at the root of the module")
 }
 
 //revive:disable:unused-parameter
 func (t *testCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
-	return executeNoArgs(f, t.impl)
+	return executeNoArgs(f, t.withConfig(f, t.impl))
 }
 
 func (t *testCmd) impl() error {
@@ -75,69 +142,412 @@ func (t *testCmd) impl() error {
 	if err != nil {
 		return fmt.Errorf("failed to create temporary file for coverprofile output: %w", err)
 	}
-
-	var errs []error
-	var testOutBuf bytes.Buffer
+	coverPkg := "./..."
 	options := []gotest.Option{
-		gotest.Race(),
 		gotest.CoverProfile(covPath),
-		gotest.CoverPkg("./..."),
 		gotest.CoverMode("atomic"),
 		gotest.P(runtime.GOMAXPROCS(0)),
-		gotest.QuietOutput(t.out),
-		gotest.VerboseOutput(&testOutBuf),
+		gotest.Flags(t.goTestFlags...),
+		gotest.Env(t.goTestEnv...),
+	}
+	if len(t.exclude) > 0 {
+		pkgs, listErr := listPackages(t.exclude, t.goTestEnv)
+		if listErr != nil {
+			return categorize(errUsage, fmt.Errorf("failed to exclude packages: %w", listErr))
+		}
+		coverPkg = strings.Join(pkgs, ",")
+		options = append(options, gotest.Packages(pkgs...))
 	}
+	options = append(options, gotest.CoverPkg(coverPkg))
 	if !t.norace {
 		options = append(options, gotest.Race())
 	}
+	var jsonOut io.WriteCloser
+	if t.jsonOut != "" {
+		if jsonOut, err = createFile(t.jsonOut); err != nil {
+			return categorize(errReportFailed, fmt.Errorf("failed to create go test -json output: %w", err))
+		}
+		options = append(options, gotest.JSONOutput(jsonOut))
+	}
 
-	testErr := gotest.Run(options...)
-	if testErr != nil {
-		errs = append(errs, fmt.Errorf("unit tests failed: %w", testErr))
+	err = t.report(func(reporters ...gotest.Option) error {
+		return gotest.Run(append(options, reporters...)...)
+	}, covPath)
+	if jsonOut != nil {
+		if closeErr := jsonOut.Close(); closeErr != nil {
+			errs := []error{categorize(errReportFailed, fmt.Errorf("failed to write go test -json output: %w", closeErr))}
+			if err != nil {
+				errs = append([]error{err}, errs...)
+			}
+			return CombineErrors(errs)
+		}
 	}
+	return err
+}
 
-	testOut := testOutBuf.String()
-	if !hasATestRegexp.MatchString(testOut) {
-		errs = append(errs, errNoTests)
-		return CombineErrors(errs)
+// report calls runTests with the options that report the test results,
+// then writes the coverage reports and checks that there are tests and
+// that the coverage is sufficient. The coverage is read from the Go
+// coverprofile at covPath, or is not checked if covPath is empty. The
+// results are streamed to the reporter executables, if any, which are
+// waited for. Finally the JSON summary is written, if requested, with the
+// errors that occurred.
+func (t *testCmd) report(runTests func(reporters ...gotest.Option) error, covPath string) error {
+	reporters := t.startReporters()
+	if t.summaryJSON == "" {
+		return appendErrors(t.reportResults(runTests, covPath, nil, reporters), t.waitReporters(reporters)...)
+	}
+	sum := &summary.Summary{
+		SchemaVersion:  summary.SchemaVersion,
+		GoOpineVersion: goOpineVersion(),
+		Checks:         []summary.Check{},
+	}
+	err := appendErrors(t.reportResults(runTests, covPath, sum, reporters), t.waitReporters(reporters)...)
+	sum.Errors = summaryErrors(err)
+	sum.ExitStatus = int(exitStatus(err))
+	if writeErr := summary.Write(sum, t.summaryJSON); writeErr != nil {
+		return appendErrors(err, categorize(errReportFailed, fmt.Errorf("failed to write JSON summary: %w", writeErr)))
+	}
+	return err
+}
+
+// reportResults does the work of report, except for waiting for the
+// reporters and writing the JSON summary. The test results, coverage, and
+// checks are added to sum if it is not nil.
+func (t *testCmd) reportResults(
+	runTests func(reporters ...gotest.Option) error,
+	covPath string,
+	sum *summary.Summary,
+	reporters []*plugin.Reporter,
+) error {
+	logOut := t.logOut()
+
+	palette, err := printing.NewPalette(t.color, logOut)
+	if err != nil {
+		return categorize(errUsage, err)
+	}
+
+	if t.updateBaseline && t.testCountBaseline == "" {
+		return categorize(errUsage, errors.New("the -update-test-count-baseline flag requires -test-count-baseline"))
+	}
+	var skipReasonPattern *regexp.Regexp
+	if t.skipReasonPattern != "" {
+		if skipReasonPattern, err = regexp.Compile(t.skipReasonPattern); err != nil {
+			return categorize(errUsage, fmt.Errorf("invalid -skip-reason-pattern: %w", err))
+		}
+	}
+
+	var errs []error
+	var (
+		testOutBuf  bytes.Buffer
+		junitReport junit.Testsuites
+		testCounts  []gotest.TestCount
+		out         = logOut
+		status      *printing.StatusLineWriter
+	)
+	if printing.IsTerminal(logOut) {
+		status = printing.NewStatusLineWriter(logOut)
+		out = status
+	}
+	options := []gotest.Option{
+		gotest.ColoredQuietOutput(out, palette),
+		gotest.VerboseOutput(&testOutBuf),
+		gotest.JUnitReport(&junitReport),
+		gotest.TestCounts(&testCounts),
+		gotest.RaceSummary(out),
+		gotest.FailureSummary(out),
+	}
+	if status != nil {
+		options = append(options, gotest.Progress(status, slowTestThreshold))
+	}
+	if t.buildWarnings {
+		options = append(options, gotest.BuildWarnings())
+	}
+	if len(t.quarantine) > 0 {
+		quarantine, expired := quarantineOption(t.quarantine, time.Now())
+		options = append(options, quarantine)
+		errs = append(errs, expired...)
+	}
+	if sum != nil {
+		options = append(options, gotest.JSONSummary(sum))
+	}
+	if len(reporters) > 0 {
+		options = append(options, gotest.ReporterStream(reportersWriter(reporters)))
+	}
+	githubActions := os.Getenv("GITHUB_ACTIONS") == "true"
+	if githubActions {
+		root, rootErr := githubWorkspace()
+		if rootErr != nil {
+			return categorize(errReportFailed, fmt.Errorf("failed to find the GitHub workspace: %w", rootErr))
+		}
+		options = append(options, gotest.GitHubAnnotations(out, root))
+	}
+	teamCity := os.Getenv("TEAMCITY_VERSION") != ""
+	if teamCity {
+		options = append(options, gotest.TeamCityMessages(out))
+	}
+	var tapFile *os.File
+	switch t.tap {
+	case "":
+	case "-":
+		options = append(options, gotest.TAPReport(t.out))
+	default:
+		tapFile, err = os.Create(t.tap)
+		if err != nil {
+			return categorize(errReportFailed, fmt.Errorf("failed to create TAP output: %w", err))
+		}
+		options = append(options, gotest.TAPReport(tapFile))
+	}
+
+	testErr := runTests(options...)
+	if testErr != nil {
+		errs = append(errs, categorizeTestErr(fmt.Errorf("unit tests failed: %w", testErr)))
+	}
+	if tapFile != nil {
+		if closeErr := tapFile.Close(); closeErr != nil {
+			errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to write TAP output: %w", closeErr)))
+		}
 	}
 
 	if t.junit != "" {
-		if junitErr := junit.Write(testOut, t.junit); junitErr != nil {
-			errs = append(errs, fmt.Errorf("failed to write JUnit XML: %w", junitErr))
+		if junitErr := junit.Write(&junitReport, t.junit); junitErr != nil {
+			errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to write JUnit XML: %w", junitErr)))
 		}
 	}
 
+	errs = append(errs, t.checkSkips(logOut, palette, testCounts, skipReasonPattern, sum)...)
+	if t.requireTests {
+		errs = append(errs, t.checkUntested(logOut, palette, testCounts, sum)...)
+	}
+	if t.testCountBaseline != "" {
+		errs = append(errs, t.checkTestCounts(logOut, palette, testCounts, sum)...)
+	}
+
+	// The reports are written even if no test ran, since every package
+	// may have failed to build.
+	if !hasATestRegexp.MatchString(testOutBuf.String()) {
+		errs = append(errs, errNoTests)
+		return CombineErrors(errs)
+	}
+
+	if covPath == "" {
+		return CombineErrors(errs)
+	}
 	if cov, covLoadErr := coverage.Load(covPath); covLoadErr == nil {
 		if t.xmlcov != "" {
 			if xmlCovErr := cov.XML(t.xmlcov); xmlCovErr != nil {
-				errs = append(errs, fmt.Errorf("failed to write XML coverage: %w", xmlCovErr))
+				errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to write XML coverage: %w", xmlCovErr)))
 			}
 		}
 		if t.coverprofile != "" {
 			if covProfileErr := cov.CoverProfile(t.coverprofile); covProfileErr != nil {
-				errs = append(errs, fmt.Errorf("failed to write coverprofile coverage: %w", covProfileErr))
+				errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to write coverprofile coverage: %w", covProfileErr)))
+			}
+		}
+
+		if githubActions {
+			if annotateErr := writeCoverageAnnotations(logOut, cov.PackageRatios(), t.minCovPercent); annotateErr != nil {
+				errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to write coverage annotations: %w", annotateErr)))
+			}
+		}
+
+		if teamCity {
+			if statsErr := writeCoverageStatistics(logOut, cov); statsErr != nil {
+				errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to write coverage statistics: %w", statsErr)))
+			}
+		}
+
+		if len(reporters) > 0 {
+			m := plugin.Message{Type: plugin.CoverageMessage, Coverage: summaryCoverage(cov)}
+			if writeErr := plugin.Write(reportersWriter(reporters), m); writeErr != nil {
+				errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to send coverage to reporters: %w", writeErr)))
 			}
 		}
 
 		covRatio := cov.Ratio()
+		if sum != nil {
+			sum.Coverage = summaryCoverage(cov)
+			sum.Checks = append(sum.Checks, summary.Check{
+				Name:      "min-coverage",
+				Threshold: t.minCovPercent,
+				Actual:    covRatio * 100,
+				Passed:    covRatio >= t.minCovPercent/100,
+			})
+		}
 		if covRatio < t.minCovPercent/100 {
-			_, _ = fmt.Printf(
-				"Insufficient test coverage (%.1f%% < %.1f%%).\nSet the -min-coverage flag to configure coverage requirements.\n",
-				covRatio*100,
-				t.minCovPercent,
+			_, _ = fmt.Fprintf(
+				logOut,
+				"%s\nSet the -min-coverage flag to configure coverage requirements.\n",
+				palette.Red(fmt.Sprintf("Insufficient test coverage (%.1f%% < %.1f%%).", covRatio*100, t.minCovPercent)),
 			)
 			errs = append(errs, errCoverageCheckFailed)
 		} else {
-			_, _ = fmt.Printf(
-				"Test coverage sufficient (%.1f%% >= %.1f%%)\n",
-				covRatio*100,
-				t.minCovPercent,
+			_, _ = fmt.Fprintln(
+				logOut,
+				palette.Green(fmt.Sprintf("Test coverage sufficient (%.1f%% >= %.1f%%)", covRatio*100, t.minCovPercent)),
 			)
 		}
 	} else {
-		errs = append(errs, fmt.Errorf("failed to load coverage: %w", covLoadErr))
+		errs = append(errs, categorize(errReportFailed, fmt.Errorf("failed to load coverage: %w", covLoadErr)))
 	}
 
 	return CombineErrors(errs)
 }
+
+// logOut returns where to write the output other than the reports. When
+// the TAP results are written to stdout nothing else can be, since TAP
+// consumers expect nothing but TAP.
+func (t *testCmd) logOut() io.Writer {
+	if t.tap == "-" {
+		return t.errOut
+	}
+	return t.out
+}
+
+// startReporters starts the reporter executables and sends each the
+// start message.
+func (t *testCmd) startReporters() []*plugin.Reporter {
+	var reporters []*plugin.Reporter
+	for _, command := range t.reporters {
+		reporters = append(reporters, plugin.Start(command, true, t.logOut()))
+	}
+	for _, command := range t.optionalReporters {
+		reporters = append(reporters, plugin.Start(command, false, t.logOut()))
+	}
+	m := plugin.Message{Type: plugin.StartMessage, ProtocolVersion: plugin.ProtocolVersion, GoOpineVersion: goOpineVersion()}
+	// Sending to a reporter never fails (see plugin.Reporter.Write).
+	_ = plugin.Write(reportersWriter(reporters), m)
+	return reporters
+}
+
+// waitReporters waits for the reporters to exit, and returns an error for
+// each required reporter that failed. The failures of optional reporters
+// are only logged.
+func (t *testCmd) waitReporters(reporters []*plugin.Reporter) []error {
+	var errs []error
+	for _, r := range reporters {
+		err := r.Wait()
+		switch {
+		case err == nil:
+		case r.Required:
+			errs = append(errs, categorize(errReportFailed, err))
+		default:
+			_, _ = fmt.Fprintf(t.logOut(), "Ignoring the failure of an optional reporter: %v\n", err)
+		}
+	}
+	return errs
+}
+
+// reportersWriter returns a writer that writes to each of the reporters.
+func reportersWriter(reporters []*plugin.Reporter) io.Writer {
+	writers := make([]io.Writer, len(reporters))
+	for i, r := range reporters {
+		writers[i] = r
+	}
+	return io.MultiWriter(writers...)
+}
+
+// summaryCoverage returns the coverage for the JSON summary, with the
+// packages and files sorted by import path.
+func summaryCoverage(cov *coverage.Coverage) *summary.Coverage {
+	covered, total := cov.Statements()
+	return &summary.Coverage{
+		Statements: summaryStatements(coverage.StatementCount{Covered: covered, Total: total}),
+		Packages:   summaryCoverageOf(cov.PackageStatements()),
+		Files:      summaryCoverageOf(cov.FileStatements()),
+	}
+}
+
+// summaryCoverageOf returns the coverage of each package or file for the
+// JSON summary, sorted by import path.
+func summaryCoverageOf(counts map[string]coverage.StatementCount) []summary.CoverageOf {
+	coverageOf := make([]summary.CoverageOf, 0, len(counts))
+	for path, count := range counts {
+		coverageOf = append(coverageOf, summary.CoverageOf{Path: path, Statements: summaryStatements(count)})
+	}
+	sort.Slice(coverageOf, func(i, j int) bool { return coverageOf[i].Path < coverageOf[j].Path })
+	return coverageOf
+}
+
+// summaryStatements converts a coverage.StatementCount for the JSON
+// summary.
+func summaryStatements(count coverage.StatementCount) summary.Statements {
+	return summary.Statements{Covered: count.Covered, Total: count.Total, Percent: count.Ratio() * 100}
+}
+
+// summaryErrors returns the errors for the JSON summary: each error
+// combined by err (see CombineErrors) with its category.
+func summaryErrors(err error) []summary.Error {
+	errs := []summary.Error{}
+	if err == nil {
+		return errs
+	}
+	for _, leaf := range leafErrors(err) {
+		errs = append(errs, summary.Error{Message: leaf.Error(), Category: exitStatusCategories[errorExitStatus(leaf)]})
+	}
+	return errs
+}
+
+// goOpineVersion returns the version of the go-opine module, or "(devel)"
+// if it is not known.
+func goOpineVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// githubWorkspace returns the root of the repository checked out by a
+// GitHub Actions workflow. If it is not known the current working
+// directory is returned.
+func githubWorkspace() (string, error) {
+	if root := os.Getenv("GITHUB_WORKSPACE"); root != "" {
+		return root, nil
+	}
+	return os.Getwd()
+}
+
+// writeCoverageAnnotations writes a GitHub Actions warning annotation for
+// each package with less than the minimum coverage.
+func writeCoverageAnnotations(w io.Writer, ratios map[string]float64, minCovPercent float64) error {
+	pkgs := make([]string, 0, len(ratios))
+	for pkg := range ratios {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		if ratios[pkg] >= minCovPercent/100 {
+			continue
+		}
+		annotation := github.Annotation{
+			Level:   github.Warning,
+			Title:   "Insufficient test coverage",
+			Message: fmt.Sprintf("%s has %.1f%% test coverage (< %.1f%%)", pkg, ratios[pkg]*100, minCovPercent),
+		}
+		if _, err := fmt.Fprintln(w, annotation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCoverageStatistics writes TeamCity service messages that report the
+// statement coverage as build statistics.
+func writeCoverageStatistics(w io.Writer, cov *coverage.Coverage) error {
+	covered, total := cov.Statements()
+	stats := []teamcity.Attr{
+		{Name: "CodeCoverageAbsSCovered", Value: strconv.Itoa(covered)},
+		{Name: "CodeCoverageAbsSTotal", Value: strconv.Itoa(total)},
+		{Name: "CodeCoverageS", Value: strconv.FormatFloat(cov.Ratio()*100, 'f', 1, 64)},
+	}
+	for _, stat := range stats {
+		message := teamcity.Message{
+			Name:  "buildStatisticValue",
+			Attrs: []teamcity.Attr{{Name: "key", Value: stat.Name}, {Name: "value", Value: stat.Value}},
+		}
+		if _, err := fmt.Fprintln(w, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
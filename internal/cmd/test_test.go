@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
 )
 
 func Test_TestCmd_impl(t *testing.T) {
@@ -90,6 +96,25 @@ func Test_TestCmd_impl_noTests(t *testing.T) {
 	require.Equal(t, errNoTests, err)
 }
 
+func Test_TestCmd_impl_buildFailedJUnit(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/broken\n\ngo 1.25\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package broken\n\nfunc F() int { return undefined }\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken_test.go"), []byte("package broken\n\nimport \"testing\"\n\nfunc TestF(t *testing.T) { F() }\n"), 0600))
+	popd := pushd(t, dir)
+	defer popd()
+
+	// No test runs since every package failed to build, but the build
+	// failure is still reported in the JUnit XML.
+	junitPath := filepath.Join(t.TempDir(), "junit.xml")
+	tested := testCmd{out: io.Discard, junit: junitPath}
+	err := tested.impl()
+	require.ErrorIs(t, err, errNoTests)
+	junitBytes, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	require.Contains(t, string(junitBytes), "undefined: undefined")
+}
+
 func Test_TestCmd_impl_sufficientCoverage(t *testing.T) {
 	popd := pushd(t, "testdata", "go-library")
 	defer popd()
@@ -115,6 +140,214 @@ func Test_TestCmd_impl_insufficientCoverage(t *testing.T) {
 	require.Equal(t, errCoverageCheckFailed, err)
 }
 
+func Test_TestCmd_impl_coloredCoverageVerdict(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	var out bytes.Buffer
+	tested := testCmd{
+		out:           &out,
+		minCovPercent: 51,
+		color:         printing.ColorAlways,
+	}
+	err := tested.impl()
+	require.Equal(t, errCoverageCheckFailed, err)
+	require.Contains(t, out.String(), "\x1b[32mok\x1b[0m")
+	require.Contains(t, out.String(), "\x1b[31mInsufficient test coverage (50.0% < 51.0%).\x1b[0m\n")
+}
+
+func Test_TestCmd_impl_githubActions(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_WORKSPACE", "")
+
+	var out bytes.Buffer
+	tested := testCmd{
+		out:           &out,
+		minCovPercent: 51,
+		color:         printing.ColorNever,
+	}
+	err := tested.impl()
+	require.Equal(t, errCoverageCheckFailed, err)
+	require.Contains(
+		t,
+		out.String(),
+		"::warning title=Insufficient test coverage::oss.indeed.com/go/go-opine-test/go-library/library has 50.0%25 test coverage (< 51.0%25)\n",
+	)
+}
+
+func Test_TestCmd_impl_teamCity(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	t.Setenv("TEAMCITY_VERSION", "2025.07")
+
+	var out bytes.Buffer
+	tested := testCmd{
+		out:           &out,
+		minCovPercent: 5,
+		color:         printing.ColorNever,
+	}
+	err := tested.impl()
+	require.NoError(t, err)
+	const flow = " flowId='oss.indeed.com/go/go-opine-test/go-library/library']\n"
+	require.Contains(t, out.String(), "##teamcity[testSuiteStarted name='oss.indeed.com/go/go-opine-test/go-library/library'"+flow)
+	require.Contains(t, out.String(), "##teamcity[testStarted name='Test_Library'"+flow)
+	require.Contains(t, out.String(), "##teamcity[buildStatisticValue key='CodeCoverageAbsSCovered' value='1']\n")
+	require.Contains(t, out.String(), "##teamcity[buildStatisticValue key='CodeCoverageAbsSTotal' value='2']\n")
+	require.Contains(t, out.String(), "##teamcity[buildStatisticValue key='CodeCoverageS' value='50.0']\n")
+}
+
+func Test_TestCmd_impl_tap(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	tapPath := filepath.Join(t.TempDir(), "results.tap")
+	tested := testCmd{
+		out:           io.Discard,
+		tap:           tapPath,
+		minCovPercent: 5,
+	}
+	err := tested.impl()
+	require.NoError(t, err)
+
+	tapBytes, err := os.ReadFile(tapPath)
+	require.NoError(t, err)
+	require.Contains(t, string(tapBytes), "TAP version 14\n")
+	require.Contains(t, string(tapBytes), "    ok 1 - Test_Library\n")
+	require.Regexp(t, `(?m)^ok \d - oss\.indeed\.com/go/go-opine-test/go-library/library$`, string(tapBytes))
+	require.Contains(t, string(tapBytes), "1..2\n")
+}
+
+func Test_TestCmd_impl_tapStdout(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	var out, errOut bytes.Buffer
+	tested := testCmd{
+		out:           &out,
+		errOut:        &errOut,
+		tap:           "-",
+		minCovPercent: 5,
+		color:         printing.ColorNever,
+	}
+	err := tested.impl()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out.String(), "TAP version 14\n"), out.String())
+	require.NotContains(t, out.String(), "Test coverage sufficient")
+	require.Contains(t, errOut.String(), "Test coverage sufficient (50.0% >= 5.0%)\n")
+}
+
+func Test_TestCmd_impl_tapCreateError(t *testing.T) {
+	tested := testCmd{
+		out: io.Discard,
+		tap: filepath.Join(t.TempDir(), "missing", "results.tap"),
+	}
+	err := tested.impl()
+	require.ErrorContains(t, err, "failed to create TAP output")
+}
+
+func Test_TestCmd_impl_jsonOut(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	jsonOutPath := filepath.Join(t.TempDir(), "events.json")
+	tested := testCmd{out: io.Discard, jsonOut: jsonOutPath, minCovPercent: 5}
+	require.NoError(t, tested.impl())
+
+	events, err := os.ReadFile(jsonOutPath)
+	require.NoError(t, err)
+	require.Contains(t, string(events), `"Action":"pass","Package":"oss.indeed.com/go/go-opine-test/go-library/library","Test":"Test_Library"`)
+}
+
+func Test_TestCmd_impl_jsonOutCreateError(t *testing.T) {
+	tested := testCmd{out: io.Discard, jsonOut: filepath.Join(t.TempDir(), "missing", "events.json")}
+	err := tested.impl()
+	require.ErrorContains(t, err, "failed to create go test -json output: ")
+}
+
+func Test_TestCmd_impl_failureSummary(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+	t.Setenv("LIBRARY_FAIL_UNIT_TESTS", "1")
+
+	var out bytes.Buffer
+	tested := testCmd{out: &out, color: printing.ColorNever}
+	err := tested.impl()
+	require.Error(t, err)
+	require.Contains(t, out.String(), "\n1 failure:\n\n1. oss.indeed.com/go/go-opine-test/go-library/library.Test_Library (")
+	require.Contains(t, out.String(), "   go test -race -run '^Test_Library$' ./library\n")
+}
+
+func Test_TestCmd_impl_summaryJSON(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	summaryPath := filepath.Join(t.TempDir(), "summary.json")
+	tested := testCmd{out: io.Discard, summaryJSON: summaryPath, minCovPercent: 51, maxSkipPercent: defaultMaxSkipPercent}
+	err := tested.impl()
+	require.Equal(t, errCoverageCheckFailed, err)
+
+	out, err := os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	var sum summary.Summary
+	require.NoError(t, json.Unmarshal(out, &sum))
+	require.Equal(t, summary.SchemaVersion, sum.SchemaVersion)
+	require.NotEmpty(t, sum.GoOpineVersion)
+	require.Regexp(t, `^go\d`, sum.GoVersion)
+	require.Equal(t, "test", sum.GoTestArgs[0])
+
+	pkgs := make(map[string]summary.Package)
+	for _, pkg := range sum.Packages {
+		pkgs[pkg.ImportPath] = pkg
+	}
+	library := pkgs["oss.indeed.com/go/go-opine-test/go-library/library"]
+	require.Equal(t, "pass", library.Outcome)
+	require.Equal(t, "Test_Library", library.Tests[0].Name)
+	require.Equal(t, "library/library_test.go", library.Tests[0].File)
+
+	require.Equal(t, 50.0, sum.Coverage.Percent)
+	require.Equal(
+		t,
+		[]summary.CoverageOf{{Path: "oss.indeed.com/go/go-opine-test/go-library/library", Statements: summary.Statements{Covered: 1, Total: 2, Percent: 50}}},
+		sum.Coverage.Packages,
+	)
+	require.Len(t, sum.Coverage.Files, 1)
+	require.Equal(t, []summary.Check{{Name: "min-coverage", Threshold: 51, Actual: 50, Passed: false}}, sum.Checks)
+	require.Equal(t, []summary.Error{{Message: "coverage check failed", Category: "coverage-check-failed"}}, sum.Errors)
+	require.Equal(t, int(exitCoverageCheckFailed), sum.ExitStatus)
+}
+
+func Test_TestCmd_impl_summaryJSONWriteError(t *testing.T) {
+	popd := pushd(t, "testdata", "go-library")
+	defer popd()
+
+	tested := testCmd{out: io.Discard, summaryJSON: filepath.Join(t.TempDir(), "missing", "summary.json"), minCovPercent: 51}
+	err := tested.impl()
+	require.ErrorIs(t, err, errCoverageCheckFailed)
+	require.ErrorIs(t, err, errReportFailed)
+	require.ErrorContains(t, err, "failed to write JSON summary: ")
+	require.Equal(t, exitReportFailed, exitStatus(err))
+}
+
+func Test_writeCoverageAnnotations(t *testing.T) {
+	var out bytes.Buffer
+	err := writeCoverageAnnotations(&out, map[string]float64{"b": 0.25, "a": 0.1, "c": 0.5}, 50)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::warning title=Insufficient test coverage::a has 10.0%25 test coverage (< 50.0%25)\n"+
+			"::warning title=Insufficient test coverage::b has 25.0%25 test coverage (< 50.0%25)\n",
+		out.String(),
+	)
+}
+
+func Test_TestCmd_impl_invalidColor(t *testing.T) {
+	tested := testCmd{out: io.Discard, color: "sometimes"}
+	err := tested.impl()
+	require.EqualError(t, err, `invalid color mode "sometimes" (must be auto, always, or never)`)
+}
+
 func Test_TestCmd_impl_outputsStillWrittenWhenTestsFail(t *testing.T) {
 	popd := pushd(t, "testdata", "go-library")
 	defer popd()
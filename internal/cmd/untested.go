@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// listedPackage is a package as listed by "go list -json".
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	GoFiles    []string
+	CgoFiles   []string
+}
+
+// checkUntested writes the packages that ran no tests (e.g. because they
+// have no test files) but contain code that is neither generated nor of
+// package main, except those allowed by the -allow-untested flag. A policy
+// failure is returned if there are any. Packages that failed are not
+// checked, since their failure is reported already. The require-tests
+// check, with the number of packages without tests, is added to sum if it
+// is not nil.
+func (t *testCmd) checkUntested(w io.Writer, palette printing.Palette, counts []gotest.TestCount, sum *summary.Summary) []error {
+	var candidates []string
+	for _, c := range counts {
+		if c.Tests == 0 && c.Outcome != "fail" && !t.allowedUntested(c.Package) {
+			candidates = append(candidates, c.Package)
+		}
+	}
+	if len(candidates) == 0 {
+		addCountCheck(sum, "require-tests", 0)
+		return nil
+	}
+	pkgs, err := goListJSON(t.goTestEnv, append(buildTags(t.goTestFlags), candidates...)...)
+	if err != nil {
+		return []error{fmt.Errorf("failed to list the packages without tests: %w", err)}
+	}
+	var untested []string
+	for _, pkg := range pkgs {
+		needsTests, err := hasHandwrittenCode(pkg)
+		if err != nil {
+			return []error{fmt.Errorf("failed to check %s for generated code: %w", pkg.ImportPath, err)}
+		}
+		if needsTests {
+			untested = append(untested, pkg.ImportPath)
+		}
+	}
+	addCountCheck(sum, "require-tests", len(untested))
+	if len(untested) == 0 {
+		return nil
+	}
+	slices.Sort(untested)
+	_, _ = fmt.Fprintln(w, palette.Red("Packages without tests:"))
+	for _, pkg := range untested {
+		_, _ = fmt.Fprintf(w, "  %s\n", pkg)
+	}
+	_, _ = fmt.Fprintln(w, "Add tests to them, or set the -allow-untested flag to allow packages without tests.")
+	return []error{categorize(errPolicyFailed, fmt.Errorf("%s without tests", countOf(len(untested), "package")))}
+}
+
+// allowedUntested returns true if the package matches an -allow-untested
+// pattern: an import path, or a pattern ending in "/..." that matches the
+// package and all packages below it.
+func (t *testCmd) allowedUntested(pkg string) bool {
+	for _, pattern := range t.allowUntested {
+		if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				return true
+			}
+		} else if pkg == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHandwrittenCode returns true if the package is not package main and
+// has a Go file that is not generated (see ast.IsGenerated).
+func hasHandwrittenCode(pkg listedPackage) (bool, error) {
+	if pkg.Name == "main" {
+		return false, nil
+	}
+	fset := token.NewFileSet()
+	for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return false, err
+		}
+		if !ast.IsGenerated(f) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// buildTags returns the -tags flags of the "go test" flags, which change
+// the files of the packages.
+func buildTags(flags []string) []string {
+	var tags []string
+	for i, flag := range flags {
+		switch {
+		case strings.HasPrefix(flag, "-tags=") || strings.HasPrefix(flag, "--tags="):
+			tags = append(tags, flag)
+		case (flag == "-tags" || flag == "--tags") && i+1 < len(flags):
+			tags = append(tags, flag, flags[i+1])
+		}
+	}
+	return tags
+}
+
+// goListJSON returns the packages matching the patterns, as listed by
+// "go list -json" with the additional environment variables. The patterns
+// may be preceded by build flags.
+func goListJSON(env []string, args ...string) ([]listedPackage, error) {
+	cmd := exec.Command("go", append([]string{"list", "-json=ImportPath,Name,Dir,GoFiles,CgoFiles"}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w", err)
+	}
+	var pkgs []listedPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return pkgs, nil
+			}
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+}
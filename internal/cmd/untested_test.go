@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// untestedEvents are the "go test -json" events of a module with a
+// tested package, and packages a, allowed, gen, and cmd without tests.
+const untestedEvents = `{"Action":"run","Package":"example.com/m/tested","Test":"TestFoo"}
+{"Action":"output","Package":"example.com/m/tested","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"output","Package":"example.com/m/tested","Test":"TestFoo","Output":"--- PASS: TestFoo (0.00s)\n"}
+{"Action":"pass","Package":"example.com/m/tested","Test":"TestFoo"}
+{"Action":"output","Package":"example.com/m/tested","Output":"ok  \texample.com/m/tested\t0.01s\n"}
+{"Action":"pass","Package":"example.com/m/tested"}
+{"Action":"output","Package":"example.com/m/a","Output":"?   \texample.com/m/a\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/m/a"}
+{"Action":"output","Package":"example.com/m/allowed","Output":"?   \texample.com/m/allowed\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/m/allowed"}
+{"Action":"output","Package":"example.com/m/gen","Output":"?   \texample.com/m/gen\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/m/gen"}
+{"Action":"output","Package":"example.com/m/cmd","Output":"?   \texample.com/m/cmd\t[no test files]\n"}
+{"Action":"skip","Package":"example.com/m/cmd"}
+`
+
+func Test_ReportCmd_impl_requireTests(t *testing.T) {
+	dir := writeModule(t, "")
+	for path, content := range map[string]string{
+		"tested/tested.go":      "package tested\n",
+		"tested/tested_test.go": "package tested\n",
+		"a/a.go":                "package a\n",
+		"allowed/allowed.go":    "package allowed\n",
+		"gen/gen.go":            "// Code generated by hand. DO NOT EDIT.\n\npackage gen\n",
+		"cmd/main.go":           "package main\n\nfunc main() {}\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+	}
+	eventsPath := filepath.Join(dir, "events.json")
+	require.NoError(t, os.WriteFile(eventsPath, []byte(untestedEvents), 0600))
+	popd := pushd(t, dir)
+	defer popd()
+
+	var out strings.Builder
+	summaryPath := filepath.Join(dir, "summary.json")
+	tested := reportCmd{
+		testCmd: testCmd{
+			out:            &out,
+			summaryJSON:    summaryPath,
+			maxSkipPercent: defaultMaxSkipPercent,
+			requireTests:   true,
+			allowUntested:  stringsFlag{"example.com/m/allowed/..."},
+		},
+		input: eventsPath,
+	}
+	err := tested.impl()
+	require.EqualError(t, err, "1 package without tests")
+	require.Equal(t, exitPolicyFailed, exitStatus(err))
+	require.Contains(t, out.String(), "Packages without tests:\n  example.com/m/a\n")
+	require.Equal(t, []summary.Check{{Name: "require-tests", Threshold: 0, Actual: 1, Passed: false}}, readSummary(t, summaryPath).Checks)
+
+	tested.allowUntested = append(tested.allowUntested, "example.com/m/a")
+	require.NoError(t, tested.impl())
+	require.Equal(t, []summary.Check{{Name: "require-tests", Threshold: 0, Actual: 0, Passed: true}}, readSummary(t, summaryPath).Checks)
+}
+
+func Test_buildTags(t *testing.T) {
+	require.Equal(
+		t,
+		[]string{"-tags=integration", "-tags", "e2e"},
+		buildTags([]string{"-count=1", "-tags=integration", "-tags", "e2e", "-short"}),
+	)
+}
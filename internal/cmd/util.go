@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"compress/gzip"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/google/subcommands"
 )
@@ -14,18 +17,18 @@ import (
 // If arguments were provided a usage error will be written and
 // subcommands.ExitUsageError will be returned.
 //
-// If impl() returns an error it is written to f.Output() and
-// subcommands.ExitFailure is returned. Otherwise subcommands.ExitSuccess
-// is returned.
+// If impl() returns an error it is written to f.Output() and the exit
+// status for the error is returned (see exitStatus). Otherwise
+// subcommands.ExitSuccess is returned.
 func executeNoArgs(f *flag.FlagSet, impl func() error) subcommands.ExitStatus {
 	if !ensureNoArgs(f) {
 		return subcommands.ExitUsageError
 	}
-	if err := impl(); err != nil {
+	err := impl()
+	if err != nil {
 		_, _ = fmt.Fprintln(f.Output(), err)
-		return subcommands.ExitFailure
 	}
-	return subcommands.ExitSuccess
+	return exitStatus(err)
 }
 
 // ensureNoArgs checks that no positional arguments were provided.
@@ -41,6 +44,21 @@ func ensureNoArgs(f *flag.FlagSet) bool {
 	return false
 }
 
+// stringsFlag is a flag.Value for a flag that may be repeated, collecting
+// the value of each.
+type stringsFlag []string
+
+var _ flag.Value = (*stringsFlag)(nil)
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 // closedTempFile creates a temp file, closes it, and returns the file path.
 func closedTempFile(dir, pattern string) (string, error) {
 	tmpCov, err := os.CreateTemp(dir, pattern)
@@ -52,3 +70,64 @@ func closedTempFile(dir, pattern string) (string, error) {
 	}
 	return tmpCov.Name(), nil
 }
+
+// createFile creates the file at the path for writing. If the path ends
+// with ".gz" what is written to the file is gzip-compressed.
+func createFile(path string) (io.WriteCloser, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	return &gzipFile{Writer: gzip.NewWriter(f), f: f}, nil
+}
+
+// gzipFile is a gzip-compressed file being written.
+type gzipFile struct {
+	*gzip.Writer
+	f *os.File
+}
+
+// Close flushes the compressed data and closes the file.
+func (g *gzipFile) Close() error {
+	err := g.Writer.Close()
+	if closeErr := g.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// openFile opens the file at the path for reading. If the path ends with
+// ".gz" the file is decompressed as it is read.
+func openFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &gunzipFile{Reader: r, f: f}, nil
+}
+
+// gunzipFile is a gzip-compressed file being read.
+type gunzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+// Close closes the file.
+func (g *gunzipFile) Close() error {
+	err := g.Reader.Close()
+	if closeErr := g.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
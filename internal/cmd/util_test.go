@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"compress/gzip"
 	"errors"
 	"flag"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -42,6 +44,15 @@ func Test_executeNoArgs_implReturnsError(t *testing.T) {
 	require.Equal(t, subcommands.ExitFailure, exitStatus)
 }
 
+func Test_executeNoArgs_implReturnsCategorizedError(t *testing.T) {
+	f := flag.NewFlagSet("foo", flag.ContinueOnError)
+	f.SetOutput(io.Discard)
+	err := f.Parse(nil)
+	require.NoError(t, err)
+	exitStatus := executeNoArgs(f, func() error { return errCoverageCheckFailed })
+	require.Equal(t, exitCoverageCheckFailed, exitStatus)
+}
+
 // pushd is a test utility that changes the current directory and returns a
 // function (suitable for defer) that will change it back.
 func pushd(t *testing.T, elem ...string) func() {
@@ -54,3 +65,65 @@ func pushd(t *testing.T, elem ...string) func() {
 		require.NoError(t, err)
 	}
 }
+
+func Test_createFile_openFile(t *testing.T) {
+	for _, name := range []string{"file.json", "file.json.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		w, err := createFile(path)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, "hello\n")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		r, err := openFile(path)
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, "hello\n", string(content), name)
+	}
+}
+
+func Test_createFile_gzipCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.gz")
+	w, err := createFile(path)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "hello\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", string(content))
+}
+
+func Test_createFile_error(t *testing.T) {
+	_, err := createFile(filepath.Join(t.TempDir(), "missing", "file.gz"))
+	require.Error(t, err)
+}
+
+func Test_openFile_notGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.gz")
+	require.NoError(t, os.WriteFile(path, []byte("hello\n"), 0666))
+	_, err := openFile(path)
+	require.Error(t, err)
+}
+
+func Test_openFile_missing(t *testing.T) {
+	_, err := openFile(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func Test_stringsFlag(t *testing.T) {
+	var s stringsFlag
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Var(&s, "s", "")
+	require.NoError(t, f.Parse([]string{"-s", "a b", "-s", "c"}))
+	require.Equal(t, stringsFlag{"a b", "c"}, s)
+	require.Equal(t, "a b, c", s.String())
+}
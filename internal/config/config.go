@@ -0,0 +1,349 @@
+// Package config is for reading the go-opine configuration file of a
+// project (.go-opine.yaml), which is checked in at the root of its module.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file.
+const FileName = ".go-opine.yaml"
+
+// Config is the configuration of go-opine for a project. Values that are
+// not set are nil or empty, leaving the defaults of the flags.
+type Config struct {
+	Coverage  Coverage   `yaml:"coverage"`
+	Reports   Reports    `yaml:"reports"`
+	Reporters []Reporter `yaml:"reporters"`
+	Test      Test       `yaml:"test"`
+	Skips     Skips      `yaml:"skips"`
+	// Quarantine are the quarantines of known-broken or flaky tests.
+	Quarantine []Quarantine `yaml:"quarantine"`
+	// Color is "auto", "always", or "never" (see the -color flag).
+	Color string `yaml:"color"`
+}
+
+// Coverage configures the coverage check.
+type Coverage struct {
+	// Min is the minimum code coverage percentage (see the -min-coverage
+	// flag).
+	Min *float64 `yaml:"min"`
+}
+
+// Reports configures the reports written, each to a path (see the flag
+// with the same name as the key).
+type Reports struct {
+	JUnit         string `yaml:"junit"`
+	TAP           string `yaml:"tap"`
+	XMLCov        string `yaml:"xmlcov"`
+	CoverProfile  string `yaml:"coverprofile"`
+	JSONOut       string `yaml:"json-out"`
+	SummaryJSON   string `yaml:"summary-json"`
+	BuildWarnings *bool  `yaml:"build-warnings"`
+}
+
+// Reporter is a reporter executable (see the -reporter flag).
+type Reporter struct {
+	Command string `yaml:"command"`
+	// Optional is true if the run does not fail when the reporter fails
+	// (see the -optional-reporter flag).
+	Optional bool `yaml:"optional"`
+}
+
+// Test configures how "go test" is run.
+type Test struct {
+	// Race is false to disable the race detector (see the -norace flag).
+	Race *bool `yaml:"race"`
+	// Flags are additional flags for "go test" (e.g. "-tags=integration").
+	Flags []string `yaml:"flags"`
+	// Exclude are package patterns (e.g. "./internal/gen/...") of packages
+	// that are neither tested nor included in the coverage.
+	Exclude []string `yaml:"exclude"`
+	// Env are additional environment variables for "go test".
+	Env map[string]string `yaml:"env"`
+	// RequireTests is true if packages with code that is neither generated
+	// nor of package main must run tests (see the -require-tests flag).
+	RequireTests *bool `yaml:"require-tests"`
+	// AllowUntested are the packages that may run no tests (see the
+	// -allow-untested flag).
+	AllowUntested []string `yaml:"allow-untested"`
+	// CountBaseline is the path of the test count baseline (see the
+	// -test-count-baseline flag).
+	CountBaseline string `yaml:"count-baseline"`
+}
+
+// Skips configures the policies for skipped tests.
+type Skips struct {
+	// RequireReason is true if tests must be skipped with a reason (see
+	// the -require-skip-reason flag).
+	RequireReason *bool `yaml:"require-reason"`
+	// MaxPercent is the maximum percentage of the tests of a package that
+	// may be skipped (see the -max-skip-percent flag).
+	MaxPercent *float64 `yaml:"max-percent"`
+	// ReasonPattern is a regular expression the reasons tests are skipped
+	// with must match (see the -skip-reason-pattern flag).
+	ReasonPattern string `yaml:"reason-pattern"`
+}
+
+// Quarantine quarantines tests: they still run, but their failures do not
+// fail the run until the quarantine expires.
+type Quarantine struct {
+	// Package is the import path of the package of the tests, or a
+	// pattern ending in "/..." that matches the package and all packages
+	// below it.
+	Package string `yaml:"package"`
+	// Test is a regular expression that must match the whole name of a
+	// test. All tests of the package match if it is empty.
+	Test   string `yaml:"test"`
+	Owner  string `yaml:"owner"`
+	Reason string `yaml:"reason"`
+	// Expires is the last day of the quarantine (e.g. "2026-12-31"). See
+	// ExpiryDate.
+	Expires string `yaml:"expires"`
+}
+
+// ExpiryDate returns the date of Expires (at midnight UTC), or the zero
+// time if it is not a valid date.
+func (q Quarantine) ExpiryDate() time.Time {
+	date, _ := time.Parse(time.DateOnly, q.Expires)
+	return date
+}
+
+// reservedTestFlags are the "go test" flags go-opine sets itself, which
+// can not be configured.
+var reservedTestFlags = []string{"-json", "-v", "-race", "-coverprofile", "-coverpkg", "-covermode", "-p"}
+
+// Find returns the path of the configuration file at the root of the
+// module containing dir, or an empty path if there is none.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			path := filepath.Join(dir, FileName)
+			if _, err := os.Stat(path); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return "", nil
+				}
+				return "", err
+			}
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and validates the configuration file at the path. If it is
+// not valid the error lists each problem on a separate line, prefixed with
+// its location (e.g. ".go-opine.yaml:3:5: unknown key ...").
+func Load(path string) (*Config, error) {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parse(path, in)
+}
+
+// parse parses and validates the configuration read from the file at the
+// path.
+func parse(path string, in []byte) (*Config, error) {
+	var cfg Config
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(in)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return &cfg, nil
+	}
+	v := validator{path: path, nodes: make(map[string]*yaml.Node)}
+	v.checkKeys(doc.Content[0], reflect.TypeOf(cfg), "")
+	// Unknown keys are ignored when decoding, so that the problems with
+	// the values are reported along with them.
+	if err := doc.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, msg := range typeErr.Errors {
+			// The messages start with "line <n>: ".
+			v.problems = append(v.problems, path+":"+strings.TrimPrefix(msg, "line "))
+		}
+	} else {
+		v.checkValues(&cfg)
+	}
+	if len(v.problems) > 0 {
+		return nil, errors.New(strings.Join(v.problems, "\n"))
+	}
+	return &cfg, nil
+}
+
+// validator collects the problems with a configuration file.
+type validator struct {
+	path     string
+	problems []string
+	// nodes are the nodes of the values, keyed by their key path (e.g.
+	// "coverage.min" or "reporters[1].command").
+	nodes map[string]*yaml.Node
+}
+
+// problemf adds a problem at the location of the node of the key path, or
+// of its closest ancestor if the key is not set.
+func (v *validator) problemf(key, format string, args ...interface{}) {
+	msg := fmt.Sprintf("%s: %s: %s", v.path, key, fmt.Sprintf(format, args...))
+	for k := key; k != ""; k = k[:max(strings.LastIndexAny(k, ".["), 0)] {
+		if n, ok := v.nodes[k]; ok {
+			msg = fmt.Sprintf("%s:%d:%d: %s: %s", v.path, n.Line, n.Column, key, fmt.Sprintf(format, args...))
+			break
+		}
+	}
+	v.problems = append(v.problems, msg)
+}
+
+// checkKeys checks that each key of the mappings in the node is a key of
+// the type the node is decoded into, and records the nodes by key path.
+func (v *validator) checkKeys(n *yaml.Node, t reflect.Type, key string) {
+	if key != "" {
+		v.nodes[key] = n
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			field, ok := fields[k.Value]
+			if !ok {
+				v.problems = append(v.problems, fmt.Sprintf(
+					"%s:%d:%d: unknown key %q%s; expected one of: %s",
+					v.path, k.Line, k.Column, k.Value, in(key), strings.Join(slices.Sorted(maps.Keys(fields)), ", "),
+				))
+				continue
+			}
+			v.checkKeys(val, field.Type, joinKey(key, k.Value))
+		}
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v.checkKeys(n.Content[i+1], t.Elem(), joinKey(key, n.Content[i].Value))
+		}
+	case t.Kind() == reflect.Slice && n.Kind == yaml.SequenceNode:
+		for i, elem := range n.Content {
+			v.checkKeys(elem, t.Elem(), key+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+// checkValues checks the values of the decoded configuration.
+func (v *validator) checkValues(cfg *Config) {
+	if cfg.Coverage.Min != nil && (*cfg.Coverage.Min < 0 || *cfg.Coverage.Min > 100) {
+		v.problemf("coverage.min", "must be between 0 and 100")
+	}
+	if cfg.Skips.MaxPercent != nil && (*cfg.Skips.MaxPercent < 0 || *cfg.Skips.MaxPercent > 100) {
+		v.problemf("skips.max-percent", "must be between 0 and 100")
+	}
+	if _, err := regexp.Compile(cfg.Skips.ReasonPattern); err != nil {
+		v.problemf("skips.reason-pattern", "invalid regular expression: %v", err)
+	}
+	switch cfg.Color {
+	case "", "auto", "always", "never":
+	default:
+		v.problemf("color", "must be auto, always, or never")
+	}
+	for i, r := range cfg.Reporters {
+		if strings.TrimSpace(r.Command) == "" {
+			v.problemf("reporters["+strconv.Itoa(i)+"].command", "must be set")
+		}
+	}
+	for i, flag := range cfg.Test.Flags {
+		key := "test.flags[" + strconv.Itoa(i) + "]"
+		name, _, _ := strings.Cut(flag, "=")
+		name = "-" + strings.TrimLeft(name, "-")
+		switch {
+		case !strings.HasPrefix(flag, "-"):
+			v.problemf(key, "must be a flag (e.g. -tags=integration)")
+		case slices.Contains(reservedTestFlags, name):
+			v.problemf(key, "%s is set by go-opine", name)
+		}
+	}
+	for i, pattern := range cfg.Test.Exclude {
+		if strings.TrimSpace(pattern) == "" {
+			v.problemf("test.exclude["+strconv.Itoa(i)+"]", "must not be empty")
+		}
+	}
+	for i, pattern := range cfg.Test.AllowUntested {
+		if strings.TrimSpace(pattern) == "" {
+			v.problemf("test.allow-untested["+strconv.Itoa(i)+"]", "must not be empty")
+		}
+	}
+	for i, q := range cfg.Quarantine {
+		key := "quarantine[" + strconv.Itoa(i) + "]"
+		for _, field := range []struct{ name, value string }{{"package", q.Package}, {"owner", q.Owner}, {"reason", q.Reason}} {
+			if strings.TrimSpace(field.value) == "" {
+				v.problemf(key+"."+field.name, "must be set")
+			}
+		}
+		if _, err := regexp.Compile(q.Test); err != nil {
+			v.problemf(key+".test", "invalid regular expression: %v", err)
+		}
+		if q.ExpiryDate().IsZero() {
+			v.problemf(key+".expires", "must be a date (e.g. 2026-12-31)")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Test.Env)) {
+		if name == "" || strings.ContainsAny(name, "= ") {
+			v.problemf("test.env."+name, "invalid environment variable name")
+		}
+	}
+}
+
+// yamlFields returns the fields of the struct type keyed by their YAML
+// key.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		fields[name] = f
+	}
+	return fields
+}
+
+// joinKey returns the key path of the key in the parent key path.
+func joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// in returns " in <key>" for a key path, or nothing for the top level.
+func in(key string) string {
+	if key == "" {
+		return ""
+	}
+	return " in " + key
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parse(t *testing.T) {
+	cfg, err := parse(FileName, []byte(`coverage:
+  min: 72.5
+reports:
+  junit: junit.xml
+  json-out: go-test.json.gz
+  build-warnings: false
+reporters:
+  - command: ./reporter --verbose
+    optional: true
+test:
+  race: true
+  flags: [-tags=integration, -count=1]
+  exclude: [./internal/gen/...]
+  env:
+    CGO_ENABLED: "0"
+  require-tests: true
+  allow-untested: [example.com/m/internal/version]
+  count-baseline: test-counts.json
+skips:
+  require-reason: true
+  max-percent: 20
+  reason-pattern: "[A-Z]+-[0-9]+"
+color: always
+`))
+	require.NoError(t, err)
+	minCov, buildWarnings, race, requireTests, requireReason, maxSkips := 72.5, false, true, true, true, 20.0
+	require.Equal(
+		t,
+		&Config{
+			Coverage:  Coverage{Min: &minCov},
+			Reports:   Reports{JUnit: "junit.xml", JSONOut: "go-test.json.gz", BuildWarnings: &buildWarnings},
+			Reporters: []Reporter{{Command: "./reporter --verbose", Optional: true}},
+			Test: Test{
+				Race:          &race,
+				Flags:         []string{"-tags=integration", "-count=1"},
+				Exclude:       []string{"./internal/gen/..."},
+				Env:           map[string]string{"CGO_ENABLED": "0"},
+				RequireTests:  &requireTests,
+				AllowUntested: []string{"example.com/m/internal/version"},
+				CountBaseline: "test-counts.json",
+			},
+			Skips: Skips{RequireReason: &requireReason, MaxPercent: &maxSkips, ReasonPattern: "[A-Z]+-[0-9]+"},
+			Color: "always",
+		},
+		cfg,
+	)
+}
+
+func Test_parse_empty(t *testing.T) {
+	for _, in := range []string{"", "# Nothing configured yet.\n"} {
+		cfg, err := parse(FileName, []byte(in))
+		require.NoError(t, err)
+		require.Equal(t, &Config{}, cfg)
+	}
+}
+
+func Test_parse_unknownKeys(t *testing.T) {
+	_, err := parse(FileName, []byte(`coverage:
+  minimum: 80
+reporters:
+  - cmd: ./reporter
+colour: never
+`))
+	require.EqualError(
+		t,
+		err,
+		FileName+`:2:3: unknown key "minimum" in coverage; expected one of: min
+`+FileName+`:4:5: unknown key "cmd" in reporters[0]; expected one of: command, optional
+`+FileName+`:5:1: unknown key "colour"; expected one of: color, coverage, quarantine, reporters, reports, skips, test
+`+FileName+`:4:5: reporters[0].command: must be set`,
+	)
+}
+
+func Test_parse_typeErrors(t *testing.T) {
+	_, err := parse(FileName, []byte(`coverage:
+  min: lots
+test:
+  flags: -race
+`))
+	require.EqualError(
+		t,
+		err,
+		FileName+":2: cannot unmarshal !!str `lots` into float64\n"+
+			FileName+":4: cannot unmarshal !!str `-race` into []string",
+	)
+}
+
+func Test_parse_invalidValues(t *testing.T) {
+	_, err := parse(FileName, []byte(`coverage:
+  min: 101
+reporters:
+  - optional: true
+test:
+  flags: [integration, -race, --coverprofile=c.out, -count=1]
+  exclude: [""]
+skips:
+  max-percent: -1
+  reason-pattern: "("
+color: sometimes
+`))
+	require.EqualError(
+		t,
+		err,
+		FileName+`:2:8: coverage.min: must be between 0 and 100
+`+FileName+`:9:16: skips.max-percent: must be between 0 and 100
+`+FileName+":10:19: skips.reason-pattern: invalid regular expression: error parsing regexp: missing closing ): `(`\n"+
+			FileName+`:11:8: color: must be auto, always, or never
+`+FileName+`:4:5: reporters[0].command: must be set
+`+FileName+`:6:11: test.flags[0]: must be a flag (e.g. -tags=integration)
+`+FileName+`:6:24: test.flags[1]: -race is set by go-opine
+`+FileName+`:6:31: test.flags[2]: -coverprofile is set by go-opine
+`+FileName+`:7:13: test.exclude[0]: must not be empty`,
+	)
+}
+
+func Test_parse_unknownKeysAndInvalidValues(t *testing.T) {
+	_, err := parse(FileName, []byte(`coverage:
+  minimum: 80
+test:
+  flags: [-race]
+`))
+	require.EqualError(
+		t,
+		err,
+		FileName+`:2:3: unknown key "minimum" in coverage; expected one of: min
+`+FileName+`:4:11: test.flags[0]: -race is set by go-opine`,
+	)
+}
+
+func Test_parse_syntaxError(t *testing.T) {
+	_, err := parse(FileName, []byte("coverage: [\n"))
+	require.ErrorContains(t, err, FileName+": yaml: ")
+}
+
+func Test_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("color: never\n"), 0600))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, "never", cfg.Color)
+
+	_, err = Load(filepath.Join(t.TempDir(), FileName))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_Find(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "internal", "pkg")
+	require.NoError(t, os.MkdirAll(sub, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/m\n"), 0600))
+
+	path, err := Find(sub)
+	require.NoError(t, err)
+	require.Empty(t, path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, FileName), nil, 0600))
+	path, err = Find(sub)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, FileName), path)
+}
+
+func Test_parse_quarantine(t *testing.T) {
+	cfg, err := parse(FileName, []byte(`quarantine:
+  - package: example.com/pkg/...
+    test: TestFlaky/.*
+    owner: "@team"
+    reason: flaky on CI
+    expires: 2026-12-31
+  - package: example.com/other
+    owner: "@other"
+    reason: broken
+    expires: "2027-01-15"
+`))
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]Quarantine{
+			{Package: "example.com/pkg/...", Test: "TestFlaky/.*", Owner: "@team", Reason: "flaky on CI", Expires: "2026-12-31"},
+			{Package: "example.com/other", Owner: "@other", Reason: "broken", Expires: "2027-01-15"},
+		},
+		cfg.Quarantine,
+	)
+	require.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), cfg.Quarantine[0].ExpiryDate())
+}
+
+func Test_parse_invalidQuarantine(t *testing.T) {
+	_, err := parse(FileName, []byte(`quarantine:
+  - package: example.com/pkg
+    test: "TestFlaky("
+    expires: soon
+`))
+	require.EqualError(
+		t,
+		err,
+		FileName+`:2:5: quarantine[0].owner: must be set
+`+FileName+`:2:5: quarantine[0].reason: must be set
+`+FileName+":3:11: quarantine[0].test: invalid regular expression: error parsing regexp: missing closing ): `TestFlaky(`\n"+
+			FileName+`:4:14: quarantine[0].expires: must be a date (e.g. 2026-12-31)`,
+	)
+}
@@ -0,0 +1,69 @@
+// Package github is for writing GitHub Actions workflow commands.
+package github
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Annotation levels.
+const (
+	Error   = "error"
+	Warning = "warning"
+	Notice  = "notice"
+)
+
+// Annotation is a message shown in the GitHub Actions summary and, if it
+// has a File, inline in the pull request diff.
+//
+// See https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions.
+type Annotation struct {
+	// Level is Error, Warning, or Notice.
+	Level string
+	// File is the path of the file, relative to the repository root. It
+	// may be empty.
+	File string
+	// Line and Col are the location in the File. Either may be 0.
+	Line int
+	Col  int
+	// Title is a short summary of the message. It may be empty.
+	Title   string
+	Message string
+}
+
+// String returns the workflow command that creates the annotation,
+// without a trailing newline.
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+		if a.Line > 0 {
+			props = append(props, "line="+strconv.Itoa(a.Line))
+			if a.Col > 0 {
+				props = append(props, "col="+strconv.Itoa(a.Col))
+			}
+		}
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+	cmd := "::" + a.Level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
@@ -0,0 +1,39 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Annotation_String(t *testing.T) {
+	a := Annotation{
+		Level:   Error,
+		File:    "pkg/foo_test.go",
+		Line:    42,
+		Col:     7,
+		Title:   "pkg.TestFoo",
+		Message: "got 1\nwant 2",
+	}
+	require.Equal(t, "::error file=pkg/foo_test.go,line=42,col=7,title=pkg.TestFoo::got 1%0Awant 2", a.String())
+}
+
+func Test_Annotation_String_messageOnly(t *testing.T) {
+	a := Annotation{Level: Warning, Message: "low coverage"}
+	require.Equal(t, "::warning::low coverage", a.String())
+}
+
+func Test_Annotation_String_lineWithoutFile(t *testing.T) {
+	a := Annotation{Level: Notice, Line: 3, Message: "hi"}
+	require.Equal(t, "::notice::hi", a.String())
+}
+
+func Test_Annotation_String_escaping(t *testing.T) {
+	a := Annotation{
+		Level:   Error,
+		File:    "a,b:c.go",
+		Title:   "50%: done, maybe",
+		Message: "100% sure: a, b\r\n",
+	}
+	require.Equal(t, "::error file=a%2Cb%3Ac.go,title=50%25%3A done%2C maybe::100%25 sure: a, b%0D%0A", a.String())
+}
@@ -0,0 +1,23 @@
+package gotest
+
+// attr is an attribute of a test set using testing.T.Attr.
+type attr struct {
+	Key   string
+	Value string
+}
+
+// eventsAttrs returns the attributes and the artifact directory reported
+// by the provided events, in the order they were reported. The artifact
+// directory is empty unless the test called testing.T.ArtifactDir while
+// running with "go test -artifacts".
+func eventsAttrs(events []event) (attrs []attr, artifactDir string) {
+	for _, e := range events {
+		switch e.Action {
+		case "attr":
+			attrs = append(attrs, attr{Key: e.Key, Value: e.Value})
+		case "artifacts":
+			artifactDir = e.Path
+		}
+	}
+	return attrs, artifactDir
+}
@@ -0,0 +1,47 @@
+package gotest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_eventsAttrs(t *testing.T) {
+	attrs, artifactDir := eventsAttrs([]event{
+		{Action: "run"},
+		{Action: "attr", Key: "ticket", Value: "GO-1"},
+		{Action: "output", Output: "=== ATTR  TestFoo ticket GO-1\n"},
+		{Action: "artifacts", Path: "/tmp/artifacts"},
+		{Action: "attr", Key: "owner", Value: "gophers"},
+	})
+	require.Equal(t, []attr{{Key: "ticket", Value: "GO-1"}, {Key: "owner", Value: "gophers"}}, attrs)
+	require.Equal(t, "/tmp/artifacts", artifactDir)
+}
+
+func Test_eventsAttrs_none(t *testing.T) {
+	attrs, artifactDir := eventsAttrs([]event{{Action: "run"}, {Action: "output", Output: "hi\n"}})
+	require.Nil(t, attrs)
+	require.Empty(t, artifactDir)
+}
+
+func Test_parseGoTestJSONOutput_attrs(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "attrs.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	results := make(map[string]result)
+	err = parseGoTestJSONOutput(f, resultAccepterFunc(func(res result) error {
+		res.walk(func(res result) { results[res.Key.Test] = res })
+		return nil
+	}), io.Discard)
+	require.NoError(t, err)
+
+	require.Equal(t, []attr{{Key: "ticket", Value: "GO-123"}, {Key: "owner", Value: "gophers"}}, results["TestTagged"].Attrs)
+	require.Equal(t, []attr{{Key: "link", Value: "https://example.com/x"}}, results["TestTagged/sub"].Attrs)
+	require.Nil(t, results["TestArtifacts"].Attrs)
+	require.Equal(t, "/src/fx/_artifacts/attrs/TestArtifacts/3065154586", results["TestArtifacts"].ArtifactDir)
+	require.Empty(t, results["TestTagged"].ArtifactDir)
+}
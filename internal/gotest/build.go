@@ -0,0 +1,143 @@
+package gotest
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	buildFailure = "build-fail"
+	// buildWarnings is the outcome of a build result that did not fail but
+	// had output, which is usually warnings (e.g. from the linker).
+	buildWarnings = "build-output"
+)
+
+// buildDiagnosticRegexp matches a compiler or vet diagnostic (e.g.
+// "pkg/foo.go:5:24: undefined: bar"). Vet sometimes prefixes diagnostics
+// with "vet: ".
+var buildDiagnosticRegexp = regexp.MustCompile(`^(?:vet: )?(\S+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// buildDiagnostic is an error or warning reported by the compiler or by
+// "go vet", which "go test" runs before running the tests.
+type buildDiagnostic struct {
+	// File is the path of the file as printed, which is relative to the
+	// working directory of "go test" unless it is outside of it.
+	File    string
+	Line    int
+	Col     int
+	Message string
+	// Vet is true if the diagnostic was reported by "go vet" rather than
+	// the compiler.
+	Vet bool
+}
+
+// String returns the diagnostic as printed (e.g. "foo.go:5:24: undefined:
+// bar").
+func (d buildDiagnostic) String() string {
+	if d.Col == 0 {
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Col, d.Message)
+}
+
+// path returns the absolute path of the file, or the File as printed if
+// that is not possible.
+func (d buildDiagnostic) path() string {
+	path, err := filepath.Abs(d.File)
+	if err != nil {
+		return d.File
+	}
+	return path
+}
+
+// parseBuildDiagnostics returns the diagnostics in the output of a build.
+// Vet diagnostics follow a "# [example.com/pkg]" line, and lines indented
+// with a tab continue the message of the previous diagnostic.
+func parseBuildDiagnostics(output string) []buildDiagnostic {
+	var (
+		diagnostics []buildDiagnostic
+		vet         bool
+		cur         *buildDiagnostic
+	)
+	for _, line := range strings.Split(output, "\n") {
+		if cur != nil && strings.HasPrefix(line, "\t") {
+			cur.Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		cur = nil
+		if strings.HasPrefix(line, "# ") {
+			vet = strings.HasPrefix(line, "# [")
+			continue
+		}
+		m := buildDiagnosticRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		diagnostics = append(diagnostics, buildDiagnostic{
+			File:    m[1],
+			Line:    n,
+			Col:     col,
+			Message: m[4],
+			Vet:     vet || strings.HasPrefix(line, "vet: "),
+		})
+		cur = &diagnostics[len(diagnostics)-1]
+	}
+	return diagnostics
+}
+
+// buildFailedMessage returns "vet failed" if "go vet" reported all the
+// diagnostics of the failed build result, or "build failed" otherwise.
+func buildFailedMessage(res result) string {
+	if len(res.Diagnostics) > 0 && !slices.ContainsFunc(res.Diagnostics, func(d buildDiagnostic) bool { return !d.Vet }) {
+		return "vet failed"
+	}
+	return "build failed"
+}
+
+// buildPackage returns the import path of the package built by the build
+// with the import path (e.g. "example.com/pkg" for
+// "example.com/pkg [example.com/pkg.test]" or "example.com/pkg.test").
+// The external test package of a package (e.g. "example.com/pkg_test
+// [example.com/pkg.test]") is not a package that can be tested, so the
+// package of its test binary is returned instead.
+func buildPackage(importPath string) string {
+	pkg, binary, _ := strings.Cut(importPath, " ")
+	if strings.HasSuffix(pkg, "_test") && binary != "" {
+		pkg = strings.Trim(binary, "[]")
+	}
+	return strings.TrimSuffix(pkg, ".test")
+}
+
+// removeBuildWarnings is a resultAccepter that drops the results of builds
+// that did not fail before forwarding the rest to the next result
+// accepter.
+type removeBuildWarnings struct {
+	next resultAccepter
+}
+
+var (
+	_ resultAccepter = (*removeBuildWarnings)(nil)
+	_ resultFinisher = (*removeBuildWarnings)(nil)
+)
+
+func newRemoveBuildWarnings(next resultAccepter) *removeBuildWarnings {
+	return &removeBuildWarnings{next: next}
+}
+
+func (r *removeBuildWarnings) Accept(res result) error {
+	if res.Key.ImportPath != "" && res.Outcome == buildWarnings {
+		return nil
+	}
+	return r.next.Accept(res)
+}
+
+// Finish finishes the next result accepter.
+func (r *removeBuildWarnings) Finish() error {
+	return finish(r.next)
+}
@@ -0,0 +1,121 @@
+package gotest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseBuildDiagnostics(t *testing.T) {
+	const output = "# example.com/pkg [example.com/pkg.test]\n" +
+		"./foo.go:5:24: undefined: bar\n" +
+		"./foo.go:9: cannot use x\n" +
+		"\thave int\n" +
+		"\twant string\n" +
+		"too many errors\n" +
+		"# [example.com/pkg]\n" +
+		"./foo_test.go:3:2: unreachable code\n" +
+		"vet: ./bar.go:1:1: expected 'package', found 'EOF'\n"
+	require.Equal(
+		t,
+		[]buildDiagnostic{
+			{File: "./foo.go", Line: 5, Col: 24, Message: "undefined: bar"},
+			{File: "./foo.go", Line: 9, Message: "cannot use x\nhave int\nwant string"},
+			{File: "./foo_test.go", Line: 3, Col: 2, Message: "unreachable code", Vet: true},
+			{File: "./bar.go", Line: 1, Col: 1, Message: "expected 'package', found 'EOF'", Vet: true},
+		},
+		parseBuildDiagnostics(output),
+	)
+	require.Nil(t, parseBuildDiagnostics("# example.com/pkg\nimport cycle not allowed\n"))
+}
+
+func Test_buildDiagnostic_String(t *testing.T) {
+	require.Equal(t, "foo.go:5:24: undefined: bar", buildDiagnostic{File: "foo.go", Line: 5, Col: 24, Message: "undefined: bar"}.String())
+	require.Equal(t, "foo.go:9: cannot use x", buildDiagnostic{File: "foo.go", Line: 9, Message: "cannot use x"}.String())
+}
+
+func Test_buildFailedMessage(t *testing.T) {
+	require.Equal(t, "build failed", buildFailedMessage(result{}))
+	require.Equal(t, "build failed", buildFailedMessage(result{Diagnostics: []buildDiagnostic{{Vet: true}, {}}}))
+	require.Equal(t, "vet failed", buildFailedMessage(result{Diagnostics: []buildDiagnostic{{Vet: true}}}))
+}
+
+func Test_buildPackage(t *testing.T) {
+	require.Equal(t, "example.com/pkg", buildPackage("example.com/pkg"))
+	require.Equal(t, "example.com/pkg", buildPackage("example.com/pkg [example.com/pkg.test]"))
+	require.Equal(t, "example.com/pkg", buildPackage("example.com/pkg.test"))
+	require.Equal(t, "example.com/pkg", buildPackage("example.com/pkg_test [example.com/pkg.test]"))
+	require.Equal(t, "example.com/dep", buildPackage("example.com/dep [example.com/pkg.test]"))
+}
+
+func Test_resultAggregator_buildWarnings(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var results []result
+	err = parseGoTestJSONOutput(f, resultAccepterFunc(func(res result) error {
+		results = append(results, res)
+		return nil
+	}), io.Discard)
+	require.NoError(t, err)
+
+	var keys []resultKey
+	for _, res := range results {
+		keys = append(keys, res.Key)
+	}
+	require.Equal(
+		t,
+		[]resultKey{
+			{ImportPath: "example.com/fx/dep"},
+			{Package: "example.com/fx/a"},
+			{Package: "example.com/fx/dep"},
+			{ImportPath: "example.com/fx/vet [example.com/fx/vet.test]"},
+			{Package: "example.com/fx/vet"},
+			{ImportPath: "example.com/fx/warn.test"},
+			{Package: "example.com/fx/warn", Test: "TestWarn"},
+			{Package: "example.com/fx/warn"},
+		},
+		keys,
+	)
+	require.Equal(t, []buildDiagnostic{{File: "fx/dep/dep.go", Line: 2, Col: 23, Message: "undefined: undefined"}}, results[0].Diagnostics)
+	require.Equal(t, "example.com/fx/dep", results[1].FailedBuild)
+	require.Equal(t, buildWarnings, results[5].Outcome)
+	require.Equal(t, "# example.com/fx/warn.test\nld: warning: something is odd\n", results[5].Output)
+}
+
+func Test_resultAggregator_FlushBuildWarnings(t *testing.T) {
+	var results []result
+	tested := newResultAggregator(resultAccepterFunc(func(res result) error {
+		results = append(results, res)
+		return nil
+	}))
+	require.NoError(t, tested.Accept(event{ImportPath: "example.com/b", Action: "build-output", Output: "# example.com/b\n"}))
+	require.NoError(t, tested.Accept(event{ImportPath: "example.com/a", Action: "build-output", Output: "# example.com/a\n"}))
+	require.NoError(t, tested.Accept(event{ImportPath: "example.com/c", Action: "build-output", Output: "# example.com/c\n"}))
+	require.NoError(t, tested.Accept(event{ImportPath: "example.com/c", Action: "build-fail"}))
+	require.NoError(t, tested.FlushBuildWarnings())
+	require.NoError(t, tested.CheckAllEventsConsumed())
+	require.Len(t, results, 3)
+	require.Equal(t, buildFailure, results[0].Outcome)
+	require.Equal(t, "example.com/a", results[1].Key.ImportPath)
+	require.Equal(t, buildWarnings, results[1].Outcome)
+	require.Equal(t, "example.com/b", results[2].Key.ImportPath)
+}
+
+func Test_removeBuildWarnings(t *testing.T) {
+	var results []result
+	tested := newRemoveBuildWarnings(resultAccepterFunc(func(res result) error {
+		results = append(results, res)
+		return nil
+	}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{ImportPath: "example.com/a"}, Outcome: buildWarnings}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{ImportPath: "example.com/a"}, Outcome: buildFailure}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "example.com/a"}, Outcome: "pass"}))
+	require.NoError(t, tested.Finish())
+	require.Len(t, results, 2)
+	require.Equal(t, buildFailure, results[0].Outcome)
+}
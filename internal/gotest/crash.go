@@ -0,0 +1,72 @@
+package gotest
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// panicRegexp matches an unrecovered panic (e.g. "panic: boom").
+	panicRegexp = regexp.MustCompile(`(?m)^panic: .*\n`)
+
+	// signalRegexp matches the line printed by "go test" when the test
+	// binary is terminated by a signal (e.g. "signal: killed").
+	signalRegexp = regexp.MustCompile(`(?m)^signal: .*\n`)
+)
+
+// testCrash describes why a test binary exited before reporting the
+// outcome of every test.
+type testCrash struct {
+	// Reason is a one line description of the crash (e.g. "panic: boom").
+	Reason string
+	// Dump is the panic message and goroutine dump. It is empty if the
+	// test binary did not panic.
+	Dump string
+}
+
+// findTestCrash looks for the cause of a test binary crash in the
+// provided outputs. The outputs must be from a test binary that is known
+// to have exited before all tests completed, so if no panic or signal is
+// found the test binary is assumed to have exited early (e.g. a test
+// called os.Exit).
+func findTestCrash(outputs ...string) testCrash {
+	for _, output := range outputs {
+		if loc := panicRegexp.FindStringIndex(output); loc != nil {
+			dump := output[loc[0]:]
+			// Do not include the package summary if the panic was
+			// attributed to the package.
+			if i := strings.Index(dump, "\nFAIL\t"); i >= 0 {
+				dump = dump[:i+1]
+			}
+			return testCrash{
+				Reason: strings.TrimSuffix(output[loc[0]:loc[1]], "\n"),
+				Dump:   dump,
+			}
+		}
+	}
+	for _, output := range outputs {
+		if loc := signalRegexp.FindStringIndex(output); loc != nil {
+			return testCrash{
+				Reason: "test binary terminated by " + strings.TrimSuffix(output[loc[0]:loc[1]], "\n"),
+			}
+		}
+	}
+	return testCrash{Reason: "test binary exited before the test completed (was os.Exit called?)"}
+}
+
+// crashedOutput returns the output to append to a test that was still
+// running when the test binary crashed. The testOutput must be the
+// output of the test so far: if it does not contain the panic (i.e. the
+// panic happened in some other test) the panic is appended as well. The
+// returned output ends with a "--- FAIL" line like the one "go test"
+// would have printed.
+func (c testCrash) crashedOutput(test, testOutput string) string {
+	var sb strings.Builder
+	sb.WriteString("    " + c.Reason + "\n")
+	if c.Dump != "" && !strings.Contains(testOutput, c.Dump) {
+		sb.WriteString("    the test binary crashed while " + test + " was running:\n\n")
+		sb.WriteString(indent(c.Dump, "        "))
+	}
+	sb.WriteString(failLine(test, 0))
+	return sb.String()
+}
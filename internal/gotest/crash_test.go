@@ -0,0 +1,50 @@
+package gotest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findTestCrash_panic(t *testing.T) {
+	const dump = "panic: boom\n\ngoroutine 10 [running]:\nexample.com/fx/crash.TestCrash.func1()\n"
+	crash := findTestCrash("=== RUN   TestOther\n", "=== RUN   TestCrash\n"+dump, "signal: killed\n")
+	require.Equal(t, testCrash{Reason: "panic: boom", Dump: dump}, crash)
+}
+
+func Test_findTestCrash_panicInPackageOutput(t *testing.T) {
+	const dump = "panic: boom\n\ngoroutine 10 [running]:\nexample.com/fx/crash.TestCrash.func1()\n"
+	crash := findTestCrash(dump + "FAIL\texample.com/fx/crash\t0.01s\n")
+	require.Equal(t, testCrash{Reason: "panic: boom", Dump: dump}, crash)
+}
+
+func Test_findTestCrash_signal(t *testing.T) {
+	crash := findTestCrash("=== RUN   TestCrash\n", "signal: killed\n")
+	require.Equal(t, testCrash{Reason: "test binary terminated by signal: killed"}, crash)
+}
+
+func Test_findTestCrash_exit(t *testing.T) {
+	crash := findTestCrash("=== RUN   TestCrash\n")
+	require.Equal(t, "test binary exited before the test completed (was os.Exit called?)", crash.Reason)
+	require.Empty(t, crash.Dump)
+}
+
+func Test_testCrash_crashedOutput(t *testing.T) {
+	crash := testCrash{Reason: "panic: boom", Dump: "panic: boom\n\ngoroutine 10 [running]:\n"}
+	require.Equal(
+		t,
+		"    panic: boom\n--- FAIL: TestCrash (0.00s)\n",
+		crash.crashedOutput("TestCrash", "=== RUN   TestCrash\npanic: boom\n\ngoroutine 10 [running]:\n"),
+	)
+	require.Equal(
+		t,
+		"    panic: boom\n"+
+			"    the test binary crashed while TestOther was running:\n"+
+			"\n"+
+			"        panic: boom\n"+
+			"\n"+
+			"        goroutine 10 [running]:\n"+
+			"--- FAIL: TestOther (0.00s)\n",
+		crash.crashedOutput("TestOther", "=== RUN   TestOther\n"),
+	)
+}
@@ -0,0 +1,166 @@
+package gotest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// closeDanglingTests completes the results of tests in the provided
+// package that never reported an outcome, which happens when the test
+// binary exits before all tests complete. It must be called when the
+// package completes, before the package result is created.
+//
+// Each dangling test is failed. If the test binary was interrupted by
+// "go test -timeout" the goroutine dump printed by the timeout is removed
+// from the output of the test it was attributed to and returned so that it
+// can be added to the package result instead, and each dangling test gets
+// the goroutines belonging to it. If the test binary crashed (e.g. a test
+// panicked or called os.Exit) each innermost dangling test gets the panic,
+// if any, and each dangling test with a dangling subtest only gets a line
+// naming the subtest that crashed.
+//
+// The returned reason describes why the test binary exited early. It is
+// empty if the package had no dangling tests.
+func (a *resultAggregator) closeDanglingTests(pkg string) (pkgOutput, reason string, err error) {
+	keys := a.danglingTests(pkg)
+	if len(keys) == 0 {
+		return "", "", nil
+	}
+
+	outputs := make([]string, len(keys))
+	for i, rk := range keys {
+		outputs[i] = eventsOutput(a.events[rk])
+	}
+	// Older versions of Go attribute panics to the package.
+	outputs = append(outputs, eventsOutput(a.events[resultKey{Package: pkg}]))
+
+	var results []result
+	if timeout, i, j, ok := findTimeoutIn(outputs); ok {
+		reason = "timed out after " + timeout.After
+		if i < len(keys) {
+			outputs[i], pkgOutput = outputs[i][:j], outputs[i][j:]
+		}
+		for i, rk := range keys {
+			results = append(results, result{
+				Key:     rk,
+				Outcome: testFailure,
+				Output:  outputs[i] + timeout.timedOutOutput(rk.Test, hasSubtests(keys, rk.Test)),
+				Elapsed: timeout.Running[rk.Test],
+				Reason:  reason,
+			})
+		}
+	} else {
+		crash := findTestCrash(outputs...)
+		reason = crash.Reason
+		for i, rk := range keys {
+			res := result{Key: rk, Outcome: testFailure, Reason: reason}
+			if sub, ok := crashedSubtest(keys, outputs, crash, rk.Test); ok {
+				// The crash is reported by the subtest, so that it is
+				// only reported once.
+				res.Reason = "subtest " + sub + " crashed"
+				res.Output = outputs[i] + "    " + res.Reason + "\n" + failLine(rk.Test, 0)
+			} else {
+				res.Output = outputs[i] + crash.crashedOutput(rk.Test, outputs[i])
+			}
+			results = append(results, res)
+		}
+	}
+
+	for _, res := range results {
+		res.Attrs, res.ArtifactDir = eventsAttrs(a.events[res.Key])
+		res.ErrorOutput = eventsErrorOutput(a.events[res.Key])
+		delete(a.events, res.Key)
+		if err := a.to.Accept(res); err != nil {
+			return "", "", err
+		}
+	}
+	return pkgOutput, reason, nil
+}
+
+// findTimeoutIn looks for a "go test -timeout" panic in each of the
+// provided outputs. If one is found the index of the output it was found
+// in and the offset of the panic in that output are returned.
+func findTimeoutIn(outputs []string) (testTimeout, int, int, bool) {
+	for i, output := range outputs {
+		if timeout, j, ok := findTestTimeout(output); ok {
+			return timeout, i, j, true
+		}
+	}
+	return testTimeout{}, 0, 0, false
+}
+
+// danglingTests returns the keys of all tests in the provided package
+// that have events but no outcome. Subtests are ordered before their
+// parents (as "go test" would complete them) and otherwise the keys are
+// sorted by test name.
+func (a *resultAggregator) danglingTests(pkg string) []resultKey {
+	var keys []resultKey
+	for rk := range a.events {
+		if rk.Package == pkg && rk.Test != "" {
+			keys = append(keys, rk)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		di, dj := strings.Count(keys[i].Test, "/"), strings.Count(keys[j].Test, "/")
+		if di != dj {
+			return di > dj
+		}
+		return keys[i].Test < keys[j].Test
+	})
+	return keys
+}
+
+// hasSubtests returns true iff any of the provided keys is a subtest of
+// the named test.
+func hasSubtests(keys []resultKey, test string) bool {
+	for _, rk := range keys {
+		if strings.HasPrefix(rk.Test, test+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// crashedSubtest returns the name of the innermost dangling subtest of
+// the named test that crashed: the one whose output contains the panic,
+// or else the first. It returns false if the test has no dangling
+// subtests.
+func crashedSubtest(keys []resultKey, outputs []string, crash testCrash, test string) (string, bool) {
+	var innermost []int
+	for i, rk := range keys {
+		if strings.HasPrefix(rk.Test, test+"/") && !hasSubtests(keys, rk.Test) {
+			innermost = append(innermost, i)
+		}
+	}
+	if len(innermost) == 0 {
+		return "", false
+	}
+	for _, i := range innermost {
+		if crash.Dump != "" && strings.Contains(outputs[i], crash.Dump) {
+			return keys[i].Test, true
+		}
+	}
+	return keys[innermost[0]].Test, true
+}
+
+// failLine returns a "--- FAIL" line like the one "go test" prints when a
+// test fails.
+func failLine(test string, elapsed time.Duration) string {
+	return fmt.Sprintf("--- FAIL: %s (%.2fs)\n", test, elapsed.Seconds())
+}
+
+// indent prefixes every non-empty line of the provided text. A newline is
+// added to the end of the text if it does not already end with one.
+func indent(text, prefix string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n") {
+		if line != "\n" {
+			sb.WriteString(prefix)
+		}
+		sb.WriteString(line)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
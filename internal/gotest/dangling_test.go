@@ -0,0 +1,157 @@
+package gotest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseGoTestJSONOutput_timeout(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "timeout.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	results := make(map[string]result)
+	err = parseGoTestJSONOutput(f, resultAccepterFunc(func(res result) error {
+		res.walk(func(res result) { results[res.Key.Test] = res })
+		return nil
+	}), io.Discard)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	require.Equal(t, "pass", results["TestQuick"].Outcome)
+	for _, test := range []string{"TestParallel", "TestHang", "TestHang/sub"} {
+		res := results[test]
+		require.Equal(t, "fail", res.Outcome, test)
+		require.Equal(t, "timed out after 1s", res.Reason, test)
+		require.Contains(t, res.Output, "--- FAIL: "+test+" (", test)
+		require.NotContains(t, res.Output, "goroutine 1 [", test)
+	}
+	require.Contains(t, results["TestParallel"].Output, "hang.TestParallel(")
+	require.Contains(t, results["TestHang"].Output, "hang.TestHang(")
+	require.NotContains(t, results["TestHang"].Output, "hang.TestHang.func1(")
+	require.Contains(t, results["TestHang/sub"].Output, "hang.TestHang.func1(")
+	require.Equal(t, time.Second, results["TestHang/sub"].Elapsed)
+
+	pkgRes := results[""]
+	require.Equal(t, "fail", pkgRes.Outcome)
+	require.True(t, pkgRes.Crashed)
+	require.Equal(t, "timed out after 1s", pkgRes.Reason)
+	require.Contains(t, pkgRes.Output, "panic: test timed out after 1s\n")
+	require.Contains(t, pkgRes.Output, "goroutine 1 [")
+}
+
+func Test_parseGoTestJSONOutput_crash(t *testing.T) {
+	testcases := []struct {
+		fixture        string
+		expectedReason string
+		crashingTest   string
+	}{
+		{
+			fixture:        "crash-panic.json",
+			expectedReason: "panic: boom",
+			crashingTest:   "TestCrash",
+		},
+		{
+			fixture:        "crash-exit.json",
+			expectedReason: "test binary exited before the test completed (was os.Exit called?)",
+		},
+		{
+			fixture:        "crash-signal.json",
+			expectedReason: "test binary terminated by signal: killed",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tc.fixture))
+			require.NoError(t, err)
+			defer f.Close()
+
+			results := make(map[string]result)
+			err = parseGoTestJSONOutput(f, resultAccepterFunc(func(res result) error {
+				results[res.Key.Test] = res
+				return nil
+			}), io.Discard)
+			require.NoError(t, err)
+
+			require.Equal(t, "pass", results["TestQuick"].Outcome)
+			require.Equal(t, "fail", results["TestCrash"].Outcome)
+			require.Equal(t, tc.expectedReason, results["TestCrash"].Reason)
+			require.Contains(t, results["TestCrash"].Output, "about to crash\n")
+			require.Contains(t, results["TestCrash"].Output, "--- FAIL: TestCrash (")
+
+			pkgRes := results[""]
+			require.Equal(t, "fail", pkgRes.Outcome)
+			require.True(t, pkgRes.Crashed)
+			require.Equal(t, tc.expectedReason, pkgRes.Reason)
+
+			if tc.crashingTest != "" {
+				// The other test that was interrupted should also get the
+				// panic, but only once.
+				slow := results["TestSlow"]
+				require.Equal(t, "fail", slow.Outcome)
+				require.Equal(t, tc.expectedReason, slow.Reason)
+				require.Contains(t, slow.Output, "crash.TestCrash.func1()")
+				require.Equal(t, 1, strings.Count(results[tc.crashingTest].Output, "goroutine 10 [running]"))
+			}
+		})
+	}
+}
+
+func Test_parseGoTestJSONOutput_crashInSubtest(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "crash-subtest.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	results := make(map[string]result)
+	err = parseGoTestJSONOutput(f, resultAccepterFunc(func(res result) error {
+		res.walk(func(res result) { results[res.Key.Test] = res })
+		return nil
+	}), io.Discard)
+	require.NoError(t, err)
+
+	sub := results["TestPanic/sub"]
+	require.Equal(t, "fail", sub.Outcome)
+	require.Equal(t, "panic: boom", sub.Reason)
+	require.Equal(t, 1, strings.Count(sub.Output, "goroutine 8 [running]"))
+
+	// The parent was still running too, but only names the subtest.
+	parent := results["TestPanic"]
+	require.Equal(t, "fail", parent.Outcome)
+	require.Equal(t, "subtest TestPanic/sub crashed", parent.Reason)
+	require.Equal(t, "=== RUN   TestPanic\n    subtest TestPanic/sub crashed\n--- FAIL: TestPanic (0.00s)\n", parent.Output)
+
+	pkgRes := results[""]
+	require.True(t, pkgRes.Crashed)
+	require.Equal(t, "panic: boom", pkgRes.Reason)
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	var quietOutputBuf, tapBuf bytes.Buffer
+	require.ErrorIs(t, Report(f, QuietOutput(&quietOutputBuf), TAPReport(&tapBuf)), ErrTestsFailed)
+	require.Equal(t, 1, strings.Count(quietOutputBuf.String(), "goroutine 8 [running]"))
+	require.Equal(t, 1, strings.Count(tapBuf.String(), "goroutine 8 [running]"))
+}
+
+func Test_resultAggregator_Accept_noDanglingTests(t *testing.T) {
+	var results []result
+	tested := newResultAggregator(resultAccepterFunc(func(res result) error { results = append(results, res); return nil }))
+	require.NoError(t, tested.Accept(event{Action: "run", Package: "pkg", Test: "TestSome"}))
+	require.NoError(t, tested.Accept(event{Action: "pass", Package: "pkg", Test: "TestSome"}))
+	require.NoError(t, tested.Accept(event{Action: "pass", Package: "pkg"}))
+	require.NoError(t, tested.CheckAllEventsConsumed())
+	require.Len(t, results, 2)
+	require.False(t, results[1].Crashed)
+	require.Empty(t, results[1].Reason)
+}
+
+func Test_indent(t *testing.T) {
+	require.Equal(t, "  a\n\n  b\n", indent("a\n\nb\n", "  "))
+	require.Equal(t, "  a\n  b\n", indent("a\nb", "  "))
+}
@@ -2,7 +2,10 @@ package gotest
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"time"
 )
@@ -17,8 +20,14 @@ type event struct {
 	Test        string
 	Elapsed     float64 // seconds
 	Output      string
+	OutputType  string
 	FailedBuild string
 	ImportPath  string
+	// Key and Value are set for "attr" events (see testing.T.Attr).
+	Key   string
+	Value string
+	// Path is set for "artifacts" events (see testing.T.ArtifactDir).
+	Path string
 }
 
 // eventAccepter accepts events created by an eventStreamParser.
@@ -26,6 +35,30 @@ type eventAccepter interface {
 	Accept(e event) error
 }
 
+// multiEventAccepter accepts events and forwards them on to zero or
+// more downstream event accepters.
+type multiEventAccepter struct {
+	accepters []eventAccepter
+}
+
+var _ eventAccepter = (*multiEventAccepter)(nil)
+
+func newMultiEventAccepter(accepter ...eventAccepter) *multiEventAccepter {
+	return &multiEventAccepter{accepters: accepter}
+}
+
+// Accept forwards the event to the downstream eventAccepters. If any
+// eventAccepter returns an error processing stops immediately and that
+// error is returned to the caller.
+func (m multiEventAccepter) Accept(e event) error {
+	for _, accepter := range m.accepters {
+		if err := accepter.Accept(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // eventConverter converts a single line (without the newline)
 // to events.
 type eventConverter interface {
@@ -34,7 +67,8 @@ type eventConverter interface {
 
 // jsonEventConverter converts JSON event lines (as printed by
 // "go test -json") into singleton lists with the corresponding
-// event.
+// event. A line that is JSON but not an event (e.g. a structured log
+// line printed by TestMain) cannot be converted.
 type jsonEventConverter struct {
 }
 
@@ -45,40 +79,105 @@ func (jsonEventConverter) Convert(line []byte) ([]event, error) {
 	if err := json.Unmarshal(line, &e); err != nil {
 		return nil, err
 	}
+	if e.Action == "" {
+		return nil, errors.New("not a test event: no Action")
+	}
 	return []event{e}, nil
 }
 
 // eventStreamParser reads "go test -json" output, converts
 // each line to an event, and passes each event to the eventAccepter.
+//
+// Lines that cannot be converted (e.g. output a cgo library writes
+// directly to stdout) are attributed to the package that is currently
+// running as "output" events, and a warning is written to warn.
 type eventStreamParser struct {
 	to        eventAccepter
 	converter eventConverter
+	warn      io.Writer
+
+	// running are the packages that have started but not completed,
+	// ordered from least to most recently active.
+	running []string
 }
 
-func newEventStreamParser(to eventAccepter) *eventStreamParser {
+func newEventStreamParser(to eventAccepter, warn io.Writer) *eventStreamParser {
 	return &eventStreamParser{
 		to:        to,
 		converter: &jsonEventConverter{},
+		warn:      warn,
 	}
 }
 
 // Parse "go test -json" output into events and pass them to the
-// eventAccepter.
+// eventAccepter. Lines may be arbitrarily long.
 //
-// If any line is not JSON, or if the eventAccepter returns an
-// error then Parse will stop immediately and return the error.
+// If the reader or the eventAccepter returns an error then Parse will
+// stop immediately and return the error.
 func (esp *eventStreamParser) Parse(r io.Reader) error {
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		events, err := esp.converter.Convert(scanner.Bytes())
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if acceptErr := esp.accept(bytes.TrimSuffix(line, []byte{'\n'})); acceptErr != nil {
+				return acceptErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
 		if err != nil {
 			return err
 		}
-		for _, e := range events {
-			if err := esp.to.Accept(e); err != nil {
-				return err
-			}
+	}
+}
+
+// accept converts a single line (without the newline) to events and
+// passes them to the eventAccepter.
+func (esp *eventStreamParser) accept(line []byte) error {
+	events, err := esp.converter.Convert(line)
+	if err != nil {
+		events = esp.unconvertible(line, err)
+	}
+	for _, e := range events {
+		esp.track(e)
+		if err := esp.to.Accept(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unconvertible returns the events for a line that could not be
+// converted, and writes a warning about it.
+func (esp *eventStreamParser) unconvertible(line []byte, err error) []event {
+	const maxQuoted = 80
+	quoted := line
+	if len(quoted) > maxQuoted {
+		quoted = quoted[:maxQuoted]
+	}
+	if len(esp.running) == 0 {
+		_, _ = fmt.Fprintf(esp.warn, "go-opine: warning: ignoring unexpected output %q while no package was running (%v)\n", quoted, err)
+		return nil
+	}
+	pkg := esp.running[len(esp.running)-1]
+	_, _ = fmt.Fprintf(esp.warn, "go-opine: warning: attributing unexpected output %q to package %s (%v)\n", quoted, pkg, err)
+	return []event{{Action: "output", Package: pkg, Output: string(line) + "\n"}}
+}
+
+// track updates the packages that are currently running.
+func (esp *eventStreamParser) track(e event) {
+	if e.Package == "" {
+		return
+	}
+	for i, pkg := range esp.running {
+		if pkg == e.Package {
+			esp.running = append(esp.running[:i], esp.running[i+1:]...)
+			break
 		}
 	}
-	return scanner.Err()
+	if e.Test == "" && isTestOrPackageComplete(e.Action) {
+		return
+	}
+	esp.running = append(esp.running, e.Package)
 }
@@ -1,6 +1,10 @@
 package gotest
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -20,7 +24,6 @@ func Test_eventStreamParser(t *testing.T) {
 		GoTestOutput string
 
 		ExpectedEvent event
-		ExpectedError bool
 	}
 	testcases := []testcase{
 		{
@@ -82,10 +85,13 @@ func Test_eventStreamParser(t *testing.T) {
 			},
 		},
 		{
-			Description:  "event parser should fail to parse text output",
-			GoTestOutput: `=== RUN   Test_eventStreamParser`,
+			Description:  "event parser should parse very long lines",
+			GoTestOutput: `{"Action":"output","Output":"` + strings.Repeat("a", bufio.MaxScanTokenSize+1) + `"}`,
 
-			ExpectedError: true,
+			ExpectedEvent: event{
+				Action: "output",
+				Output: strings.Repeat("a", bufio.MaxScanTokenSize+1),
+			},
 		},
 	}
 
@@ -95,14 +101,67 @@ func Test_eventStreamParser(t *testing.T) {
 			parser := newEventStreamParser(eventAccepterFunc(func(e event) error {
 				observedEvent = e
 				return nil
-			}))
+			}), io.Discard)
 			err := parser.Parse(strings.NewReader(tc.GoTestOutput))
-			if tc.ExpectedError {
-				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				require.Equal(t, tc.ExpectedEvent, observedEvent)
-			}
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedEvent, observedEvent)
 		})
 	}
 }
+
+func Test_eventStreamParser_notJSON(t *testing.T) {
+	const output = `{"Action":"start","Package":"pkg1"}
+{"Action":"start","Package":"pkg2"}
+{"Action":"run","Package":"pkg1","Test":"Test_Some"}
+not JSON while pkg1 is the most recently active
+{"level":"info","msg":"JSON but not an event"}
+{"Action":"pass","Package":"pkg1"}
+not JSON while only pkg2 is running
+{"Action":"pass","Package":"pkg2"}
+not JSON while nothing is running`
+	var (
+		events []event
+		warn   bytes.Buffer
+	)
+	parser := newEventStreamParser(eventAccepterFunc(func(e event) error {
+		events = append(events, e)
+		return nil
+	}), &warn)
+	require.NoError(t, parser.Parse(strings.NewReader(output)))
+	require.Equal(
+		t,
+		[]event{
+			{Action: "start", Package: "pkg1"},
+			{Action: "start", Package: "pkg2"},
+			{Action: "run", Package: "pkg1", Test: "Test_Some"},
+			{Action: "output", Package: "pkg1", Output: "not JSON while pkg1 is the most recently active\n"},
+			{Action: "output", Package: "pkg1", Output: "{\"level\":\"info\",\"msg\":\"JSON but not an event\"}\n"},
+			{Action: "pass", Package: "pkg1"},
+			{Action: "output", Package: "pkg2", Output: "not JSON while only pkg2 is running\n"},
+			{Action: "pass", Package: "pkg2"},
+		},
+		events,
+	)
+	require.Equal(t, 4, strings.Count(warn.String(), "go-opine: warning:"))
+	require.Contains(t, warn.String(), "not JSON while nothing is running")
+}
+
+func Test_eventStreamParser_acceptError(t *testing.T) {
+	expectedErr := errors.New("fail boat")
+	parser := newEventStreamParser(eventAccepterFunc(func(event) error { return expectedErr }), io.Discard)
+	require.Equal(t, expectedErr, parser.Parse(strings.NewReader(`{"Action":"start","Package":"pkg"}`+"\n")))
+}
+
+func Test_eventStreamParser_readError(t *testing.T) {
+	expectedErr := errors.New("fail boat")
+	parser := newEventStreamParser(eventAccepterFunc(func(event) error { return nil }), io.Discard)
+	require.Equal(t, expectedErr, parser.Parse(io.MultiReader(strings.NewReader(`{"Action":"start","Package":"pkg"}`+"\n"), errorReader{err: expectedErr})))
+}
+
+type errorReader struct {
+	err error
+}
+
+func (e errorReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
@@ -0,0 +1,104 @@
+package gotest
+
+import (
+	"oss.indeed.com/go/go-opine/testresult"
+)
+
+// exportedResults is a resultAccepter that converts results to
+// testresult.Results and passes them to a testresult.ResultAccepter.
+type exportedResults struct {
+	to testresult.ResultAccepter
+}
+
+var (
+	_ resultAccepter = (*exportedResults)(nil)
+	_ resultFinisher = (*exportedResults)(nil)
+)
+
+func (e *exportedResults) Accept(res result) error {
+	return e.to.Accept(exportResult(res))
+}
+
+// Finish finishes the testresult.ResultAccepter if it is a
+// testresult.ResultFinisher.
+func (e *exportedResults) Finish() error {
+	if f, ok := e.to.(testresult.ResultFinisher); ok {
+		return f.Finish()
+	}
+	return nil
+}
+
+// exportedEvents is an eventAccepter that converts events to
+// testresult.Events and passes them to a testresult.EventAccepter.
+type exportedEvents struct {
+	to testresult.EventAccepter
+}
+
+var _ eventAccepter = (*exportedEvents)(nil)
+
+func (e *exportedEvents) Accept(ev event) error {
+	return e.to.Accept(testresult.Event(ev))
+}
+
+// exportResult converts a result, with its subtests, to a
+// testresult.Result.
+func exportResult(res result) testresult.Result {
+	exported := testresult.Result{
+		Package:     res.Key.Package,
+		Test:        res.Key.Test,
+		ImportPath:  res.Key.ImportPath,
+		Outcome:     res.Outcome,
+		Output:      res.Output,
+		ErrorOutput: res.ErrorOutput,
+		Elapsed:     res.Elapsed,
+		Reason:      res.Reason,
+		Crashed:     res.Crashed,
+		ArtifactDir: res.ArtifactDir,
+		FailedBuild: res.FailedBuild,
+		File:        res.File,
+		Line:        res.Line,
+	}
+	for _, a := range res.Attrs {
+		exported.Attrs = append(exported.Attrs, testresult.Attr(a))
+	}
+	for _, race := range res.Races {
+		exported.Races = append(exported.Races, exportDataRace(race))
+	}
+	for _, d := range res.Diagnostics {
+		exported.Diagnostics = append(exported.Diagnostics, testresult.Diagnostic(d))
+	}
+	if res.Quarantine != nil {
+		q := testresult.Quarantine(*res.Quarantine)
+		exported.Quarantine = &q
+	}
+	for _, sub := range res.Subtests {
+		exported.Subtests = append(exported.Subtests, exportResult(sub))
+	}
+	return exported
+}
+
+// exportDataRace converts a dataRace to a testresult.DataRace.
+func exportDataRace(race dataRace) testresult.DataRace {
+	exported := testresult.DataRace{
+		Current:  exportRaceAccess(race.Current),
+		Previous: exportRaceAccess(race.Previous),
+	}
+	for _, g := range race.Goroutines {
+		exported.Goroutines = append(exported.Goroutines, testresult.RaceGoroutine{ID: g.ID, Stack: exportStack(g.Stack)})
+	}
+	return exported
+}
+
+// exportRaceAccess converts a raceAccess to a testresult.RaceAccess.
+func exportRaceAccess(access raceAccess) testresult.RaceAccess {
+	return testresult.RaceAccess{Op: access.Op, Goroutine: access.Goroutine, Stack: exportStack(access.Stack)}
+}
+
+// exportStack converts stack frames to testresult.StackFrames.
+func exportStack(stack []stackFrame) []testresult.StackFrame {
+	var exported []testresult.StackFrame
+	for _, f := range stack {
+		exported = append(exported, testresult.StackFrame(f))
+	}
+	return exported
+}
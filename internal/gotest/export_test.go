@@ -0,0 +1,140 @@
+package gotest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/testresult"
+)
+
+type recordedResults struct {
+	results  []testresult.Result
+	finished bool
+}
+
+func (r *recordedResults) Accept(res testresult.Result) error {
+	r.results = append(r.results, res)
+	return nil
+}
+
+func (r *recordedResults) Finish() error {
+	r.finished = true
+	return nil
+}
+
+type recordedEvents []testresult.Event
+
+func (r *recordedEvents) Accept(e testresult.Event) error {
+	*r = append(*r, e)
+	return nil
+}
+
+func Test_exportedResults_subtests(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var recorded recordedResults
+	tested := &exportedResults{to: &recorded}
+	require.NoError(t, parseGoTestJSONOutput(f, tested, io.Discard))
+	require.True(t, recorded.finished)
+
+	var names []string
+	for _, res := range recorded.results {
+		names = append(names, res.Test+" "+res.Outcome)
+	}
+	require.Equal(t, []string{"TestTable fail", "TestFlat pass", " fail"}, names)
+	table := recorded.results[0]
+	require.Equal(t, "example.com/fx/subtests", table.Package)
+	require.Len(t, table.Subtests, 2)
+	require.Equal(t, "TestTable/b", table.Subtests[1].Test)
+	require.Equal(t, "TestTable/b/deep", table.Subtests[1].Subtests[0].Test)
+	require.Equal(t, testresult.Skip, table.Subtests[1].Subtests[0].Outcome)
+}
+
+func Test_exportedResults_quarantined(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	quarantined, err := compileQuarantine(flakyTable)
+	require.NoError(t, err)
+	var recorded recordedResults
+	tested := newQuarantine(&exportedResults{to: &recorded}, []quarantinedTest{quarantined})
+	require.NoError(t, parseGoTestJSONOutput(f, tested, io.Discard))
+
+	b := recorded.results[0].Subtests[1]
+	require.Equal(t, testresult.Quarantined, b.Outcome)
+	require.Equal(t, &testresult.Quarantine{
+		Package: "example.com/fx/...",
+		Test:    "TestTable/b",
+		Owner:   "@team",
+		Reason:  "flaky",
+		Expires: flakyTable.Expires,
+	}, b.Quarantine)
+	require.Equal(t, testresult.Pass, recorded.results[2].Outcome)
+}
+
+func Test_exportedResults_races(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "race.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var recorded recordedResults
+	require.NoError(t, parseGoTestJSONOutput(f, &exportedResults{to: &recorded}, io.Discard))
+
+	racy := recorded.results[0]
+	require.Equal(t, "TestRacyA", racy.Test)
+	require.NotEmpty(t, racy.Races)
+	race := racy.Races[0]
+	require.NotEmpty(t, race.Current.Op)
+	require.NotEmpty(t, race.Current.Stack)
+	require.NotEmpty(t, race.Current.Stack[0].File)
+	require.NotZero(t, race.Current.Stack[0].Line)
+}
+
+func Test_exportedResults_build(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var recorded recordedResults
+	require.NoError(t, parseGoTestJSONOutput(f, &exportedResults{to: &recorded}, io.Discard))
+
+	var builds []testresult.Result
+	for _, res := range recorded.results {
+		if res.ImportPath != "" {
+			builds = append(builds, res)
+		}
+	}
+	require.Equal(t, "example.com/fx/dep", builds[0].ImportPath)
+	require.Equal(t, testresult.BuildFail, builds[0].Outcome)
+	require.Equal(
+		t,
+		[]testresult.Diagnostic{{File: "fx/dep/dep.go", Line: 2, Col: 23, Message: "undefined: undefined"}},
+		builds[0].Diagnostics,
+	)
+}
+
+func Test_exportedEvents(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "attrs.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events recordedEvents
+	err = parseGoTestJSONOutput(f, newMultiResultAccepter(), io.Discard, &exportedEvents{to: &events})
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	var attrs []testresult.Event
+	for _, e := range events {
+		if e.Action == "attr" {
+			attrs = append(attrs, e)
+		}
+	}
+	require.NotEmpty(t, attrs)
+	require.NotEmpty(t, attrs[0].Key)
+}
@@ -0,0 +1,231 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"oss.indeed.com/go/go-opine/internal/github"
+)
+
+// testLogLocationRegexp matches the location prefix of a line logged by a
+// test (e.g. "    foo_test.go:42: message").
+var testLogLocationRegexp = regexp.MustCompile(`^(\s+)([\w.-]+\.go):(\d+): (.*)$`)
+
+// raceDetectedMessage is the error that the testing package logs for a
+// test during which a data race was detected. The data race itself is
+// annotated instead.
+const raceDetectedMessage = "race detected during execution of test"
+
+// githubAnnotations is a resultAccepter that writes GitHub Actions
+// annotations for failed tests, build failures, and data races. Build
+// warnings, which are only passed on with the BuildWarnings option, and
+// quarantined tests that failed are annotated as warnings.
+//
+// A failed test is annotated at each location it logged an error from
+// (e.g. "foo_test.go:42: ..."), or at the test function if it did not log
+// any (e.g. because it timed out). File paths are relative to root, which
+// should be the root of the repository.
+type githubAnnotations struct {
+	to         io.Writer
+	root       string
+	packageDir func(pkg string) (string, error)
+	races      map[string]bool
+}
+
+var _ resultAccepter = (*githubAnnotations)(nil)
+
+// newGitHubAnnotations returns a githubAnnotations that writes to the
+// provided io.Writer and finds the directory of each package with
+// packageDir.
+func newGitHubAnnotations(to io.Writer, root string, packageDir func(pkg string) (string, error)) *githubAnnotations {
+	return &githubAnnotations{
+		to:         to,
+		root:       root,
+		packageDir: packageDir,
+		races:      make(map[string]bool),
+	}
+}
+
+func (g *githubAnnotations) Accept(res result) error {
+	var annotations []github.Annotation
+	switch {
+	case res.Key.ImportPath != "":
+		annotations = g.buildAnnotations(res)
+	case res.Key.Test != "":
+		dir := ""
+		res.walk(func(res result) {
+			if isQuarantined(res) && !res.hasQuarantinedSubtest() {
+				annotations = append(annotations, g.quarantineAnnotation(res))
+			}
+			if res.Outcome != testFailure {
+				return
+			}
+			if dir == "" {
+				dir, _ = g.packageDir(res.Key.Package)
+			}
+			annotations = append(annotations, g.testAnnotations(res, dir)...)
+		})
+	}
+	res.walk(func(res result) {
+		annotations = append(annotations, g.raceAnnotations(res)...)
+	})
+	for _, a := range annotations {
+		if _, err := fmt.Fprintln(g.to, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// testAnnotations returns the annotations for a failed test. The test
+// files are in the directory dir. A test that failed only because a
+// subtest failed is not annotated since its subtests are.
+//
+// The locations are taken from the ErrorOutput so that lines logged with
+// t.Log are not annotated as errors. Older versions of Go do not
+// categorize output, in which case all the output of the test is used
+// unless go-opine determined the Reason for the failure (e.g. a panic).
+func (g *githubAnnotations) testAnnotations(res result, dir string) []github.Annotation {
+	output := res.ErrorOutput
+	if output == "" && res.Reason == "" {
+		output = res.Output
+	}
+	var (
+		annotations []github.Annotation
+		cur         *github.Annotation
+		indent      string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		if cur != nil && strings.HasPrefix(line, indent+" ") {
+			cur.Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		cur = nil
+		m := testLogLocationRegexp.FindStringSubmatch(line)
+		if m == nil || (m[4] == raceDetectedMessage && len(res.Races) > 0) {
+			continue
+		}
+		n, _ := strconv.Atoi(m[3])
+		file := m[2]
+		if dir != "" {
+			file = g.relPath(filepath.Join(dir, m[2]))
+		}
+		annotations = append(annotations, github.Annotation{
+			Level:   github.Error,
+			File:    file,
+			Line:    n,
+			Title:   resultName(res),
+			Message: m[4],
+		})
+		cur, indent = &annotations[len(annotations)-1], m[1]
+	}
+	if len(annotations) == 0 && !res.hasFailedSubtest() && len(res.Races) == 0 {
+		message := res.Reason
+		if message == "" {
+			message = "test failed"
+		}
+		a := github.Annotation{
+			Level:   github.Error,
+			Title:   resultName(res),
+			Message: message,
+		}
+		if res.File != "" {
+			a.File, a.Line = g.relPath(res.File), res.Line
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
+
+// quarantineAnnotation returns the warning annotation for a quarantined
+// test that failed, at its test function.
+func (g *githubAnnotations) quarantineAnnotation(res result) github.Annotation {
+	a := github.Annotation{
+		Level:   github.Warning,
+		Title:   resultName(res),
+		Message: "quarantined test failed (" + res.Quarantine.String() + ")",
+	}
+	if res.File != "" {
+		a.File, a.Line = g.relPath(res.File), res.Line
+	}
+	return a
+}
+
+// buildAnnotations returns the annotations for a failed build, or for the
+// warnings of a build that did not fail, at each of its diagnostics.
+func (g *githubAnnotations) buildAnnotations(res result) []github.Annotation {
+	level, title := github.Error, buildFailedMessage(res)
+	switch res.Outcome {
+	case buildFailure:
+	case buildWarnings:
+		level, title = github.Warning, "build warning"
+	default:
+		return nil
+	}
+	title += ": " + buildPackage(res.Key.ImportPath)
+	var annotations []github.Annotation
+	for _, d := range res.Diagnostics {
+		annotations = append(annotations, github.Annotation{
+			Level:   level,
+			File:    g.relPath(d.path()),
+			Line:    d.Line,
+			Col:     d.Col,
+			Title:   title,
+			Message: d.Message,
+		})
+	}
+	if len(annotations) == 0 {
+		annotations = append(annotations, github.Annotation{
+			Level:   level,
+			Title:   title,
+			Message: strings.TrimSpace(res.Output),
+		})
+	}
+	return annotations
+}
+
+// raceAnnotations returns the annotations for the data races of a result
+// that have not been annotated yet. A data race is annotated at the
+// innermost frame of the current access that is in the repository.
+func (g *githubAnnotations) raceAnnotations(res result) []github.Annotation {
+	var annotations []github.Annotation
+	for _, race := range res.Races {
+		sig := race.signature()
+		if g.races[sig] {
+			continue
+		}
+		g.races[sig] = true
+		a := github.Annotation{
+			Level: github.Error,
+			Title: "data race",
+			Message: fmt.Sprintf(
+				"%s by %s at %s\n%s by %s at %s\ndetected in %s",
+				race.Current.Op, race.Current.Goroutine, accessLocation(race.Current),
+				race.Previous.Op, race.Previous.Goroutine, accessLocation(race.Previous),
+				resultName(res),
+			),
+		}
+		for _, frame := range race.Current.Stack {
+			if rel := g.relPath(frame.File); !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel) {
+				a.File, a.Line = rel, frame.Line
+				break
+			}
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
+
+// relPath returns the path relative to the root, using forward slashes.
+// If that is not possible the path is returned unchanged.
+func (g *githubAnnotations) relPath(path string) string {
+	rel, err := filepath.Rel(g.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
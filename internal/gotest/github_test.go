@@ -0,0 +1,235 @@
+package gotest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGitHubAnnotations(to io.Writer) *githubAnnotations {
+	return newGitHubAnnotations(to, "/src/fx", func(pkg string) (string, error) {
+		return strings.Replace(pkg, "example.com/fx", "/src/fx", 1), nil
+	})
+}
+
+func Test_githubAnnotations_Accept(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestGitHubAnnotations(&out), io.Discard)
+	require.NoError(t, err)
+	// TestTable only failed because TestTable/b failed, and the output
+	// logged with t.Log is not annotated.
+	require.Equal(
+		t,
+		"::error file=subtests/subtests_test.go,line=10,title=example.com/fx/subtests.TestTable/b::bad\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_quarantined(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	quarantined, err := compileQuarantine(flakyTable)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newQuarantine(newTestGitHubAnnotations(&out), []quarantinedTest{quarantined}), io.Discard)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::warning title=example.com/fx/subtests.TestTable/b::quarantined test failed (owner @team, expires 2026-12-31: flaky)\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_race(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "race.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestGitHubAnnotations(&out), io.Discard)
+	require.NoError(t, err)
+	// The same race in both tests is only annotated once, and the "race
+	// detected" errors logged by the testing package are not annotated.
+	require.Equal(
+		t,
+		"::error file=race/race.go,line=7,title=data race::"+
+			"Read by goroutine 9 at example.com/fx/race.(*Counter).Inc /src/fx/race/race.go:7%0A"+
+			"Previous write by goroutine 8 at example.com/fx/race.(*Counter).Inc /src/fx/race/race.go:7%0A"+
+			"detected in example.com/fx/race.TestRacyA\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_timeout(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "timeout.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestGitHubAnnotations(&out), io.Discard)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::error title=example.com/fx/hang.TestHang/sub::timed out after 1s\n"+
+			"::error title=example.com/fx/hang.TestParallel::timed out after 1s\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_multilineError(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:         resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome:     "fail",
+		ErrorOutput: "    foo_test.go:5: first\n        second\n    foo_test.go:9: third\n",
+	})
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::error file=pkg/foo_test.go,line=5,title=example.com/fx/pkg.TestFoo::first%0Asecond\n"+
+			"::error file=pkg/foo_test.go,line=9,title=example.com/fx/pkg.TestFoo::third\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_uncategorizedOutput(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:     resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome: "fail",
+		Output:  "=== RUN   TestFoo\n    foo_test.go:5: oops\n--- FAIL: TestFoo (0.00s)\n",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "::error file=pkg/foo_test.go,line=5,title=example.com/fx/pkg.TestFoo::oops\n", out.String())
+}
+
+func Test_githubAnnotations_Accept_noLocation(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:     resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome: "fail",
+		Output:  "=== RUN   TestFoo\n--- FAIL: TestFoo (0.00s)\n",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "::error title=example.com/fx/pkg.TestFoo::test failed\n", out.String())
+}
+
+func Test_githubAnnotations_Accept_noLocationTestFunc(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:     resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome: "fail",
+		Reason:  "timed out after 1s",
+		File:    "/src/fx/pkg/foo_test.go",
+		Line:    7,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "::error file=pkg/foo_test.go,line=7,title=example.com/fx/pkg.TestFoo::timed out after 1s\n", out.String())
+}
+
+func Test_githubAnnotations_Accept_passed(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:     resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome: "pass",
+		Output:  "    foo_test.go:5: fine\n",
+	})
+	require.NoError(t, err)
+	require.Empty(t, out.String())
+}
+
+func Test_githubAnnotations_Accept_buildFail(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	const output = "# example.com/pkg [example.com/pkg.test]\n./foo.go:5:24: undefined: bar\n./foo_test.go:3:2: \"os\" imported and not used\n"
+	var out bytes.Buffer
+	tested := newGitHubAnnotations(&out, filepath.Dir(wd), unknownPackageDir)
+	err = tested.Accept(result{
+		Key:         resultKey{ImportPath: "example.com/pkg [example.com/pkg.test]"},
+		Outcome:     "build-fail",
+		Output:      output,
+		Diagnostics: parseBuildDiagnostics(output),
+	})
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::error file=gotest/foo.go,line=5,col=24,title=build failed%3A example.com/pkg::undefined: bar\n"+
+			"::error file=gotest/foo_test.go,line=3,col=2,title=build failed%3A example.com/pkg::\"os\" imported and not used\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_buildFailNoLocation(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:     resultKey{ImportPath: "example.com/pkg"},
+		Outcome: "build-fail",
+		Output:  "# example.com/pkg\nimport cycle not allowed\n",
+	})
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::error title=build failed%3A example.com/pkg::# example.com/pkg%0Aimport cycle not allowed\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_buildFailExternalTestPackage(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestGitHubAnnotations(&out)
+	err := tested.Accept(result{
+		Key:     resultKey{ImportPath: "example.com/pkg_test [example.com/pkg.test]"},
+		Outcome: "build-fail",
+		Output:  "# example.com/pkg_test [example.com/pkg.test]\nimport cycle not allowed\n",
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out.String(), "::error title=build failed%3A example.com/pkg::"), out.String())
+}
+
+func Test_githubAnnotations_Accept_buildFixture(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newGitHubAnnotations(&out, wd, unknownPackageDir), io.Discard)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"::error file=fx/dep/dep.go,line=2,col=23,title=build failed%3A example.com/fx/dep::undefined: undefined\n"+
+			"::error file=fx/vet/vet.go,line=3,col=24,title=vet failed%3A example.com/fx/vet::fmt.Printf format %25d has arg \"x\" of wrong type string\n"+
+			"::warning title=build warning%3A example.com/fx/warn::# example.com/fx/warn.test%0Ald: warning: something is odd\n",
+		out.String(),
+	)
+}
+
+func Test_githubAnnotations_Accept_error(t *testing.T) {
+	expectedErr := errors.New("blah")
+	tested := newTestGitHubAnnotations(&errorWriter{err: expectedErr})
+	err := tested.Accept(result{
+		Key:     resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome: "fail",
+	})
+	require.Equal(t, expectedErr, err)
+}
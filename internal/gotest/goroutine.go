@@ -0,0 +1,117 @@
+package gotest
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	goroutineHeaderRegexp = regexp.MustCompile(`^goroutine \d+ \[[^\]]*\]:$`)
+
+	// stackFrameFuncRegexp matches the function of a stack frame line
+	// (e.g. "testing.(*T).Run(0xc0001, {0x5, 0x6})"). The function is
+	// everything before the argument list, which means it may contain
+	// receiver types such as "(*T)".
+	stackFrameFuncRegexp = regexp.MustCompile(`^((?:[^(\s]|\(\*?[^()\s]*\))+)\(`)
+
+	// testFuncRegexp matches the name of a top level test, benchmark,
+	// fuzz test, or example function.
+	testFuncRegexp = regexp.MustCompile(`^(?:Test|Benchmark|Fuzz|Example)`)
+)
+
+// goroutine is a single goroutine from a goroutine dump, such as the one
+// printed by an unrecovered panic or by "go test -timeout".
+type goroutine struct {
+	// Trace is the full text of the goroutine, starting with the
+	// "goroutine N [status]:" header. It always ends with a newline.
+	Trace string
+	// Funcs are the fully qualified functions of each stack frame, from
+	// the innermost to the outermost.
+	Funcs []string
+}
+
+// parseGoroutines finds all goroutines in the provided goroutine dump.
+// Text that is not part of a goroutine (e.g. the panic message) is
+// ignored.
+func parseGoroutines(dump string) []goroutine {
+	var (
+		res   []goroutine
+		cur   *goroutine
+		trace strings.Builder
+	)
+	finish := func() {
+		if cur != nil {
+			cur.Trace = trace.String()
+			res = append(res, *cur)
+			cur = nil
+			trace.Reset()
+		}
+	}
+	for _, line := range strings.SplitAfter(dump, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		if goroutineHeaderRegexp.MatchString(trimmed) {
+			finish()
+			cur = &goroutine{}
+		}
+		if cur == nil {
+			continue
+		}
+		if trimmed == "" {
+			finish()
+			continue
+		}
+		trace.WriteString(trimmed + "\n")
+		if m := stackFrameFuncRegexp.FindStringSubmatch(trimmed); m != nil {
+			cur.Funcs = append(cur.Funcs, m[1])
+		}
+	}
+	finish()
+	return res
+}
+
+// testFunc returns the test function the goroutine is running, which is
+// the innermost stack frame in a Test, Benchmark, Fuzz, or Example
+// function. The returned closure is true if that frame is a function
+// literal inside the test function (e.g. a subtest or a goroutine the test
+// started) rather than the test function itself.
+//
+// If the goroutine is not running a test function ok will be false.
+func (g goroutine) testFunc() (name string, closure, ok bool) {
+	for _, fn := range g.Funcs {
+		// Strip the import path ("example.com/pkg.TestFoo.func1" becomes
+		// "pkg.TestFoo.func1") so that dots in the path do not matter.
+		if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+			fn = fn[i+1:]
+		}
+		parts := strings.Split(fn, ".")
+		if len(parts) < 2 || !testFuncRegexp.MatchString(parts[1]) {
+			continue
+		}
+		return parts[1], len(parts) > 2, true
+	}
+	return "", false, false
+}
+
+// goroutinesForTest returns the goroutines that appear to belong to the
+// named test. Goroutines cannot be mapped to subtests precisely, so this
+// is a heuristic:
+//
+//   - A top level test owns the goroutines running the test function
+//     itself. If hasSubtests is false it also owns the goroutines running
+//     function literals declared in the test function.
+//   - A subtest owns the goroutines running function literals declared in
+//     its top level test function.
+func goroutinesForTest(goroutines []goroutine, test string, hasSubtests bool) []goroutine {
+	top, _, isSubtest := strings.Cut(test, "/")
+	var res []goroutine
+	for _, g := range goroutines {
+		name, closure, ok := g.testFunc()
+		if !ok || name != top {
+			continue
+		}
+		if closure == isSubtest || (closure && !hasSubtests) {
+			res = append(res, g)
+		}
+	}
+	return res
+}
@@ -0,0 +1,74 @@
+package gotest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const goroutineDump = `panic: boom
+
+goroutine 9 [chan receive]:
+testing.(*T).Run(0x206ce76026c8, {0x554104?, 0x4ed993?}, 0x6d4a70)
+	/go/src/testing/testing.go:2266 +0x4f2
+example.com/fx/hang.TestHang(0x206ce76026c8?)
+	/src/fx/hang/hang_test.go:16 +0x26
+created by testing.(*T).Run in goroutine 1
+	/go/src/testing/testing.go:2258 +0x4d4
+
+goroutine 10 [sleep]:
+time.Sleep(0x34630b8a000)
+	/go/src/runtime/time.go:368 +0x165
+example.com/fx/hang.TestHang.func1(0x206ce7602908?)
+	/src/fx/hang/hang_test.go:17 +0x1d
+created by testing.(*T).Run in goroutine 9
+	/go/src/testing/testing.go:2258 +0x4d4
+
+goroutine 1 [chan receive]:
+main.main()
+	_testmain.go:50 +0x9b
+`
+
+func Test_parseGoroutines(t *testing.T) {
+	goroutines := parseGoroutines(goroutineDump)
+	require.Len(t, goroutines, 3)
+	require.Equal(
+		t,
+		[]string{"testing.(*T).Run", "example.com/fx/hang.TestHang"},
+		goroutines[0].Funcs,
+	)
+	require.Equal(
+		t,
+		"goroutine 1 [chan receive]:\nmain.main()\n\t_testmain.go:50 +0x9b\n",
+		goroutines[2].Trace,
+	)
+}
+
+func Test_parseGoroutines_noGoroutines(t *testing.T) {
+	require.Empty(t, parseGoroutines("panic: boom\n"))
+}
+
+func Test_goroutine_testFunc(t *testing.T) {
+	goroutines := parseGoroutines(goroutineDump)
+
+	name, closure, ok := goroutines[0].testFunc()
+	require.True(t, ok)
+	require.Equal(t, "TestHang", name)
+	require.False(t, closure)
+
+	name, closure, ok = goroutines[1].testFunc()
+	require.True(t, ok)
+	require.Equal(t, "TestHang", name)
+	require.True(t, closure)
+
+	_, _, ok = goroutines[2].testFunc()
+	require.False(t, ok)
+}
+
+func Test_goroutinesForTest(t *testing.T) {
+	goroutines := parseGoroutines(goroutineDump)
+	require.Equal(t, goroutines[:1], goroutinesForTest(goroutines, "TestHang", true))
+	require.Equal(t, goroutines[:2], goroutinesForTest(goroutines, "TestHang", false))
+	require.Equal(t, goroutines[1:2], goroutinesForTest(goroutines, "TestHang/sub", false))
+	require.Empty(t, goroutinesForTest(goroutines, "TestOther", false))
+}
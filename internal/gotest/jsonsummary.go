@@ -0,0 +1,130 @@
+package gotest
+
+import (
+	"strings"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// jsonSummary is a resultAccepter that adds each package to a
+// summary.Summary, with its tests and any build failure. When finished the
+// Go version and "go test" arguments are added too, if known.
+type jsonSummary struct {
+	summary  *summary.Summary
+	goTest   func() (version string, args []string)
+	packages summaryPackages
+}
+
+var (
+	_ resultAccepter = (*jsonSummary)(nil)
+	_ resultFinisher = (*jsonSummary)(nil)
+)
+
+// newJSONSummary returns a jsonSummary that adds to the provided summary.
+// The goTest function returns the version of Go and the arguments "go
+// test" was run with, which are empty if it was not run.
+func newJSONSummary(s *summary.Summary, goTest func() (string, []string)) *jsonSummary {
+	if s.Packages == nil {
+		s.Packages = []summary.Package{}
+	}
+	return &jsonSummary{summary: s, goTest: goTest, packages: newSummaryPackages()}
+}
+
+func (j *jsonSummary) Accept(res result) error {
+	if pkg, ok := j.packages.add(res); ok {
+		j.summary.Packages = append(j.summary.Packages, pkg)
+	}
+	return nil
+}
+
+// Finish adds the Go version and "go test" arguments to the summary.
+func (j *jsonSummary) Finish() error {
+	j.summary.GoVersion, j.summary.GoTestArgs = j.goTest()
+	return nil
+}
+
+// summaryPackages converts results to summary.Packages. The tests of a
+// package are collected until the package completes.
+type summaryPackages struct {
+	tests  []summary.Test
+	builds map[string]result
+}
+
+func newSummaryPackages() summaryPackages {
+	return summaryPackages{builds: make(map[string]result)}
+}
+
+// add adds the result. If it is a package result the package, with its
+// tests and any build failure, is returned with true.
+func (s *summaryPackages) add(res result) (summary.Package, bool) {
+	switch {
+	case res.Key.ImportPath != "":
+		if res.Outcome == buildFailure {
+			s.builds[res.Key.ImportPath] = res
+		}
+	case res.Key.Test != "":
+		s.addTest(res, "")
+	case res.Key.Package != "":
+		pkg := summary.Package{
+			ImportPath:     res.Key.Package,
+			Outcome:        res.Outcome,
+			ElapsedSeconds: res.Elapsed.Seconds(),
+			Cached:         strings.Contains(res.Output, "\t(cached)"),
+			Reason:         res.Reason,
+			FailedBuild:    res.FailedBuild,
+			Tests:          s.tests,
+		}
+		if pkg.Tests == nil {
+			pkg.Tests = []summary.Test{}
+		}
+		if build, ok := s.builds[res.FailedBuild]; ok {
+			for _, d := range build.Diagnostics {
+				pkg.BuildErrors = append(pkg.BuildErrors, summary.Diagnostic{
+					File:    d.File,
+					Line:    d.Line,
+					Column:  d.Col,
+					Message: d.Message,
+					Vet:     d.Vet,
+				})
+			}
+		}
+		s.tests = nil
+		return pkg, true
+	}
+	return summary.Package{}, false
+}
+
+// addTest adds the test result, a subtest of parent if it is not empty,
+// followed by its subtests.
+func (s *summaryPackages) addTest(res result, parent string) {
+	s.tests = append(s.tests, summaryTest(res, parent))
+	for _, subtest := range res.Subtests {
+		s.addTest(subtest, res.Key.Test)
+	}
+}
+
+// summaryTest converts a test result, without its subtests, to a
+// summary.Test that is a subtest of parent if it is not empty.
+func summaryTest(res result, parent string) summary.Test {
+	test := summary.Test{
+		Name:           res.Key.Test,
+		Parent:         parent,
+		Outcome:        res.Outcome,
+		ElapsedSeconds: res.Elapsed.Seconds(),
+		Reason:         res.Reason,
+		DataRace:       len(res.Races) > 0,
+		FailedItself:   res.failedItself(),
+		Line:           res.Line,
+	}
+	if res.File != "" {
+		test.File = relativePath(res.File)
+	}
+	for _, a := range res.Attrs {
+		test.Attrs = append(test.Attrs, summary.Attr{Key: a.Key, Value: a.Value})
+	}
+	if q := res.Quarantine; q != nil {
+		test.Quarantine = &summary.Quarantine{Owner: q.Owner, Reason: q.Reason, Expires: q.Expires.Format(time.DateOnly)}
+	}
+	return test
+}
@@ -0,0 +1,132 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+func noGoTest() (string, []string) {
+	return "", nil
+}
+
+func Test_jsonSummary_Accept(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var s summary.Summary
+	err = parseGoTestJSONOutput(f, newJSONSummary(&s, noGoTest), io.Discard)
+	require.NoError(t, err)
+
+	require.Len(t, s.Packages, 1)
+	pkg := s.Packages[0]
+	require.Equal(t, "example.com/fx/subtests", pkg.ImportPath)
+	require.Equal(t, "fail", pkg.Outcome)
+	require.False(t, pkg.Cached)
+	var tests []string
+	for _, test := range pkg.Tests {
+		tests = append(tests, fmt.Sprintf("%s (parent %q) %s, failed itself: %t", test.Name, test.Parent, test.Outcome, test.FailedItself))
+	}
+	// TestTable only failed because TestTable/b failed.
+	require.Equal(
+		t,
+		[]string{
+			`TestTable (parent "") fail, failed itself: false`,
+			`TestTable/a (parent "TestTable") pass, failed itself: false`,
+			`TestTable/a/deep (parent "TestTable/a") skip, failed itself: false`,
+			`TestTable/b (parent "TestTable") fail, failed itself: true`,
+			`TestTable/b/deep (parent "TestTable/b") skip, failed itself: false`,
+			`TestFlat (parent "") pass, failed itself: false`,
+		},
+		tests,
+	)
+}
+
+func Test_jsonSummary_Accept_attrs(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "attrs.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var s summary.Summary
+	err = parseGoTestJSONOutput(f, newJSONSummary(&s, noGoTest), io.Discard)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]summary.Attr{{Key: "ticket", Value: "GO-123"}, {Key: "owner", Value: "gophers"}},
+		s.Packages[0].Tests[0].Attrs,
+	)
+}
+
+func Test_jsonSummary_Accept_buildFail(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var s summary.Summary
+	err = parseGoTestJSONOutput(f, newJSONSummary(&s, noGoTest), io.Discard)
+	require.NoError(t, err)
+	require.Len(t, s.Packages, 4)
+	a := s.Packages[0]
+	require.Equal(t, "example.com/fx/a", a.ImportPath)
+	require.Equal(t, "example.com/fx/dep", a.FailedBuild)
+	require.Equal(t, []summary.Diagnostic{{File: "fx/dep/dep.go", Line: 2, Column: 23, Message: "undefined: undefined"}}, a.BuildErrors)
+	require.Equal(t, []summary.Test{}, a.Tests)
+	require.True(t, s.Packages[2].BuildErrors[0].Vet)
+	require.Empty(t, s.Packages[3].BuildErrors)
+}
+
+func Test_jsonSummary_Accept_testDetails(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	var s summary.Summary
+	tested := newJSONSummary(&s, func() (string, []string) { return "go1.25.1", []string{"test", "./..."} })
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/pkg", Test: "TestFoo"},
+		Outcome: testFailure,
+		Elapsed: 1500 * time.Millisecond,
+		Reason:  "timed out after 1s",
+		Races:   []dataRace{{}},
+		File:    filepath.Join(wd, "pkg", "foo_test.go"),
+		Line:    12,
+	}))
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/pkg"},
+		Outcome: testFailure,
+		Output:  "ok  \texample.com/pkg\t(cached)\n",
+		Elapsed: 2 * time.Second,
+	}))
+	require.NoError(t, tested.Finish())
+	require.Equal(
+		t,
+		summary.Summary{
+			GoVersion:  "go1.25.1",
+			GoTestArgs: []string{"test", "./..."},
+			Packages: []summary.Package{{
+				ImportPath:     "example.com/pkg",
+				Outcome:        testFailure,
+				ElapsedSeconds: 2,
+				Cached:         true,
+				Tests: []summary.Test{{
+					Name:           "TestFoo",
+					Outcome:        testFailure,
+					ElapsedSeconds: 1.5,
+					Reason:         "timed out after 1s",
+					DataRace:       true,
+					FailedItself:   true,
+					File:           "pkg/foo_test.go",
+					Line:           12,
+				}},
+			}},
+		},
+		s,
+	)
+}
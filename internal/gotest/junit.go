@@ -0,0 +1,203 @@
+package gotest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/junit"
+)
+
+// junitOutput is a resultAccepter that adds a JUnit testsuite to a report
+// for each package.
+//
+// Every test without subtests is a testcase. A test with subtests is
+// usually represented only by its subtests, which avoids reporting the
+// same failure for both a failed subtest and its parent. It is only a
+// testcase of its own if it failed without any of its subtests failing.
+//
+// The attributes (see testing.T.Attr) and artifact directory of a test
+// are its testcase properties.
+//
+// A package that failed because a build failed (possibly of another
+// package it depends on) has a failed "[build failed]" testcase, or
+// "[vet failed]" if "go vet" failed, at the first diagnostic. Any other
+// package that failed without a failed testcase (e.g. TestMain called
+// os.Exit, or the test binary crashed before the first test) has a failed
+// "[package failed]" testcase with the output of the package.
+type junitOutput struct {
+	report    *junit.Testsuites
+	testcases []junit.Testcase
+	builds    map[string]result
+}
+
+var _ resultAccepter = (*junitOutput)(nil)
+
+func newJUnitOutput(report *junit.Testsuites) *junitOutput {
+	return &junitOutput{report: report, builds: make(map[string]result)}
+}
+
+func (j *junitOutput) Accept(res result) error {
+	if res.Key.ImportPath != "" {
+		if res.Outcome == buildFailure {
+			j.builds[res.Key.ImportPath] = res
+		}
+		return nil
+	}
+	if res.Key.Test != "" {
+		j.addTestcases(res, nil)
+		return nil
+	}
+	if res.Key.Package == "" {
+		return nil
+	}
+	if build, ok := j.builds[res.FailedBuild]; ok {
+		j.testcases = append(j.testcases, junitBuildTestcase(res.Key.Package, build))
+	} else if res.Outcome == testFailure && !hasJUnitFailure(j.testcases) {
+		j.testcases = append(j.testcases, junitPackageTestcase(res))
+	}
+
+	suite := junit.Testsuite{
+		Name:      res.Key.Package,
+		Time:      junitSeconds(res.Elapsed),
+		Testcases: j.testcases,
+	}
+	for _, tc := range j.testcases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	j.report.Suites = append(j.report.Suites, suite)
+	j.testcases = nil
+	return nil
+}
+
+// addTestcases adds the testcases for a test result and its subtests.
+// Since a test with subtests may not have a testcase of its own, each
+// subtest inherits the attributes of its ancestors.
+func (j *junitOutput) addTestcases(res result, inherited []attr) {
+	attrs := append(inherited[:len(inherited):len(inherited)], res.Attrs...)
+	if len(res.Subtests) == 0 || res.failedItself() {
+		j.testcases = append(j.testcases, junitTestcase(res, attrs))
+	}
+	for _, subtest := range res.Subtests {
+		j.addTestcases(subtest, attrs)
+	}
+}
+
+// junitTestcase converts a test result with the provided attributes to
+// a JUnit testcase.
+func junitTestcase(res result, attrs []attr) junit.Testcase {
+	tc := junit.Testcase{
+		Classname: path.Base(res.Key.Package),
+		Name:      res.Key.Test,
+		Time:      junitSeconds(res.Elapsed),
+	}
+	if res.File != "" {
+		tc.File, tc.Line = relativePath(res.File), res.Line
+	}
+	if props := junitProperties(attrs, res.ArtifactDir); len(props) > 0 {
+		tc.Properties = &junit.Properties{Properties: props}
+	}
+	switch res.Outcome {
+	case testFailure:
+		message := res.Reason
+		if message == "" {
+			message = "Failed"
+		}
+		tc.Failure = &junit.Failure{
+			Message:  message,
+			Contents: res.nestedOutput(isFailed),
+		}
+		if len(res.Races) > 0 {
+			tc.Failure.Type = "race"
+			if res.Reason == "" {
+				tc.Failure.Message = "data race detected"
+			}
+		}
+	case "skip":
+		tc.Skipped = &junit.Skipped{
+			Message: strings.TrimSpace(removeFrames(res.Output)),
+		}
+	case testQuarantined:
+		// JUnit has no outcome for failures that do not fail the run, so
+		// quarantined tests are skipped with their output.
+		tc.Skipped = &junit.Skipped{
+			Message: "quarantined (" + res.Quarantine.String() + ")",
+		}
+		tc.SystemOut = res.nestedOutput(isFailedOrQuarantined)
+	}
+	return tc
+}
+
+// junitBuildTestcase returns the testcase for the failed build of the
+// package.
+func junitBuildTestcase(pkg string, build result) junit.Testcase {
+	message := buildFailedMessage(build)
+	tc := junit.Testcase{
+		Classname: path.Base(pkg),
+		Name:      "[" + message + "]",
+		Time:      junitSeconds(0),
+		Failure: &junit.Failure{
+			Message:  message,
+			Type:     strings.TrimSuffix(message, " failed"),
+			Contents: build.Output,
+		},
+	}
+	if len(build.Diagnostics) > 0 {
+		d := build.Diagnostics[0]
+		tc.File, tc.Line = relativePath(d.path()), d.Line
+	}
+	return tc
+}
+
+// junitPackageTestcase returns the testcase for a package that failed
+// without any of its tests failing.
+func junitPackageTestcase(res result) junit.Testcase {
+	message := res.Reason
+	if message == "" {
+		message = "package failed"
+	}
+	return junit.Testcase{
+		Classname: path.Base(res.Key.Package),
+		Name:      "[package failed]",
+		Time:      junitSeconds(res.Elapsed),
+		Failure: &junit.Failure{
+			Message:  message,
+			Contents: res.Output,
+		},
+	}
+}
+
+// hasJUnitFailure returns true if any of the testcases failed.
+func hasJUnitFailure(testcases []junit.Testcase) bool {
+	for _, tc := range testcases {
+		if tc.Failure != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// junitProperties returns the JUnit properties for the provided
+// attributes followed by the artifact directory (as "artifacts"), if any.
+func junitProperties(attrs []attr, artifactDir string) []junit.Property {
+	var props []junit.Property
+	for _, a := range attrs {
+		props = append(props, junit.Property{Name: a.Key, Value: a.Value})
+	}
+	if artifactDir != "" {
+		props = append(props, junit.Property{Name: "artifacts", Value: artifactDir})
+	}
+	return props
+}
+
+// junitSeconds formats a duration as seconds for a JUnit report.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
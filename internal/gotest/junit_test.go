@@ -0,0 +1,203 @@
+package gotest
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/junit"
+)
+
+func Test_junitOutput_Accept(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var report junit.Testsuites
+	err = parseGoTestJSONOutput(f, newJUnitOutput(&report), io.Discard)
+	require.NoError(t, err)
+
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	require.Equal(t, "example.com/fx/subtests", suite.Name)
+	require.Equal(t, 4, suite.Tests)
+	require.Equal(t, 1, suite.Failures)
+	require.Equal(t, 2, suite.Skipped)
+	require.Equal(t, "0.004", suite.Time)
+
+	// TestTable and TestTable/a are only represented by their subtests
+	// since TestTable failed because TestTable/b failed and TestTable/a
+	// passed. TestTable/b failed itself so it is a testcase too.
+	var names []string
+	for _, tc := range suite.Testcases {
+		names = append(names, tc.Name)
+	}
+	require.Equal(t, []string{"TestTable/a/deep", "TestTable/b", "TestTable/b/deep", "TestFlat"}, names)
+
+	failed := suite.Testcases[1]
+	require.Equal(t, "subtests", failed.Classname)
+	require.Equal(t, "Failed", failed.Failure.Message)
+	require.Contains(t, failed.Failure.Contents, "subtests_test.go:10: bad\n")
+	require.NotContains(t, failed.Failure.Contents, "not today")
+
+	skipped := suite.Testcases[0]
+	require.Equal(t, "subtests_test.go:12: not today", skipped.Skipped.Message)
+}
+
+func Test_junitOutput_Accept_parentFailedItself(t *testing.T) {
+	var report junit.Testsuites
+	tested := newJUnitOutput(&report)
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/pkg", Test: "TestFoo"},
+		Outcome: "fail",
+		Output:  "=== RUN   TestFoo\n--- FAIL: TestFoo (1.00s)\n",
+		Reason:  "timed out after 1s",
+		Subtests: []result{
+			{Key: resultKey{Package: "example.com/pkg", Test: "TestFoo/a"}, Outcome: "pass"},
+		},
+	}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "example.com/pkg"}, Outcome: "fail"}))
+	require.Len(t, report.Suites, 1)
+	require.Len(t, report.Suites[0].Testcases, 2)
+	require.Equal(t, "TestFoo", report.Suites[0].Testcases[0].Name)
+	require.Equal(t, "timed out after 1s", report.Suites[0].Testcases[0].Failure.Message)
+}
+
+func Test_junitOutput_Accept_location(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	var report junit.Testsuites
+	tested := newJUnitOutput(&report)
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/pkg", Test: "TestFoo"},
+		Outcome: "pass",
+		File:    filepath.Join(wd, "pkg", "foo_test.go"),
+		Line:    12,
+	}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "example.com/pkg", Test: "TestBar"}, Outcome: "pass"}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "example.com/pkg"}, Outcome: "pass"}))
+	require.Len(t, report.Suites, 1)
+	testcases := report.Suites[0].Testcases
+	require.Len(t, testcases, 2)
+	require.Equal(t, "pkg/foo_test.go", testcases[0].File)
+	require.Equal(t, 12, testcases[0].Line)
+	require.Empty(t, testcases[1].File)
+	require.Zero(t, testcases[1].Line)
+}
+
+func Test_junitOutput_Accept_ignoresBuildOutput(t *testing.T) {
+	var report junit.Testsuites
+	tested := newJUnitOutput(&report)
+	require.NoError(t, tested.Accept(result{Key: resultKey{ImportPath: "example.com/pkg"}, Outcome: "build-fail"}))
+	require.Empty(t, report.Suites)
+}
+
+func Test_junitOutput_Accept_buildFail(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var report junit.Testsuites
+	err = parseGoTestJSONOutput(f, newJUnitOutput(&report), io.Discard)
+	require.NoError(t, err)
+
+	var suites []string
+	for _, suite := range report.Suites {
+		suites = append(suites, suite.Name)
+	}
+	require.Equal(t, []string{"example.com/fx/a", "example.com/fx/dep", "example.com/fx/vet", "example.com/fx/warn"}, suites)
+
+	// The build failure of a dependency fails the package too.
+	for _, suite := range report.Suites[:2] {
+		require.Equal(t, 1, suite.Tests)
+		require.Equal(t, 1, suite.Failures)
+		require.Equal(
+			t,
+			junit.Testcase{
+				Classname: path.Base(suite.Name),
+				Name:      "[build failed]",
+				Time:      "0.000",
+				File:      "fx/dep/dep.go",
+				Line:      2,
+				Failure: &junit.Failure{
+					Message:  "build failed",
+					Type:     "build",
+					Contents: "# example.com/fx/dep\nfx/dep/dep.go:2:23: undefined: undefined\n",
+				},
+			},
+			suite.Testcases[0],
+		)
+	}
+
+	vet := report.Suites[2].Testcases[0]
+	require.Equal(t, "[vet failed]", vet.Name)
+	require.Equal(t, "vet", vet.Failure.Type)
+	require.Equal(t, "fx/vet/vet.go", vet.File)
+
+	require.Equal(t, 0, report.Suites[3].Failures)
+}
+
+func Test_junitOutput_Accept_packageFail(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "testmain-exit.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var report junit.Testsuites
+	err = parseGoTestJSONOutput(f, newJUnitOutput(&report), io.Discard)
+	require.NoError(t, err)
+
+	// TestMain called os.Exit(1) before running any test.
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	require.Equal(t, 1, suite.Tests)
+	require.Equal(t, 1, suite.Failures)
+	require.Equal(
+		t,
+		junit.Testcase{
+			Classname: "testmain",
+			Name:      "[package failed]",
+			Time:      "0.002",
+			Failure: &junit.Failure{
+				Message:  "package failed",
+				Contents: "setup failed: no database\nFAIL\texample.com/fx/testmain\t0.002s\n",
+			},
+		},
+		suite.Testcases[0],
+	)
+}
+
+func Test_junitOutput_Accept_properties(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "attrs.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var report junit.Testsuites
+	err = parseGoTestJSONOutput(f, newJUnitOutput(&report), io.Discard)
+	require.NoError(t, err)
+
+	require.Len(t, report.Suites, 1)
+	testcases := report.Suites[0].Testcases
+	require.Len(t, testcases, 2)
+	// TestTagged/sub inherits the attributes of TestTagged, which is not a
+	// testcase of its own.
+	require.Equal(t, "TestTagged/sub", testcases[0].Name)
+	require.Equal(
+		t,
+		&junit.Properties{Properties: []junit.Property{
+			{Name: "ticket", Value: "GO-123"},
+			{Name: "owner", Value: "gophers"},
+			{Name: "link", Value: "https://example.com/x"},
+		}},
+		testcases[0].Properties,
+	)
+	require.Equal(t, "TestArtifacts", testcases[1].Name)
+	require.Equal(
+		t,
+		&junit.Properties{Properties: []junit.Property{{Name: "artifacts", Value: "/src/fx/_artifacts/attrs/TestArtifacts/3065154586"}}},
+		testcases[1].Properties,
+	)
+}
@@ -0,0 +1,146 @@
+package gotest
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// testFuncPrefixes are the prefixes of the names of the functions that
+// "go test" runs.
+var testFuncPrefixes = []string{"Test", "Benchmark", "Fuzz", "Example"}
+
+// testLocator is a resultAccepter that sets the File and Line of test
+// results to the declaration of the test function before forwarding them
+// to the next result accepter. The declarations are found by parsing the
+// test files of each package, so nothing is set if the package cannot be
+// found (e.g. when reporting results from another machine).
+type testLocator struct {
+	next       resultAccepter
+	packageDir func(pkg string) (string, error)
+	funcs      map[string]map[string]token.Position
+}
+
+var (
+	_ resultAccepter = (*testLocator)(nil)
+	_ resultFinisher = (*testLocator)(nil)
+)
+
+// newTestLocator returns a testLocator that finds the directory of each
+// package with packageDir.
+func newTestLocator(next resultAccepter, packageDir func(pkg string) (string, error)) *testLocator {
+	return &testLocator{
+		next:       next,
+		packageDir: packageDir,
+		funcs:      make(map[string]map[string]token.Position),
+	}
+}
+
+func (l *testLocator) Accept(res result) error {
+	switch {
+	case res.Key.Test != "":
+		l.locate(&res, l.packageFuncs(res.Key.Package))
+	case res.Key.Package != "":
+		delete(l.funcs, res.Key.Package)
+	}
+	return l.next.Accept(res)
+}
+
+// Finish finishes the next result accepter.
+func (l *testLocator) Finish() error {
+	return finish(l.next)
+}
+
+// locate sets the location of the test result and its subtests. A subtest
+// is located at the function of its top level test.
+func (l *testLocator) locate(res *result, funcs map[string]token.Position) {
+	name, _, _ := strings.Cut(res.Key.Test, "/")
+	if pos, ok := funcs[name]; ok {
+		res.File, res.Line = pos.Filename, pos.Line
+	}
+	for i := range res.Subtests {
+		l.locate(&res.Subtests[i], funcs)
+	}
+}
+
+// packageFuncs returns the positions of the test functions of the package
+// by name. It is empty if the package cannot be found.
+func (l *testLocator) packageFuncs(pkg string) map[string]token.Position {
+	if funcs, ok := l.funcs[pkg]; ok {
+		return funcs
+	}
+	var funcs map[string]token.Position
+	if dir, err := l.packageDir(pkg); err == nil && dir != "" {
+		funcs = findTestFuncs(dir)
+	}
+	l.funcs[pkg] = funcs
+	return funcs
+}
+
+// findTestFuncs returns the positions of the test, benchmark, fuzz, and
+// example functions declared in the test files in the directory, by name.
+// Files that cannot be parsed are skipped.
+func findTestFuncs(dir string) map[string]token.Position {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	fset := token.NewFileSet()
+	funcs := make(map[string]token.Position)
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !isTestFunc(fn.Name.Name) {
+				continue
+			}
+			if _, ok := funcs[fn.Name.Name]; !ok {
+				funcs[fn.Name.Name] = fset.Position(fn.Pos())
+			}
+		}
+	}
+	return funcs
+}
+
+// isTestFunc returns true iff the function name is one "go test" runs.
+// Like "go test" this requires that the prefix is not followed by a lower
+// case letter, so "Testify" is not a test.
+func isTestFunc(name string) bool {
+	for _, prefix := range testFuncPrefixes {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if r, _ := utf8.DecodeRuneInString(rest); ok && (rest == "" || !unicode.IsLower(r)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceLocation returns the "file:line" location of the test result, with
+// the file relative to the working directory if possible. It is empty if
+// the location is not known.
+func sourceLocation(res result) string {
+	if res.File == "" {
+		return ""
+	}
+	return relativePath(res.File) + ":" + strconv.Itoa(res.Line)
+}
+
+// relativePath returns the path relative to the working directory, using
+// forward slashes. If that is not possible the path is returned unchanged.
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,124 @@
+package gotest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const locationTestSource = `package pkg
+
+import "testing"
+
+func TestFoo(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {})
+}
+
+func Testify() {}
+
+func BenchmarkFoo(b *testing.B) {}
+
+func (s suite) TestMethod(t *testing.T) {}
+
+func Example() {}
+`
+
+// unknownPackageDir is a packageDir for tests that never find a package.
+func unknownPackageDir(string) (string, error) {
+	return "", errors.New("no such package")
+}
+
+func writeLocationTestPackage(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo_test.go"), []byte(locationTestSource), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken_test.go"), []byte("package pkg\nfunc TestBroken("), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.go"), []byte("package pkg\nfunc TestNotATestFile() {}\n"), 0666))
+	return dir
+}
+
+func Test_findTestFuncs(t *testing.T) {
+	dir := writeLocationTestPackage(t)
+	funcs := findTestFuncs(dir)
+	lines := make(map[string]int)
+	for name, pos := range funcs {
+		require.Equal(t, filepath.Join(dir, "foo_test.go"), pos.Filename)
+		lines[name] = pos.Line
+	}
+	require.Equal(t, map[string]int{"TestFoo": 5, "BenchmarkFoo": 11, "Example": 15}, lines)
+}
+
+func Test_isTestFunc(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Test":            true,
+		"TestFoo":         true,
+		"Test_foo":        true,
+		"TestÉcole":       true,
+		"Testify":         false,
+		"Testéxample":     false,
+		"BenchmarkFoo":    true,
+		"FuzzFoo":         true,
+		"ExampleFoo_bar":  true,
+		"Examples":        false,
+		"helperTestFoo":   false,
+		"NotTestFoo":      false,
+		"TestingHelperFn": false,
+	} {
+		require.Equal(t, want, isTestFunc(name), name)
+	}
+}
+
+func Test_testLocator_Accept(t *testing.T) {
+	dir := writeLocationTestPackage(t)
+	var results []result
+	lookups := 0
+	tested := newTestLocator(resultAccepterFunc(func(res result) error {
+		results = append(results, res)
+		return nil
+	}), func(pkg string) (string, error) {
+		lookups++
+		require.Equal(t, "example.com/pkg", pkg)
+		return dir, nil
+	})
+
+	err := tested.Accept(result{
+		Key:      resultKey{Package: "example.com/pkg", Test: "TestFoo"},
+		Subtests: []result{{Key: resultKey{Package: "example.com/pkg", Test: "TestFoo/sub"}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "example.com/pkg", Test: "TestMissing"}}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "example.com/pkg"}}))
+	require.Equal(t, 1, lookups)
+
+	file := filepath.Join(dir, "foo_test.go")
+	require.Len(t, results, 3)
+	require.Equal(t, file, results[0].File)
+	require.Equal(t, 5, results[0].Line)
+	require.Equal(t, file, results[0].Subtests[0].File)
+	require.Equal(t, 5, results[0].Subtests[0].Line)
+	require.Empty(t, results[1].File)
+	require.Zero(t, results[1].Line)
+	require.Empty(t, tested.funcs)
+}
+
+func Test_testLocator_Accept_unknownPackage(t *testing.T) {
+	var got result
+	tested := newTestLocator(resultAccepterFunc(func(res result) error {
+		got = res
+		return nil
+	}), unknownPackageDir)
+	err := tested.Accept(result{Key: resultKey{Package: "example.com/pkg", Test: "TestFoo"}})
+	require.NoError(t, err)
+	require.Equal(t, resultKey{Package: "example.com/pkg", Test: "TestFoo"}, got.Key)
+	require.Empty(t, got.File)
+}
+
+func Test_sourceLocation(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.Equal(t, "", sourceLocation(result{}))
+	require.Equal(t, "fx/foo_test.go:12", sourceLocation(result{File: filepath.Join(wd, "fx", "foo_test.go"), Line: 12}))
+	require.Equal(t, "/elsewhere/foo_test.go:3", sourceLocation(result{File: "/elsewhere/foo_test.go", Line: 3}))
+}
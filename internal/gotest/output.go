@@ -2,12 +2,23 @@ package gotest
 
 import (
 	"io"
+	"path/filepath"
 	"regexp"
+	"strings"
+
+	"oss.indeed.com/go/go-opine/internal/printing"
 )
 
 var (
 	removeCoverageOutputRegexp = regexp.MustCompile(`(?m)(?:^|\n|\t)coverage:[^\t\n]*`)
 	removePassOutputRegexp     = regexp.MustCompile(`(?m)(?:\nPASS$|^PASS\n)`)
+
+	// failLineRegexp matches a (possibly indented) "--- FAIL" line.
+	failLineRegexp = regexp.MustCompile(`^(\s*)(--- FAIL: .*)$`)
+
+	// fileLocationRegexp matches a Go source file location such as
+	// "foo_test.go:42", "pkg/foo.go:42:7", or "/src/pkg/foo.go:42".
+	fileLocationRegexp = regexp.MustCompile(`(?:[\w./-]*/)?[\w.-]+\.go:\d+(?::\d+)?`)
 )
 
 const testFailure = "fail"
@@ -30,7 +41,8 @@ func (r *removeCoverageOutput) Accept(res result) error {
 }
 
 // verboseOutput is a resultAccepter that writes "go test -v"-like
-// output to an io.Writer.
+// output to an io.Writer. The output of subtests is nested in the output
+// of their parent.
 type verboseOutput struct {
 	to io.Writer
 }
@@ -42,41 +54,142 @@ func newVerboseOutput(to io.Writer) *verboseOutput {
 }
 
 func (v *verboseOutput) Accept(res result) error {
-	_, err := v.to.Write([]byte(res.Output))
+	_, err := v.to.Write([]byte(res.nestedOutput(func(result) bool { return true })))
 	return err
 }
 
 // quietOutput is a resultAccepter that writes "go test"-like (no "-v")
-// output to an io.Writer.
+// output to an io.Writer. A failed test is printed once, with the output
+// of its failed subtests nested in it.
+//
+// The output is styled with the palette: failures are red, quarantines
+// are yellow, passing packages are green, and file locations are
+// hyperlinks.
 type quietOutput struct {
-	to io.Writer
+	to         io.Writer
+	palette    printing.Palette
+	packageDir func(pkg string) (string, error)
 }
 
 var _ resultAccepter = (*quietOutput)(nil)
 
 func newQuietOutput(to io.Writer) *quietOutput {
-	return &quietOutput{to: to}
+	return newColoredQuietOutput(to, printing.Palette{}, nil)
+}
+
+// newColoredQuietOutput returns a quietOutput styled with the palette. The
+// packageDir finds the directory of each package, which is only needed if
+// the palette enables hyperlinks.
+func newColoredQuietOutput(to io.Writer, palette printing.Palette, packageDir func(pkg string) (string, error)) *quietOutput {
+	return &quietOutput{
+		to:         to,
+		palette:    palette,
+		packageDir: packageDir,
+	}
 }
 
-func (q quietOutput) Accept(res result) error {
-	// Print output from failed tests.
-	if res.Key.Test != "" && res.Outcome == testFailure {
-		_, err := q.to.Write([]byte(res.Output))
-		return err
+func (q *quietOutput) Accept(res result) error {
+	// Print output from failed tests, followed by the quarantine of each
+	// quarantined test.
+	if res.Key.Test != "" && isFailedOrQuarantined(res) {
+		output := res.nestedOutput(isFailedOrQuarantined)
+		res.walk(func(res result) {
+			if isQuarantined(res) && !res.hasQuarantinedSubtest() {
+				output += quarantineLine(res)
+			}
+		})
+		return q.write(res.Key.Package, output)
 	}
 	// Print output from build output
 	if res.Key.ImportPath != "" {
-		_, err := q.to.Write([]byte(res.Output))
-		return err
+		return q.write("", res.Output)
 	}
 
 	// Remove "PASS" lines from the non-test (i.e. package) output. There
 	// is already a line starting with "?", "ok", or "fail" that indicates the
 	// package result.
 	if res.Key.Test == "" {
-		_, err := q.to.Write([]byte(removePassOutputRegexp.ReplaceAllString(res.Output, "")))
-		return err
+		return q.write(res.Key.Package, removePassOutputRegexp.ReplaceAllString(res.Output, ""))
 	}
 
 	return nil
 }
+
+// write writes the output of the package (or of no particular package if
+// pkg is empty), styled with the palette.
+func (q *quietOutput) write(pkg, output string) error {
+	if q.palette != (printing.Palette{}) {
+		dir := ""
+		if fileLocationRegexp.MatchString(output) {
+			dir = q.dir(pkg)
+		}
+		output = styleOutput(output, q.palette, dir)
+	}
+	_, err := io.WriteString(q.to, output)
+	return err
+}
+
+// dir returns the directory of the package, which is needed to link to
+// files in test output, so it is only looked up for output with file
+// locations. It is empty if hyperlinks are disabled or the directory
+// cannot be found.
+func (q *quietOutput) dir(pkg string) string {
+	if !q.palette.Hyperlinks || pkg == "" {
+		return ""
+	}
+	dir, _ := q.packageDir(pkg)
+	return dir
+}
+
+// styleOutput styles "go test" output with the palette. File locations
+// that are not absolute are relative to the package directory dir if they
+// are just a file name (as in test output), and otherwise relative to the
+// working directory (as in build output).
+func styleOutput(output string, palette printing.Palette, dir string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(output, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		if palette.Hyperlinks {
+			text = fileLocationRegexp.ReplaceAllStringFunc(text, func(loc string) string {
+				return palette.FileLink(locationPath(loc, dir), loc)
+			})
+		}
+		switch {
+		case failLineRegexp.MatchString(text):
+			m := failLineRegexp.FindStringSubmatch(text)
+			text = m[1] + palette.BoldRed(m[2])
+		case text == "FAIL" || strings.HasPrefix(text, "FAIL\t") || strings.HasPrefix(text, "panic: "):
+			text = palette.Red(text)
+		case strings.HasPrefix(text, "--- QUARANTINED: "):
+			text = palette.Yellow(text)
+		case strings.HasPrefix(text, "ok  \t"):
+			text = palette.Green("ok") + strings.TrimPrefix(text, "ok")
+		}
+		sb.WriteString(text)
+		if strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// locationPath returns the absolute path of the file of a file location,
+// or an empty string if it cannot be determined.
+func locationPath(loc, dir string) string {
+	file := loc[:strings.Index(loc, ".go:")+len(".go")]
+	switch {
+	case filepath.IsAbs(file):
+		return file
+	case !strings.Contains(file, "/"):
+		if dir == "" {
+			return ""
+		}
+		return filepath.Join(dir, file)
+	default:
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return ""
+		}
+		return abs
+	}
+}
@@ -3,9 +3,12 @@ package gotest
 import (
 	"bytes"
 	"errors"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/printing"
 )
 
 func Test_removeCoverageOutput_Accept(t *testing.T) {
@@ -131,6 +134,86 @@ FAIL	oss.indeed.com/go/go-opine/internal/cmd	11.527s
 	require.Equal(t, expectedOutput, output.String())
 }
 
+func Test_quietOutput_Accept_colored(t *testing.T) {
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	link := func(path, text string) string {
+		return "\x1b]8;;file://" + host + path + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+	}
+
+	var (
+		output bytes.Buffer
+		listed []string
+	)
+	tested := newColoredQuietOutput(&output, printing.Palette{Color: true, Hyperlinks: true}, func(pkg string) (string, error) {
+		listed = append(listed, pkg)
+		return "/src/pkg", nil
+	})
+	for _, res := range []result{
+		{
+			Key:     resultKey{Package: "example.com/pkg", Test: "TestFoo"},
+			Outcome: "fail",
+			Output:  "=== RUN   TestFoo\n    foo_test.go:42: bad\n--- FAIL: TestFoo (0.00s)\n",
+		},
+		{
+			Key:     resultKey{Package: "example.com/pkg", Test: "TestBar"},
+			Outcome: "fail",
+			Output:  "=== RUN   TestBar\n    --- FAIL: TestBar/sub (0.00s)\n",
+		},
+		{
+			Key:     resultKey{Package: "example.com/pkg"},
+			Outcome: "fail",
+			Output:  "FAIL\nFAIL\texample.com/pkg\t0.1s\n",
+		},
+		{
+			Key:     resultKey{Package: "example.com/other"},
+			Outcome: "pass",
+			Output:  "PASS\nok  \texample.com/other\t0.1s\n",
+		},
+	} {
+		require.NoError(t, tested.Accept(res))
+	}
+	require.Equal(
+		t,
+		"=== RUN   TestFoo\n"+
+			"    "+link("/src/pkg/foo_test.go", "foo_test.go:42")+": bad\n"+
+			"\x1b[1;31m--- FAIL: TestFoo (0.00s)\x1b[0m\n"+
+			"=== RUN   TestBar\n"+
+			"    \x1b[1;31m--- FAIL: TestBar/sub (0.00s)\x1b[0m\n"+
+			"\x1b[31mFAIL\x1b[0m\n"+
+			"\x1b[31mFAIL\texample.com/pkg\t0.1s\x1b[0m\n"+
+			"\x1b[32mok\x1b[0m  \texample.com/other\t0.1s\n",
+		output.String(),
+	)
+	// The directory of a package is only looked up if its output has file
+	// locations.
+	require.Equal(t, []string{"example.com/pkg"}, listed)
+}
+
+func Test_styleOutput_fileLocations(t *testing.T) {
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	link := func(path, text string) string {
+		return "\x1b]8;;file://" + host + path + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+	}
+	palette := printing.Palette{Color: true, Hyperlinks: true}
+
+	require.Equal(
+		t,
+		"      "+link("/src/pkg/foo.go", "/src/pkg/foo.go:7")+" +0x36\n",
+		styleOutput("      /src/pkg/foo.go:7 +0x36\n", palette, ""),
+	)
+	require.Equal(
+		t,
+		link(wd+"/v/v.go", "v/v.go:5:24")+": fmt.Printf format %d has arg x of wrong type string",
+		styleOutput("v/v.go:5:24: fmt.Printf format %d has arg x of wrong type string", palette, ""),
+	)
+	require.Equal(t, "    foo_test.go:42: bad\n", styleOutput("    foo_test.go:42: bad\n", palette, ""))
+	require.Equal(t, "    foo_test.go:42: bad\n", styleOutput("    foo_test.go:42: bad\n", printing.Palette{Color: true}, "/src/pkg"))
+}
+
 func Test_quietOutput_Accept_error(t *testing.T) {
 	expectedErr := errors.New("failed")
 	tested := newQuietOutput(&errorWriter{err: expectedErr})
@@ -0,0 +1,51 @@
+package gotest
+
+import (
+	"io"
+
+	"oss.indeed.com/go/go-opine/internal/plugin"
+)
+
+// reporterStream is a resultAccepter that writes a plugin.Message for
+// each test and package as it completes.
+type reporterStream struct {
+	to       io.Writer
+	packages summaryPackages
+}
+
+var _ resultAccepter = (*reporterStream)(nil)
+
+func newReporterStream(to io.Writer) *reporterStream {
+	return &reporterStream{to: to, packages: newSummaryPackages()}
+}
+
+func (r *reporterStream) Accept(res result) error {
+	if res.Key.Test != "" {
+		if err := r.writeTest(res, ""); err != nil {
+			return err
+		}
+	}
+	if pkg, ok := r.packages.add(res); ok {
+		return plugin.Write(r.to, plugin.Message{Type: plugin.PackageMessage, Package: &pkg})
+	}
+	return nil
+}
+
+// writeTest writes a message for the test result, a subtest of parent if
+// it is not empty, followed by messages for its subtests.
+func (r *reporterStream) writeTest(res result, parent string) error {
+	test := summaryTest(res, parent)
+	m := plugin.Message{Type: plugin.TestMessage, ImportPath: res.Key.Package, Test: &test}
+	if res.Outcome == testFailure {
+		m.Output = res.Output
+	}
+	if err := plugin.Write(r.to, m); err != nil {
+		return err
+	}
+	for _, subtest := range res.Subtests {
+		if err := r.writeTest(subtest, res.Key.Test); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package gotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/plugin"
+)
+
+func Test_reporterStream_Accept(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, parseGoTestJSONOutput(f, newReporterStream(&out), io.Discard))
+
+	var messages []string
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var m plugin.Message
+		require.NoError(t, dec.Decode(&m))
+		switch m.Type {
+		case plugin.TestMessage:
+			require.Equal(t, "example.com/fx/subtests", m.ImportPath)
+			if m.Test.Outcome == testFailure {
+				require.NotEmpty(t, m.Output)
+			} else {
+				require.Empty(t, m.Output)
+			}
+			messages = append(messages, "test "+m.Test.Name+" "+m.Test.Outcome+" "+m.Test.Parent)
+		case plugin.PackageMessage:
+			require.Len(t, m.Package.Tests, 6)
+			messages = append(messages, "package "+m.Package.ImportPath+" "+m.Package.Outcome)
+		}
+	}
+	require.Equal(
+		t,
+		[]string{
+			"test TestTable fail ",
+			"test TestTable/a pass TestTable",
+			"test TestTable/a/deep skip TestTable/a",
+			"test TestTable/b fail TestTable",
+			"test TestTable/b/deep skip TestTable/b",
+			"test TestFlat pass ",
+			"package example.com/fx/subtests fail",
+		},
+		messages,
+	)
+}
@@ -0,0 +1,192 @@
+package gotest
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxProgressWidth is the maximum width of the progress status line. A
+// status line that wraps cannot be erased, so it is kept short.
+const maxProgressWidth = 100
+
+// statusSetter displays a status line.
+type statusSetter interface {
+	SetStatus(status string) error
+}
+
+// progress is an eventAccepter that keeps a status line up to date with
+// the progress of "go test": the packages completed, the tests passed,
+// failed, and skipped, the elapsed time, and the tests that have been
+// running for longer than a threshold. Paused tests (see testing.T.Parallel)
+// are not considered to be running.
+//
+// The status line is updated with each event and also periodically
+// between start and stop, so that the elapsed time keeps moving when
+// "go test" is quiet.
+type progress struct {
+	to   statusSetter
+	slow time.Duration
+	now  func() time.Time
+
+	mu       sync.Mutex
+	started  time.Time
+	total    int
+	done     int
+	passed   int
+	failed   int
+	skipped  int
+	running  map[resultKey]time.Time
+	stopping chan struct{}
+	stopped  chan struct{}
+}
+
+var _ eventAccepter = (*progress)(nil)
+
+func newProgress(to statusSetter, slow time.Duration) *progress {
+	return &progress{
+		to:      to,
+		slow:    slow,
+		now:     time.Now,
+		running: make(map[resultKey]time.Time),
+	}
+}
+
+// start starts updating the status line every interval. The total is the
+// number of packages that will be tested, or 0 if it is not known.
+func (p *progress) start(total int, interval time.Duration) {
+	p.mu.Lock()
+	p.started = p.now()
+	p.total = total
+	p.stopping = make(chan struct{})
+	p.stopped = make(chan struct{})
+	p.mu.Unlock()
+
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = p.update()
+			case <-p.stopping:
+				return
+			}
+		}
+	}()
+}
+
+// stop stops updating the status line and erases it.
+func (p *progress) stop() error {
+	close(p.stopping)
+	<-p.stopped
+	return p.to.SetStatus("")
+}
+
+func (p *progress) Accept(e event) error {
+	p.mu.Lock()
+	rk := resultKey{Package: e.Package, Test: e.Test}
+	switch {
+	case e.Test == "" && e.Package != "" && isTestOrPackageComplete(e.Action):
+		p.done++
+		for running := range p.running {
+			if running.Package == e.Package {
+				delete(p.running, running)
+			}
+		}
+	case e.Test == "":
+	case e.Action == "run" || e.Action == "cont":
+		p.running[rk] = p.now()
+	case e.Action == "pause":
+		delete(p.running, rk)
+	case e.Action == "pass":
+		p.passed++
+		delete(p.running, rk)
+	case e.Action == testFailure:
+		p.failed++
+		delete(p.running, rk)
+	case e.Action == "skip":
+		p.skipped++
+		delete(p.running, rk)
+	default:
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+	return p.update()
+}
+
+// update sets the status line.
+func (p *progress) update() error {
+	return p.to.SetStatus(p.status())
+}
+
+// status returns the status line, e.g.
+// "3/10 packages, 42 passed, 1 failed, 2 skipped, 1m5s; slow: pkg.TestFoo (35s)".
+func (p *progress) status() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var sb strings.Builder
+	if p.total > 0 {
+		fmt.Fprintf(&sb, "%d/%d packages", p.done, p.total)
+	} else {
+		fmt.Fprintf(&sb, "%d packages", p.done)
+	}
+	fmt.Fprintf(&sb, ", %d passed, %d failed, %d skipped, %s", p.passed, p.failed, p.skipped, now.Sub(p.started).Round(time.Second))
+
+	slow := p.slowTests(now)
+	for i, rk := range slow {
+		entry := fmt.Sprintf("%s.%s (%s)", path.Base(rk.Package), rk.Test, now.Sub(p.running[rk]).Round(time.Second))
+		if i == 0 {
+			sb.WriteString("; slow: " + entry)
+			continue
+		}
+		if sb.Len()+len(", ")+len(entry) > maxProgressWidth {
+			fmt.Fprintf(&sb, ", +%d more", len(slow)-i)
+			break
+		}
+		sb.WriteString(", " + entry)
+	}
+
+	status := sb.String()
+	if len(status) > maxProgressWidth {
+		status = status[:maxProgressWidth-3] + "..."
+	}
+	return status
+}
+
+// slowTests returns the tests that have been running for at least the
+// slow threshold, from the longest running to the shortest. A test with
+// running subtests is not included since the subtests are more specific.
+func (p *progress) slowTests(now time.Time) []resultKey {
+	var slow []resultKey
+	for rk, since := range p.running {
+		if now.Sub(since) >= p.slow && !p.hasRunningSubtest(rk) {
+			slow = append(slow, rk)
+		}
+	}
+	sort.Slice(slow, func(i, j int) bool {
+		si, sj := p.running[slow[i]], p.running[slow[j]]
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return slow[i].Package+"."+slow[i].Test < slow[j].Package+"."+slow[j].Test
+	})
+	return slow
+}
+
+// hasRunningSubtest returns true iff a subtest of the test is running.
+func (p *progress) hasRunningSubtest(rk resultKey) bool {
+	for running := range p.running {
+		if running.Package == rk.Package && strings.HasPrefix(running.Test, rk.Test+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package gotest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_progress_Accept(t *testing.T) {
+	var status statusRecorder
+	now := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	tested := newProgress(&status, 10*time.Second)
+	tested.now = func() time.Time { return now }
+	tested.started = now
+	tested.total = 3
+
+	accept := func(e event) {
+		t.Helper()
+		require.NoError(t, tested.Accept(e))
+	}
+	accept(event{Action: "start", Package: "example.com/a"})
+	accept(event{Action: "run", Package: "example.com/a", Test: "TestSlow"})
+	accept(event{Action: "run", Package: "example.com/a", Test: "TestParallel"})
+	accept(event{Action: "pause", Package: "example.com/a", Test: "TestParallel"})
+	now = now.Add(5 * time.Second)
+	accept(event{Action: "run", Package: "example.com/a", Test: "TestSlow/sub"})
+	accept(event{Action: "pass", Package: "example.com/a", Test: "TestSlow/sub"})
+	accept(event{Action: "output", Package: "example.com/a", Test: "TestSlow", Output: "hi\n"})
+	require.Equal(t, "0/3 packages, 1 passed, 0 failed, 0 skipped, 5s", status.last())
+
+	// TestParallel is paused so only TestSlow is slow.
+	now = now.Add(10 * time.Second)
+	accept(event{Action: "run", Package: "example.com/b", Test: "TestSkip"})
+	accept(event{Action: "skip", Package: "example.com/b", Test: "TestSkip"})
+	require.Equal(t, "0/3 packages, 1 passed, 0 failed, 1 skipped, 15s; slow: a.TestSlow (15s)", status.last())
+
+	accept(event{Action: "cont", Package: "example.com/a", Test: "TestParallel"})
+	now = now.Add(20 * time.Second)
+	require.NoError(t, tested.update())
+	require.Equal(t, "0/3 packages, 1 passed, 0 failed, 1 skipped, 35s; slow: a.TestSlow (35s), a.TestParallel (20s)", status.last())
+
+	accept(event{Action: "fail", Package: "example.com/a", Test: "TestSlow"})
+	accept(event{Action: "pass", Package: "example.com/b"})
+	require.Equal(t, "1/3 packages, 1 passed, 1 failed, 1 skipped, 35s; slow: a.TestParallel (20s)", status.last())
+
+	// Tests that never completed are no longer running once their package
+	// completes.
+	accept(event{Action: "fail", Package: "example.com/a"})
+	require.Equal(t, "2/3 packages, 1 passed, 1 failed, 1 skipped, 35s", status.last())
+}
+
+func Test_progress_status_truncated(t *testing.T) {
+	now := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	tested := newProgress(&statusRecorder{}, time.Second)
+	tested.now = func() time.Time { return now }
+	tested.started = now
+	for i := 0; i < 10; i++ {
+		require.NoError(t, tested.Accept(event{Action: "run", Package: "example.com/a", Test: "TestSomethingSlow" + strings.Repeat("x", i)}))
+	}
+	now = now.Add(time.Minute)
+	require.Equal(
+		t,
+		"0 packages, 0 passed, 0 failed, 0 skipped, 1m0s; slow: a.TestSomethingSlow (1m0s), +9 more",
+		tested.status(),
+	)
+
+	tested = newProgress(&statusRecorder{}, time.Second)
+	tested.now = func() time.Time { return now }
+	tested.started = now
+	require.NoError(t, tested.Accept(event{Action: "run", Package: "example.com/a", Test: "Test" + strings.Repeat("Long", 30)}))
+	now = now.Add(time.Minute)
+	status := tested.status()
+	require.Len(t, status, maxProgressWidth)
+	require.True(t, strings.HasSuffix(status, "..."))
+}
+
+func Test_progress_startStop(t *testing.T) {
+	var status statusRecorder
+	tested := newProgress(&status, time.Minute)
+	tested.start(2, time.Millisecond)
+	require.Eventually(t, func() bool { return status.last() != "" }, time.Second, time.Millisecond)
+	require.True(t, strings.HasPrefix(status.last(), "0/2 packages, 0 passed, 0 failed, 0 skipped, "))
+	require.NoError(t, tested.stop())
+	require.Equal(t, "", status.last())
+}
+
+// statusRecorder is a statusSetter that records every status set.
+type statusRecorder struct {
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (s *statusRecorder) SetStatus(status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+func (s *statusRecorder) all() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.statuses...)
+}
+
+func (s *statusRecorder) last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.statuses) == 0 {
+		return ""
+	}
+	return s.statuses[len(s.statuses)-1]
+}
@@ -0,0 +1,220 @@
+package gotest
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// testQuarantined is the outcome of a failed test that is quarantined,
+// which does not fail the run.
+const testQuarantined = "quarantined"
+
+// QuarantinedTest is a known-broken or flaky test that is still run, but
+// whose failure does not fail the run.
+type QuarantinedTest struct {
+	// Package is the import path of the package of the test, or a pattern
+	// ending in "/..." that matches the package and all packages below it.
+	Package string
+	// Test is a regular expression that must match the whole name of the
+	// test (e.g. "TestFoo" or "TestFoo/case_.*"). All tests of the package
+	// match if it is empty. The subtests of a matching test are
+	// quarantined too.
+	Test    string
+	Owner   string
+	Reason  string
+	Expires time.Time
+}
+
+// String returns a description of the quarantine (e.g. "owner @team,
+// expires 2026-12-31: flaky on CI").
+func (q QuarantinedTest) String() string {
+	return fmt.Sprintf("owner %s, expires %s: %s", q.Owner, q.Expires.Format(time.DateOnly), q.Reason)
+}
+
+// Expired returns true iff the quarantine expired before now. It expires
+// at the end of the day of Expires.
+func (q QuarantinedTest) Expired(now time.Time) bool {
+	return !now.Before(q.Expires.AddDate(0, 0, 1))
+}
+
+// quarantinedTest is a QuarantinedTest with its Test compiled.
+type quarantinedTest struct {
+	QuarantinedTest
+	test *regexp.Regexp
+}
+
+// compileQuarantine compiles the Test of the quarantined test.
+func compileQuarantine(q QuarantinedTest) (quarantinedTest, error) {
+	compiled := quarantinedTest{QuarantinedTest: q}
+	if q.Test != "" {
+		var err error
+		if compiled.test, err = regexp.Compile("^(?:" + q.Test + ")$"); err != nil {
+			return quarantinedTest{}, fmt.Errorf("gotest: invalid quarantined test %q: %w", q.Test, err)
+		}
+	}
+	return compiled, nil
+}
+
+// matches returns true iff the quarantine matches the test of the package.
+func (q quarantinedTest) matches(pkg, test string) bool {
+	if pattern, ok := strings.CutSuffix(q.Package, "/..."); ok {
+		if pkg != pattern && !strings.HasPrefix(pkg, pattern+"/") {
+			return false
+		}
+	} else if pkg != q.Package {
+		return false
+	}
+	return q.test == nil || q.test.MatchString(test)
+}
+
+// quarantine is a resultAccepter that changes the outcome of failed tests
+// that are quarantined to testQuarantined before forwarding the results to
+// the next resultAccepter. A failed test whose only failures are its
+// quarantined subtests is quarantined too.
+//
+// A package that failed only because of quarantined tests passes, and its
+// output is changed accordingly. A package that crashed, or that has
+// output other than the "FAIL" lines of its failed tests (e.g. because
+// TestMain failed), still fails.
+type quarantine struct {
+	next  resultAccepter
+	tests []quarantinedTest
+	// quarantined and failed are the packages with quarantined tests and
+	// with failed tests that are not quarantined.
+	quarantined map[string]bool
+	failed      map[string]bool
+}
+
+var _ resultAccepter = (*quarantine)(nil)
+
+func newQuarantine(next resultAccepter, tests []quarantinedTest) *quarantine {
+	return &quarantine{
+		next:        next,
+		tests:       tests,
+		quarantined: make(map[string]bool),
+		failed:      make(map[string]bool),
+	}
+}
+
+func (q *quarantine) Accept(res result) error {
+	switch {
+	case res.Key.ImportPath != "":
+	case res.Key.Test != "":
+		res = q.apply(res, nil)
+		res.walk(func(res result) {
+			switch res.Outcome {
+			case testQuarantined:
+				q.quarantined[res.Key.Package] = true
+			case testFailure:
+				q.failed[res.Key.Package] = true
+			}
+		})
+	case res.Key.Package != "":
+		pkg := res.Key.Package
+		if res.Outcome == testFailure && q.quarantined[pkg] && !q.failed[pkg] && !res.Crashed && res.FailedBuild == "" &&
+			res.Reason == "" && hasOnlyFailLines(res.Output) {
+			res.Outcome = "pass"
+			res.Output = passedPackageOutput(res.Output)
+		}
+		delete(q.quarantined, pkg)
+		delete(q.failed, pkg)
+	}
+	return q.next.Accept(res)
+}
+
+// apply quarantines the test result and its subtests. The quarantine of
+// the parent of the test, if any, applies to the test too.
+func (q *quarantine) apply(res result, parent *QuarantinedTest) result {
+	entry := parent
+	if entry == nil {
+		i := slices.IndexFunc(q.tests, func(t quarantinedTest) bool { return t.matches(res.Key.Package, res.Key.Test) })
+		if i >= 0 {
+			entry = &q.tests[i].QuarantinedTest
+		}
+	}
+	if len(res.Subtests) > 0 {
+		res.Subtests = slices.Clone(res.Subtests)
+		for i := range res.Subtests {
+			res.Subtests[i] = q.apply(res.Subtests[i], entry)
+		}
+	}
+	if res.Outcome != testFailure {
+		return res
+	}
+	if entry == nil && res.Reason == "" && res.ErrorOutput == "" && !res.hasFailedSubtest() {
+		// The test failed only because of its quarantined subtests.
+		if i := slices.IndexFunc(res.Subtests, isQuarantined); i >= 0 {
+			entry = res.Subtests[i].Quarantine
+		}
+	}
+	if entry != nil {
+		res.Outcome = testQuarantined
+		res.Quarantine = entry
+	}
+	return res
+}
+
+// hasOnlyFailLines returns true iff the output of a package has no lines
+// other than the "FAIL" lines that are printed when its tests fail.
+func hasOnlyFailLines(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" && line != "FAIL" && !strings.HasPrefix(line, "FAIL\t") {
+			return false
+		}
+	}
+	return true
+}
+
+// passedPackageOutput returns the output of a package that failed as if it
+// passed: the "FAIL" lines are removed or replaced with "ok" lines.
+func passedPackageOutput(output string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(output, "\n") {
+		switch {
+		case strings.TrimSuffix(line, "\n") == "FAIL":
+			continue
+		case strings.HasPrefix(line, "FAIL\t"):
+			line = "ok  \t" + strings.TrimPrefix(line, "FAIL\t")
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// isQuarantined returns true iff the result is a quarantined failure.
+func isQuarantined(res result) bool {
+	return res.Outcome == testQuarantined
+}
+
+// hasQuarantinedSubtest returns true iff any subtest of the result (at
+// any depth) is quarantined.
+func (res result) hasQuarantinedSubtest() bool {
+	for _, subtest := range res.Subtests {
+		if isQuarantined(subtest) || subtest.hasQuarantinedSubtest() {
+			return true
+		}
+	}
+	return false
+}
+
+// failedItself returns true iff the result failed or is quarantined other
+// than only because a subtest failed or is quarantined.
+func (res result) failedItself() bool {
+	return isFailedOrQuarantined(res) && !res.hasFailedSubtest() && !res.hasQuarantinedSubtest()
+}
+
+// isFailedOrQuarantined returns true iff the result failed, whether or
+// not it is quarantined.
+func isFailedOrQuarantined(res result) bool {
+	return isFailed(res) || isQuarantined(res)
+}
+
+// quarantineLine returns the line describing the quarantine of a
+// quarantined test (e.g. "--- QUARANTINED: TestFoo (owner @team, expires
+// 2026-12-31: flaky on CI)").
+func quarantineLine(res result) string {
+	return fmt.Sprintf("--- QUARANTINED: %s (%s)\n", res.Key.Test, res.Quarantine)
+}
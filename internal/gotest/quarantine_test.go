@@ -0,0 +1,175 @@
+package gotest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/junit"
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// flakyTable quarantines the failing subtest of the subtests.json fixture.
+var flakyTable = QuarantinedTest{
+	Package: "example.com/fx/...",
+	Test:    "TestTable/b",
+	Owner:   "@team",
+	Reason:  "flaky",
+	Expires: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+}
+
+func Test_Report_quarantine(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var (
+		quietOutputBuf bytes.Buffer
+		tapBuf         bytes.Buffer
+		report         junit.Testsuites
+		jsonSummary    summary.Summary
+	)
+	err = Report(
+		f,
+		Quarantine(flakyTable),
+		QuietOutput(&quietOutputBuf),
+		TAPReport(&tapBuf),
+		JUnitReport(&report),
+		JSONSummary(&jsonSummary),
+	)
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		"=== RUN   TestTable\n"+
+			"=== RUN   TestTable/b\n"+
+			"    subtests_test.go:8: in b\n"+
+			"    subtests_test.go:10: bad\n"+
+			"--- FAIL: TestTable/b (0.00s)\n"+
+			"--- FAIL: TestTable (0.00s)\n"+
+			"--- QUARANTINED: TestTable/b (owner @team, expires 2026-12-31: flaky)\n"+
+			"ok  \texample.com/fx/subtests\t0.003s\n",
+		quietOutputBuf.String(),
+	)
+
+	require.Contains(t, tapBuf.String(), "    not ok 1 - TestTable # TODO quarantined (owner @team, expires 2026-12-31: flaky)\n")
+	require.Contains(t, tapBuf.String(), "ok 1 - example.com/fx/subtests\n")
+
+	suite := report.Suites[0]
+	require.Equal(t, 0, suite.Failures)
+	require.Equal(t, 3, suite.Skipped)
+	quarantined := suite.Testcases[1]
+	require.Equal(t, "TestTable/b", quarantined.Name)
+	require.Equal(t, "quarantined (owner @team, expires 2026-12-31: flaky)", quarantined.Skipped.Message)
+	require.Contains(t, quarantined.SystemOut, "subtests_test.go:10: bad\n")
+
+	pkg := jsonSummary.Packages[0]
+	require.Equal(t, "pass", pkg.Outcome)
+	require.Equal(t, "quarantined", pkg.Tests[0].Outcome)
+	require.Equal(t, "quarantined", pkg.Tests[3].Outcome)
+	require.Equal(t, &summary.Quarantine{Owner: "@team", Reason: "flaky", Expires: "2026-12-31"}, pkg.Tests[3].Quarantine)
+}
+
+func Test_Report_quarantineOtherFailure(t *testing.T) {
+	events := `{"Action":"run","Package":"pkg","Test":"TestFlaky"}
+{"Action":"fail","Package":"pkg","Test":"TestFlaky"}
+{"Action":"run","Package":"pkg","Test":"TestBroken"}
+{"Action":"fail","Package":"pkg","Test":"TestBroken"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t0.01s\n"}
+{"Action":"fail","Package":"pkg"}
+`
+	var quietOutputBuf bytes.Buffer
+	err := Report(
+		strings.NewReader(events),
+		Quarantine(QuarantinedTest{Package: "pkg", Test: "TestFl.*"}),
+		QuietOutput(&quietOutputBuf),
+	)
+	require.EqualError(t, err, "1 package failed")
+	require.Contains(t, quietOutputBuf.String(), "--- QUARANTINED: TestFlaky (")
+	require.Contains(t, quietOutputBuf.String(), "FAIL\tpkg\t0.01s\n")
+}
+
+func Test_Report_quarantinePackageFailure(t *testing.T) {
+	// The package still fails since TestMain failed after the quarantined
+	// test did.
+	events := `{"Action":"run","Package":"pkg","Test":"TestFlaky"}
+{"Action":"fail","Package":"pkg","Test":"TestFlaky"}
+{"Action":"output","Package":"pkg","Output":"FAIL\n"}
+{"Action":"output","Package":"pkg","Output":"goleak: Errors on successful test run: found unexpected goroutines\n"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t0.01s\n"}
+{"Action":"fail","Package":"pkg"}
+`
+	var quietOutputBuf bytes.Buffer
+	err := Report(
+		strings.NewReader(events),
+		Quarantine(QuarantinedTest{Package: "pkg", Test: "TestFlaky"}),
+		QuietOutput(&quietOutputBuf),
+	)
+	require.EqualError(t, err, "1 package failed")
+	require.Contains(t, quietOutputBuf.String(), "--- QUARANTINED: TestFlaky (")
+	require.Contains(t, quietOutputBuf.String(), "found unexpected goroutines\nFAIL\tpkg\t0.01s\n")
+}
+
+func Test_Run_quarantine(t *testing.T) {
+	popd := pushd(t, "testdata")
+	defer popd()
+	var quietOutputBuf bytes.Buffer
+	err := Run(
+		Env("GOTEST_FAIL=1"),
+		Quarantine(QuarantinedTest{Package: "oss.indeed.com/go/go-opine/internal/gotest/testdata"}),
+		QuietOutput(&quietOutputBuf),
+	)
+	require.NoError(t, err)
+	require.Contains(t, quietOutputBuf.String(), "--- QUARANTINED: Test_Some_test (")
+}
+
+func Test_Quarantine_invalidTest(t *testing.T) {
+	err := Report(strings.NewReader(""), Quarantine(QuarantinedTest{Package: "pkg", Test: "("}))
+	require.ErrorContains(t, err, `gotest: invalid quarantined test "("`)
+}
+
+func Test_quarantinedTest_matches(t *testing.T) {
+	for _, tc := range []struct {
+		pkg, test string
+		pattern   QuarantinedTest
+		expected  bool
+	}{
+		{"example.com/a", "TestFoo", QuarantinedTest{Package: "example.com/a"}, true},
+		{"example.com/a", "TestFoo", QuarantinedTest{Package: "example.com/a", Test: "TestFoo"}, true},
+		{"example.com/a", "TestFooBar", QuarantinedTest{Package: "example.com/a", Test: "TestFoo"}, false},
+		{"example.com/a", "TestFoo/x", QuarantinedTest{Package: "example.com/a", Test: "TestFoo/.*"}, true},
+		{"example.com/a", "TestFoo", QuarantinedTest{Package: "example.com/..."}, true},
+		{"example.com", "TestFoo", QuarantinedTest{Package: "example.com/..."}, true},
+		{"example.community", "TestFoo", QuarantinedTest{Package: "example.com/..."}, false},
+		{"example.com/ab", "TestFoo", QuarantinedTest{Package: "example.com/a"}, false},
+	} {
+		q, err := compileQuarantine(tc.pattern)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, q.matches(tc.pkg, tc.test), "%s.%s %+v", tc.pkg, tc.test, tc.pattern)
+	}
+}
+
+func Test_QuarantinedTest_Expired(t *testing.T) {
+	require.False(t, flakyTable.Expired(time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)))
+	require.True(t, flakyTable.Expired(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func Test_hasOnlyFailLines(t *testing.T) {
+	require.True(t, hasOnlyFailLines("FAIL\nFAIL\tpkg\t0.01s\n"))
+	require.True(t, hasOnlyFailLines(""))
+	require.False(t, hasOnlyFailLines("setup failed: no database\nFAIL\tpkg\t0.01s\n"))
+	require.False(t, hasOnlyFailLines("PASS\nFAIL\tpkg\t0.01s\n"))
+}
+
+func Test_passedPackageOutput(t *testing.T) {
+	require.Equal(
+		t,
+		"coverage: 50.0% of statements\nok  \tpkg\t0.01s\n",
+		passedPackageOutput("FAIL\ncoverage: 50.0% of statements\nFAIL\tpkg\t0.01s\n"),
+	)
+}
@@ -0,0 +1,215 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// dataRaceRegexp matches a data race report printed by the race
+	// detector. The first group is the body of the report.
+	dataRaceRegexp = regexp.MustCompile(`(?ms)^==================\nWARNING: DATA RACE\n(.*?)^==================\n`)
+
+	// raceAccessRegexp matches the header of a memory access in a data race
+	// report (e.g. "Previous write at 0x00c000018308 by goroutine 8:").
+	raceAccessRegexp = regexp.MustCompile(`^(.+?) at 0x[0-9a-f]+ by (.+):$`)
+
+	// raceGoroutineRegexp matches the header of a goroutine creation site
+	// in a data race report (e.g. "Goroutine 9 (running) created at:").
+	raceGoroutineRegexp = regexp.MustCompile(`^Goroutine (\d+) \(\w+\) created at:$`)
+
+	// raceFrameFileRegexp matches the file line of a stack frame in a data
+	// race report (e.g. "      /src/race.go:7 +0x36").
+	raceFrameFileRegexp = regexp.MustCompile(`^\s+(\S+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// stackFrame is a single frame of a stack trace.
+type stackFrame struct {
+	Func string
+	File string
+	Line int
+}
+
+// location returns the "file:line" location of the frame.
+func (f stackFrame) location() string {
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// raceAccess is one of the two memory accesses of a data race.
+type raceAccess struct {
+	// Op describes the access (e.g. "Read" or "Previous write").
+	Op string
+	// Goroutine is the goroutine that made the access (e.g. "goroutine 9"
+	// or "main goroutine").
+	Goroutine string
+	// Stack is the stack of the access, from the innermost frame to the
+	// outermost. It is empty if the race detector could not restore it.
+	Stack []stackFrame
+}
+
+// raceGoroutine is the creation site of a goroutine involved in a data
+// race.
+type raceGoroutine struct {
+	ID    int
+	Stack []stackFrame
+}
+
+// dataRace is a data race report printed by the race detector.
+type dataRace struct {
+	Current    raceAccess
+	Previous   raceAccess
+	Goroutines []raceGoroutine
+}
+
+// findDataRaces returns the data races reported in the provided output.
+func findDataRaces(output string) []dataRace {
+	var races []dataRace
+	for _, m := range dataRaceRegexp.FindAllStringSubmatch(output, -1) {
+		races = append(races, parseDataRace(m[1]))
+	}
+	return races
+}
+
+// parseDataRace parses the body of a data race report, which is made up
+// of sections separated by blank lines. Sections other than the memory
+// accesses and goroutine creation sites (e.g. the location of a global
+// variable) are ignored.
+func parseDataRace(body string) dataRace {
+	var (
+		race     dataRace
+		accesses int
+	)
+	for _, section := range strings.Split(body, "\n\n") {
+		header, frames, _ := strings.Cut(strings.Trim(section, "\n"), "\n")
+		if m := raceAccessRegexp.FindStringSubmatch(header); m != nil {
+			access := raceAccess{Op: m[1], Goroutine: m[2], Stack: parseRaceStack(frames)}
+			if accesses == 0 {
+				race.Current = access
+			} else {
+				race.Previous = access
+			}
+			accesses++
+		} else if m := raceGoroutineRegexp.FindStringSubmatch(header); m != nil {
+			id, _ := strconv.Atoi(m[1])
+			race.Goroutines = append(race.Goroutines, raceGoroutine{ID: id, Stack: parseRaceStack(frames)})
+		}
+	}
+	return race
+}
+
+// parseRaceStack parses the stack frames of a data race report section.
+// Each frame is a function line followed by a file line.
+func parseRaceStack(frames string) []stackFrame {
+	var (
+		stack []stackFrame
+		fn    string
+	)
+	for _, line := range strings.Split(frames, "\n") {
+		if m := raceFrameFileRegexp.FindStringSubmatch(line); m != nil && fn != "" {
+			n, _ := strconv.Atoi(m[2])
+			stack = append(stack, stackFrame{Func: fn, File: m[1], Line: n})
+			fn = ""
+			continue
+		}
+		fn = strings.TrimSuffix(strings.TrimSpace(line), "()")
+	}
+	return stack
+}
+
+// signature identifies the data race regardless of which test it was
+// detected in. Like the race detector itself, which reports each pair of
+// racing program locations once, it is made up of the innermost frames of
+// the two accesses.
+func (r dataRace) signature() string {
+	return r.Current.Op + " " + accessLocation(r.Current) + " " + r.Previous.Op + " " + accessLocation(r.Previous)
+}
+
+// accessLocation returns the function and "file:line" location of the
+// innermost frame of the access.
+func accessLocation(a raceAccess) string {
+	if len(a.Stack) == 0 {
+		return "(unknown location)"
+	}
+	return a.Stack[0].Func + " " + a.Stack[0].location()
+}
+
+// findRaces is a resultAccepter that sets the Races of results from
+// their output before forwarding to the next result accepter.
+type findRaces struct {
+	next resultAccepter
+}
+
+var _ resultAccepter = (*findRaces)(nil)
+
+func newFindRaces(next resultAccepter) *findRaces {
+	return &findRaces{next: next}
+}
+
+func (f *findRaces) Accept(res result) error {
+	res.Races = findDataRaces(res.Output)
+	return f.next.Accept(res)
+}
+
+// raceSummary is a resultAccepter that collects the data races of all
+// results, deduplicated by signature, and writes a summary of them to an
+// io.Writer when finished.
+type raceSummary struct {
+	to    io.Writer
+	races []dataRace
+	tests map[string][]string
+}
+
+var (
+	_ resultAccepter = (*raceSummary)(nil)
+	_ resultFinisher = (*raceSummary)(nil)
+)
+
+func newRaceSummary(to io.Writer) *raceSummary {
+	return &raceSummary{to: to, tests: make(map[string][]string)}
+}
+
+func (s *raceSummary) Accept(res result) error {
+	res.walk(func(res result) {
+		name := resultName(res)
+		for _, race := range res.Races {
+			sig := race.signature()
+			tests, ok := s.tests[sig]
+			if !ok {
+				s.races = append(s.races, race)
+			}
+			if len(tests) == 0 || tests[len(tests)-1] != name {
+				s.tests[sig] = append(tests, name)
+			}
+		}
+	})
+	return nil
+}
+
+// Finish writes the summary of the data races. Nothing is written if
+// there were no data races.
+func (s *raceSummary) Finish() error {
+	if len(s.races) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	if len(s.races) == 1 {
+		sb.WriteString("\nFound 1 data race:\n")
+	} else {
+		fmt.Fprintf(&sb, "\nFound %d data races:\n", len(s.races))
+	}
+	for i, race := range s.races {
+		fmt.Fprintf(&sb, "\n%d. %s by %s at %s\n", i+1, race.Current.Op, race.Current.Goroutine, accessLocation(race.Current))
+		fmt.Fprintf(&sb, "   %s by %s at %s\n", race.Previous.Op, race.Previous.Goroutine, accessLocation(race.Previous))
+		for _, g := range race.Goroutines {
+			if len(g.Stack) > 0 {
+				fmt.Fprintf(&sb, "   Goroutine %d created at %s %s\n", g.ID, g.Stack[0].Func, g.Stack[0].location())
+			}
+		}
+		fmt.Fprintf(&sb, "   Detected in %s\n", strings.Join(s.tests[race.signature()], ", "))
+	}
+	_, err := io.WriteString(s.to, sb.String())
+	return err
+}
@@ -0,0 +1,143 @@
+package gotest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/junit"
+)
+
+const testDataRaceOutput = `=== RUN   TestFoo
+==================
+WARNING: DATA RACE
+Write at 0x00c000018308 by main goroutine:
+  example.com/foo.Inc()
+      /src/foo/foo.go:7 +0x36
+
+Previous read at 0x00c000018308 by goroutine 9:
+  [failed to restore the stack]
+
+Location is global 'counter' of size 8 at 0x00c000018308 (foo.test+0x1234)
+
+Goroutine 9 (finished) created at:
+  example.com/foo.Start()
+      /src/foo/foo.go:13 +0xf9
+  example.com/foo.TestFoo()
+      /src/foo/foo_test.go:6 +0x1c
+==================
+    testing.go:1865: race detected during execution of test
+--- FAIL: TestFoo (0.00s)
+`
+
+func Test_findDataRaces(t *testing.T) {
+	require.Equal(
+		t,
+		[]dataRace{
+			{
+				Current: raceAccess{
+					Op:        "Write",
+					Goroutine: "main goroutine",
+					Stack:     []stackFrame{{Func: "example.com/foo.Inc", File: "/src/foo/foo.go", Line: 7}},
+				},
+				Previous: raceAccess{
+					Op:        "Previous read",
+					Goroutine: "goroutine 9",
+				},
+				Goroutines: []raceGoroutine{
+					{
+						ID: 9,
+						Stack: []stackFrame{
+							{Func: "example.com/foo.Start", File: "/src/foo/foo.go", Line: 13},
+							{Func: "example.com/foo.TestFoo", File: "/src/foo/foo_test.go", Line: 6},
+						},
+					},
+				},
+			},
+		},
+		findDataRaces(testDataRaceOutput),
+	)
+}
+
+func Test_findDataRaces_none(t *testing.T) {
+	require.Nil(t, findDataRaces("=== RUN   TestFoo\n==================\n--- PASS: TestFoo (0.00s)\n"))
+}
+
+func Test_dataRace_signature(t *testing.T) {
+	races := findDataRaces(testDataRaceOutput)
+	require.Len(t, races, 1)
+	require.Equal(
+		t,
+		"Write example.com/foo.Inc /src/foo/foo.go:7 Previous read (unknown location)",
+		races[0].signature(),
+	)
+}
+
+func Test_parseGoTestJSONOutput_race(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "race.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var (
+		results = make(map[string]result)
+		summary bytes.Buffer
+		report  junit.Testsuites
+	)
+	err = parseGoTestJSONOutput(f, newMultiResultAccepter(
+		resultAccepterFunc(func(res result) error {
+			results[res.Key.Test] = res
+			return nil
+		}),
+		newRaceSummary(&summary),
+		newJUnitOutput(&report),
+	), io.Discard)
+	require.NoError(t, err)
+
+	for _, test := range []string{"TestRacyA", "TestRacyB"} {
+		races := results[test].Races
+		require.Len(t, races, 1, test)
+		require.Equal(t, "Read", races[0].Current.Op)
+		require.Equal(t, "goroutine", races[0].Current.Goroutine[:9])
+		require.Equal(t, "Previous write", races[0].Previous.Op)
+		require.Len(t, races[0].Previous.Stack, 5)
+		require.Equal(t, "example.com/fx/race."+test, races[0].Previous.Stack[2].Func)
+		require.Len(t, races[0].Goroutines, 2)
+	}
+	require.Nil(t, results["TestFine"].Races)
+
+	// The same race in both tests is only summarized once.
+	require.Equal(
+		t,
+		"\nFound 1 data race:\n\n"+
+			"1. Read by goroutine 9 at example.com/fx/race.(*Counter).Inc /src/fx/race/race.go:7\n"+
+			"   Previous write by goroutine 8 at example.com/fx/race.(*Counter).Inc /src/fx/race/race.go:7\n"+
+			"   Goroutine 9 created at example.com/fx/race.Racy /src/fx/race/race.go:13\n"+
+			"   Goroutine 8 created at testing.(*T).Run /go/src/testing/testing.go:2258\n"+
+			"   Detected in example.com/fx/race.TestRacyA, example.com/fx/race.TestRacyB\n",
+		summary.String(),
+	)
+
+	testcases := report.Suites[0].Testcases
+	require.Equal(t, &junit.Failure{Message: "data race detected", Type: "race", Contents: results["TestRacyA"].Output}, testcases[0].Failure)
+	require.Nil(t, testcases[2].Failure)
+}
+
+func Test_raceSummary_Finish_noRaces(t *testing.T) {
+	var summary bytes.Buffer
+	tested := newRaceSummary(&summary)
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "pkg", Test: "TestFoo"}}))
+	require.NoError(t, tested.Finish())
+	require.Empty(t, summary.String())
+}
+
+func Test_raceSummary_Finish_error(t *testing.T) {
+	expectedErr := errors.New("blah")
+	tested := newRaceSummary(&errorWriter{err: expectedErr})
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "pkg"}, Races: findDataRaces(testDataRaceOutput)}))
+	require.ErrorIs(t, tested.Finish(), expectedErr)
+}
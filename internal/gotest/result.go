@@ -2,6 +2,7 @@ package gotest
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -16,11 +17,51 @@ type resultKey struct {
 
 // result is a test result. The result is for either a single test or for
 // a package, in which case Key.Test is empty.
+//
+// The Output of a test result never includes the output of its subtests.
 type result struct {
 	Key     resultKey
 	Outcome string
 	Output  string
-	Elapsed time.Duration
+	// ErrorOutput is the part of the Output that "go test" categorized as
+	// errors (e.g. from t.Error). Older versions of Go do not categorize
+	// output, in which case it is empty.
+	ErrorOutput string
+	Elapsed     time.Duration
+	// Reason explains a failure that was determined by go-opine rather
+	// than reported by "go test" (e.g. "timed out after 10m0s"). It is
+	// empty for all other results.
+	Reason string
+	// Crashed is true for a package result when the test binary exited
+	// before reporting the outcome of every test (e.g. a test panicked or
+	// called os.Exit). Reason explains why.
+	Crashed bool
+	// Attrs are the attributes the test set using testing.T.Attr.
+	Attrs []attr
+	// ArtifactDir is the directory the test stored artifacts in, if any
+	// (see testing.T.ArtifactDir).
+	ArtifactDir string
+	// Races are the data races reported by the race detector in the
+	// Output.
+	Races []dataRace
+	// FailedBuild is the import path of the build that failed (see
+	// Key.ImportPath) for a package result that failed because of it.
+	FailedBuild string
+	// Diagnostics are the compiler and vet diagnostics in the Output of a
+	// build result.
+	Diagnostics []buildDiagnostic
+	// File and Line are the location of the declaration of the test
+	// function, or of the top level test function for a subtest. They are
+	// only set by a testLocator, and only if the location was found.
+	File string
+	Line int
+	// Quarantine is the quarantine of a test with the testQuarantined
+	// outcome. It is only set by a quarantine.
+	Quarantine *QuarantinedTest
+	// Subtests are the results of the subtests of a test, in the order
+	// they completed. Only results passed on by a resultPackageGrouper
+	// have subtests; before that each subtest is a separate result.
+	Subtests []result
 }
 
 // resultAccepter accepts results.
@@ -28,13 +69,22 @@ type resultAccepter interface {
 	Accept(res result) error
 }
 
+// resultFinisher is implemented by resultAccepters that need to know
+// when all results have been accepted (e.g. to write a summary).
+type resultFinisher interface {
+	Finish() error
+}
+
 // multiResultAccepter accepts results and forwards them on to zero or
 // more downstream result accepters.
 type multiResultAccepter struct {
 	accepters []resultAccepter
 }
 
-var _ resultAccepter = (*multiResultAccepter)(nil)
+var (
+	_ resultAccepter = (*multiResultAccepter)(nil)
+	_ resultFinisher = (*multiResultAccepter)(nil)
+)
 
 func newMultiResultAccepter(accepter ...resultAccepter) *multiResultAccepter {
 	return &multiResultAccepter{accepters: accepter}
@@ -52,9 +102,37 @@ func (m multiResultAccepter) Accept(res result) error {
 	return nil
 }
 
+// Finish calls Finish on each downstream resultAccepter that is a
+// resultFinisher. If any returns an error processing stops immediately
+// and that error is returned to the caller.
+func (m multiResultAccepter) Finish() error {
+	for _, accepter := range m.accepters {
+		if err := finish(accepter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish calls Finish on the resultAccepter if it is a resultFinisher.
+func finish(accepter resultAccepter) error {
+	if f, ok := accepter.(resultFinisher); ok {
+		return f.Finish()
+	}
+	return nil
+}
+
 // resultAggregator is an eventAccepter that aggregates events for the same
 // test or package into results. Completed results are passed to the
 // resultAccepter.
+//
+// When a package completes, any of its tests that never reported an
+// outcome (because the test binary timed out or crashed) are failed and
+// passed to the resultAccepter before the package result.
+//
+// A build that has output but does not fail never completes, so its
+// result (with a buildWarnings outcome) is passed to the resultAccepter
+// when its package completes, or by FlushBuildWarnings.
 type resultAggregator struct {
 	to     resultAccepter
 	events map[resultKey][]event
@@ -90,18 +168,42 @@ func (a *resultAggregator) Accept(e event) error {
 		return nil
 	}
 
-	var output strings.Builder
-	for _, prevEvent := range a.events[rk] {
-		output.WriteString(prevEvent.Output)
+	var (
+		output strings.Builder
+		reason string
+	)
+	if rk.Test == "" && rk.Package != "" {
+		if err := a.flushBuildWarnings(rk.Package); err != nil {
+			return err
+		}
+		pkgOutput, crashReason, err := a.closeDanglingTests(rk.Package)
+		if err != nil {
+			a.setErr(err)
+			return a.err
+		}
+		output.WriteString(pkgOutput)
+		reason = crashReason
 	}
+	events := a.events[rk]
 	delete(a.events, rk)
+	output.WriteString(eventsOutput(events))
 	output.WriteString(e.Output)
 
+	attrs, artifactDir := eventsAttrs(events)
 	res := result{
-		Key:     rk,
-		Outcome: e.Action,
-		Output:  output.String(),
-		Elapsed: time.Duration(e.Elapsed * float64(time.Second)),
+		Key:         rk,
+		Outcome:     e.Action,
+		Output:      output.String(),
+		ErrorOutput: eventsErrorOutput(events),
+		Elapsed:     time.Duration(e.Elapsed * float64(time.Second)),
+		Reason:      reason,
+		FailedBuild: e.FailedBuild,
+		Crashed:     reason != "",
+		Attrs:       attrs,
+		ArtifactDir: artifactDir,
+	}
+	if rk.ImportPath != "" {
+		res.Diagnostics = parseBuildDiagnostics(res.Output)
 	}
 	if err := a.to.Accept(res); err != nil {
 		a.setErr(err)
@@ -111,6 +213,78 @@ func (a *resultAggregator) Accept(e event) error {
 	return nil
 }
 
+// FlushBuildWarnings passes the results of the builds that had output but
+// did not fail, and whose packages did not complete, to the
+// resultAccepter.
+func (a *resultAggregator) FlushBuildWarnings() error {
+	if a.err != nil {
+		return a.err
+	}
+	return a.flushBuildWarnings("")
+}
+
+// flushBuildWarnings passes the results of the builds of the package that
+// had output but did not fail to the resultAccepter. If pkg is empty the
+// results of the builds of all packages are.
+func (a *resultAggregator) flushBuildWarnings(pkg string) error {
+	var keys []resultKey
+	for rk, events := range a.events {
+		if rk.ImportPath == "" || (pkg != "" && buildPackage(rk.ImportPath) != pkg) {
+			continue
+		}
+		if slices.ContainsFunc(events, func(e event) bool { return e.Action != buildWarnings }) {
+			continue
+		}
+		keys = append(keys, rk)
+	}
+	slices.SortFunc(keys, func(a, b resultKey) int { return strings.Compare(a.ImportPath, b.ImportPath) })
+	for _, rk := range keys {
+		output := eventsOutput(a.events[rk])
+		delete(a.events, rk)
+		res := result{
+			Key:         rk,
+			Outcome:     buildWarnings,
+			Output:      output,
+			Diagnostics: parseBuildDiagnostics(output),
+		}
+		if err := a.to.Accept(res); err != nil {
+			a.setErr(err)
+			return a.err
+		}
+	}
+	return nil
+}
+
+// eventsOutput returns the concatenated output of the provided events.
+func eventsOutput(events []event) string {
+	var output strings.Builder
+	for _, e := range events {
+		output.WriteString(e.Output)
+	}
+	return output.String()
+}
+
+// resultName returns the name of a result: the package followed by the
+// test, if any (e.g. "example.com/pkg.TestFoo").
+func resultName(res result) string {
+	if res.Key.Test == "" {
+		return res.Key.Package
+	}
+	return res.Key.Package + "." + res.Key.Test
+}
+
+// eventsErrorOutput returns the concatenated output of the provided
+// events that is categorized as errors.
+func eventsErrorOutput(events []event) string {
+	var output strings.Builder
+	for _, e := range events {
+		if e.OutputType == "error" || e.OutputType == "error-continue" {
+			output.WriteString(e.Output)
+		}
+	}
+	return output.String()
+}
+
 // filterBuildWarnings returns a copy of the supplied events map after removing
 // any resultKeys that only contain "build-output" actions. These actions
 // without a corresponding build-fail event are just build warnings and do not
@@ -149,7 +323,9 @@ func (a *resultAggregator) setErr(err error) {
 }
 
 // resultPackageGrouper accepts results, groups them by package, and
-// forwards all results for a package when it completes.
+// forwards all results for a package when it completes. Subtest results
+// are not forwarded directly: they are nested in the Subtests of their
+// parent (see nestSubtests).
 //
 // This is necessary because by default Go will run tests from different
 // packages at the same time. If the output of each result is printed
@@ -187,8 +363,9 @@ func newResultPackageGrouper(to resultAccepter) *resultPackageGrouper {
 }
 
 // Accept adds the result to the resultPackageGrouper internal state and,
-// if the result is a "package result", forwards all buffered test results
-// and the package result onward.
+// if the result is a "package result", forwards all buffered top level
+// test results (with their subtests nested) and the package result
+// onward.
 //
 // If the resultAccepter returns an error the resultPackageGrouper will enter
 // an error state causing the current accept and all subsequent accepts to
@@ -204,7 +381,9 @@ func (r *resultPackageGrouper) Accept(res result) error {
 		return nil
 	}
 
-	if err := r.forward(r.pkgResults[res.Key.Package]...); err != nil {
+	testResults := r.pkgResults[res.Key.Package]
+	testResults = testResults[:len(testResults)-1]
+	if err := r.forward(append(nestSubtests(testResults), res)...); err != nil {
 		return err
 	}
 	delete(r.pkgResults, res.Key.Package)
@@ -244,7 +423,7 @@ func (r *resultPackageGrouper) setErr(err error) {
 // isTestOrPackageComplete returns true iff the provided event.Action
 // represents the completion of test or package.
 func isTestOrPackageComplete(action string) bool {
-	return action == "pass" || action == "fail" || action == "skip" || action == "build-fail"
+	return action == "pass" || action == "fail" || action == "skip" || action == buildFailure
 }
 
 // isPackageComplete returns true iff the provided result represents
@@ -23,6 +23,25 @@ func Test_resultAccepter_Accept(t *testing.T) {
 	require.True(t, secondCalled)
 }
 
+func Test_resultAccepter_Finish(t *testing.T) {
+	var finished bool
+	tested := newMultiResultAccepter(
+		resultAccepterFunc(func(res result) error { return nil }),
+		resultFinisherFunc(func() error { finished = true; return nil }),
+	)
+	require.NoError(t, tested.Finish())
+	require.True(t, finished)
+}
+
+func Test_resultAccepter_Finish_error(t *testing.T) {
+	expectedErr := errors.New("blah")
+	tested := newMultiResultAccepter(
+		resultFinisherFunc(func() error { return expectedErr }),
+		resultFinisherFunc(func() error { t.Fatal("called after error"); return nil }),
+	)
+	require.ErrorIs(t, tested.Finish(), expectedErr)
+}
+
 func Test_resultAccepter_Accept_error(t *testing.T) {
 	expectedErr := errors.New("fail boat")
 	tested := newMultiResultAccepter(
@@ -308,6 +327,34 @@ func Test_resultPackageGrouper_Accept_OnePackageOneTest(t *testing.T) {
 	require.NoError(t, tested.CheckAllResultsConsumed())
 }
 
+func Test_resultPackageGrouper_Accept_nestsSubtests(t *testing.T) {
+	var results []result
+	tested := newResultPackageGrouper(
+		resultAccepterFunc(func(res result) error { results = append(results, res); return nil }),
+	)
+
+	subtestRes := result{
+		Key:     resultKey{Package: "MyPackage", Test: "MyTest/sub"},
+		Outcome: "pass",
+		Output:  "=== RUN   MyTest/sub\n--- PASS: MyTest/sub (0.00s)\n",
+	}
+	testRes := result{
+		Key:     resultKey{Package: "MyPackage", Test: "MyTest"},
+		Outcome: "pass",
+		Output:  "=== RUN   MyTest\n--- PASS: MyTest (0.00s)\n    --- PASS: MyTest/sub (0.00s)\n",
+	}
+	packageRes := result{Key: resultKey{Package: "MyPackage"}}
+	require.NoError(t, tested.Accept(subtestRes))
+	require.NoError(t, tested.Accept(testRes))
+	require.NoError(t, tested.Accept(packageRes))
+
+	nestedRes := testRes
+	nestedRes.Output = "=== RUN   MyTest\n--- PASS: MyTest (0.00s)\n"
+	nestedRes.Subtests = []result{subtestRes}
+	require.Equal(t, []result{nestedRes, packageRes}, results)
+	require.NoError(t, tested.CheckAllResultsConsumed())
+}
+
 func Test_resultPackageGrouper_Accept_overlappingPackages(t *testing.T) {
 	var results []result
 	tested := newResultPackageGrouper(
@@ -527,3 +574,13 @@ type resultAccepterFunc func(res result) error
 func (f resultAccepterFunc) Accept(res result) error {
 	return f(res)
 }
+
+type resultFinisherFunc func() error
+
+func (f resultFinisherFunc) Accept(result) error {
+	return nil
+}
+
+func (f resultFinisherFunc) Finish() error {
+	return f()
+}
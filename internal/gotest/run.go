@@ -1,11 +1,31 @@
 package gotest
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/junit"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/internal/summary"
+	"oss.indeed.com/go/go-opine/testresult"
+)
+
+var (
+	// ErrTestsFailed is matched (see errors.Is) by the error Run and
+	// Report return when a test or package failed.
+	ErrTestsFailed = errors.New("tests failed")
+
+	// ErrBuildFailed is matched (see errors.Is) by the error Run and
+	// Report return when a package failed because a build failed. Such an
+	// error matches ErrTestsFailed too.
+	ErrBuildFailed = errors.New("build failed")
 )
 
 // Option can be passed to Run to change how it behaves (e.g. test
@@ -18,7 +38,25 @@ type options struct {
 	coverpkg     string
 	covermode    string
 	p            int
+	flags        []string
+	env          []string
+	packages     []string
+	jsonOut      io.Writer
+	warnings     bool
+	quarantine   []quarantinedTest
 	accepters    []resultAccepter
+	// goVersion and goTestArgs are set by Run: goVersion only if
+	// wantGoVersion is true.
+	wantGoVersion bool
+	goVersion     string
+	goTestArgs    []string
+	observers     []eventAccepter
+	progress      *progress
+	// dirs are the directories of the packages tested by import path,
+	// which are listed once when first needed.
+	dirs     map[string]string
+	dirsErr  error
+	dirsOnce sync.Once
 }
 
 // Race runs tests with -race.
@@ -67,11 +105,81 @@ func P(p int) Option {
 	}
 }
 
+// Flags runs tests with additional flags (e.g. "-tags=integration").
+func Flags(flags ...string) Option {
+	return func(o *options) error {
+		o.flags = append(o.flags, flags...)
+		return nil
+	}
+}
+
+// Env runs tests with additional environment variables, each of the form
+// "key=value".
+func Env(env ...string) Option {
+	return func(o *options) error {
+		o.env = append(o.env, env...)
+		return nil
+	}
+}
+
+// Packages tests the packages matching the patterns instead of all
+// packages (./...).
+func Packages(patterns ...string) Option {
+	return func(o *options) error {
+		o.packages = append(o.packages, patterns...)
+		return nil
+	}
+}
+
+// JSONOutput writes the unmodified "go test -json" output to the provided
+// writer as it is read.
+func JSONOutput(to io.Writer) Option {
+	return func(o *options) error {
+		o.jsonOut = to
+		return nil
+	}
+}
+
+// BuildWarnings reports the output of builds that did not fail, which is
+// usually warnings (e.g. from the linker or cgo), like the output of
+// builds that did. By default it is dropped.
+func BuildWarnings() Option {
+	return func(o *options) error {
+		o.warnings = true
+		return nil
+	}
+}
+
+// Quarantine quarantines the tests: their failures are reported with a
+// "quarantined" outcome instead, and do not fail the run. Whether the
+// quarantines expired is not checked.
+func Quarantine(tests ...QuarantinedTest) Option {
+	return func(o *options) error {
+		for _, test := range tests {
+			compiled, err := compileQuarantine(test)
+			if err != nil {
+				return err
+			}
+			o.quarantine = append(o.quarantine, compiled)
+		}
+		return nil
+	}
+}
+
 // QuietOutput writes output similar to "go test" (without "-v")
 // to the provided writer.
 func QuietOutput(to io.Writer) Option {
 	return func(o *options) error {
-		o.accepters = append(o.accepters, &quietOutput{to: to})
+		o.accepters = append(o.accepters, newQuietOutput(to))
+		return nil
+	}
+}
+
+// ColoredQuietOutput is like QuietOutput, except that the output is
+// styled with the provided palette.
+func ColoredQuietOutput(to io.Writer, palette printing.Palette) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newColoredQuietOutput(to, palette, o.packageDir))
 		return nil
 	}
 }
@@ -85,6 +193,212 @@ func VerboseOutput(to io.Writer) Option {
 	}
 }
 
+// JUnitReport adds a JUnit testsuite to the provided report for each
+// package tested.
+func JUnitReport(report *junit.Testsuites) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newJUnitOutput(report))
+		return nil
+	}
+}
+
+// FailureSummary writes a summary of the failed tests and packages (if
+// any) to the provided io.Writer once all tests complete. Each failure is
+// listed with the start of its output and a "go test" command that
+// reproduces it.
+func FailureSummary(to io.Writer) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newFailureSummary(to, o.reproduceFlags, o.packageDir))
+		return nil
+	}
+}
+
+// TAPReport writes TAP version 14 test results to the provided writer,
+// with a subtest for each package.
+func TAPReport(to io.Writer) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newTAPOutput(to))
+		return nil
+	}
+}
+
+// JSONSummary adds each package tested, with its tests, to the provided
+// summary. Run also sets the version of Go and the arguments "go test" was
+// run with.
+func JSONSummary(s *summary.Summary) Option {
+	return func(o *options) error {
+		o.wantGoVersion = true
+		o.accepters = append(o.accepters, newJSONSummary(s, o.goTest))
+		return nil
+	}
+}
+
+// TestCounts adds the number of tests run in each package tested, with
+// the tests that were skipped, to the provided slice.
+func TestCounts(to *[]TestCount) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newTestCounter(to, o.packageDir))
+		return nil
+	}
+}
+
+// ReporterStream writes a plugin.Message to the provided writer, such as
+// a plugin.Reporter, for each test and package as it completes.
+func ReporterStream(to io.Writer) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newReporterStream(to))
+		return nil
+	}
+}
+
+// RaceSummary writes a summary of the data races detected (if any) to the
+// provided io.Writer once all tests complete. Each data race is listed
+// once, with the tests it was detected in.
+func RaceSummary(to io.Writer) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newRaceSummary(to))
+		return nil
+	}
+}
+
+// GitHubAnnotations writes GitHub Actions workflow commands to the
+// provided writer that annotate failed tests, build failures, and data
+// races with their location. File paths in the annotations are relative to
+// root, which should be the root of the repository.
+func GitHubAnnotations(to io.Writer, root string) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, newGitHubAnnotations(to, root, o.packageDir))
+		return nil
+	}
+}
+
+// TeamCityMessages writes TeamCity service messages to the provided
+// writer as soon as each event is read. Each package is reported as a
+// test suite containing its tests.
+func TeamCityMessages(to io.Writer) Option {
+	return func(o *options) error {
+		o.observers = append(o.observers, newTeamCityOutput(to, o.quarantines))
+		return nil
+	}
+}
+
+// Results passes each result, converted to a testresult.Result, to the
+// provided testresult.ResultAccepter. It is finished once all tests
+// complete if it is a testresult.ResultFinisher.
+func Results(to testresult.ResultAccepter) Option {
+	return func(o *options) error {
+		o.accepters = append(o.accepters, &exportedResults{to: to})
+		return nil
+	}
+}
+
+// Events passes each "go test -json" event, converted to a
+// testresult.Event, to the provided testresult.EventAccepter as soon as it
+// is read.
+func Events(to testresult.EventAccepter) Option {
+	return func(o *options) error {
+		o.observers = append(o.observers, &exportedEvents{to: to})
+		return nil
+	}
+}
+
+// Progress keeps a live status line showing the progress of the tests
+// on the provided writer, such as a printing.StatusLineWriter. Tests that
+// have been running for at least slow are listed in the status line.
+func Progress(to interface{ SetStatus(string) error }, slow time.Duration) Option {
+	return func(o *options) error {
+		o.progress = newProgress(to, slow)
+		o.observers = append(o.observers, o.progress)
+		return nil
+	}
+}
+
+// reproduceFlags returns the "go test" flags that affect whether tests
+// pass, to include in commands that reproduce failures.
+func (o *options) reproduceFlags() []string {
+	if o.race {
+		return []string{"-race"}
+	}
+	return nil
+}
+
+// quarantines returns the quarantined tests.
+func (o *options) quarantines() []quarantinedTest {
+	return o.quarantine
+}
+
+// patterns returns the patterns of the packages to test.
+func (o *options) patterns() []string {
+	if len(o.packages) == 0 {
+		return []string{"./..."}
+	}
+	return o.packages
+}
+
+// listFlags are the "go test" flags that change which packages "go list"
+// finds.
+var listFlags = map[string]bool{"-tags": true, "-mod": true, "-modfile": true}
+
+// goListArgs returns the "go list" arguments that list the packages to
+// test, built like "go test" builds them.
+func (o *options) goListArgs() []string {
+	var args []string
+	if o.race {
+		args = append(args, "-race")
+	}
+	for i := 0; i < len(o.flags); i++ {
+		name, _, hasValue := strings.Cut(o.flags[i], "=")
+		if !listFlags["-"+strings.TrimLeft(name, "-")] {
+			continue
+		}
+		args = append(args, o.flags[i])
+		if !hasValue && i+1 < len(o.flags) {
+			i++
+			args = append(args, o.flags[i])
+		}
+	}
+	return append(args, o.patterns()...)
+}
+
+// packageDirs returns the directories of the packages to test by import
+// path. They are listed with a single "go list" the first time they are
+// needed.
+func (o *options) packageDirs() (map[string]string, error) {
+	o.dirsOnce.Do(func() {
+		o.dirs, o.dirsErr = listPackageDirs(o.env, o.goListArgs()...)
+	})
+	return o.dirs, o.dirsErr
+}
+
+// packageDir returns the directory of the package, or empty if it is not
+// one of the packages to test.
+func (o *options) packageDir(pkg string) (string, error) {
+	dirs, err := o.packageDirs()
+	if err != nil {
+		return "", err
+	}
+	return dirs[pkg], nil
+}
+
+// goTest returns the version of Go and the arguments "go test" was run
+// with, or empty values if it was not run.
+func (o *options) goTest() (string, []string) {
+	return o.goVersion, o.goTestArgs
+}
+
+// accepter returns the resultAccepter that passes results to the
+// accepters of the options, preceded by the provided accepters.
+func (o *options) accepter(accepters ...resultAccepter) resultAccepter {
+	var to resultAccepter = newTestLocator(newMultiResultAccepter(append(accepters, o.accepters...)...), o.packageDir)
+	if len(o.quarantine) > 0 {
+		to = newQuarantine(to, o.quarantine)
+	}
+	if !o.warnings {
+		to = newRemoveBuildWarnings(to)
+	}
+	return to
+}
+
 // Run runs go test.
 func Run(opts ...Option) error {
 	var o options
@@ -112,9 +426,28 @@ func Run(opts ...Option) error {
 	if o.p != 0 {
 		args = append(args, "-p="+strconv.Itoa(o.p))
 	}
-	args = append(args, "./...")
+	args = append(args, o.flags...)
+	args = append(args, o.patterns()...)
+	o.goTestArgs = args
+	if o.wantGoVersion {
+		// The version is only informational, so it is not worth failing
+		// over.
+		o.goVersion, _ = goVersion()
+	}
+
+	if o.progress != nil {
+		// The package count is only for display, so it is not worth
+		// failing over.
+		dirs, _ := o.packageDirs()
+		o.progress.start(len(dirs), time.Second)
+		defer func() { _ = o.progress.stop() }()
+	}
+
 	cmd := exec.Command("go", args...)
 	cmd.Stderr = os.Stderr
+	if len(o.env) > 0 {
+		cmd.Env = append(os.Environ(), o.env...)
+	}
 
 	cmdStdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -123,30 +456,170 @@ func Run(opts ...Option) error {
 	if err := cmd.Start(); err != nil {
 		return err
 	}
-	if err := parseGoTestJSONOutput(cmdStdout, newMultiResultAccepter(o.accepters...)); err != nil {
+	var stdout io.Reader = cmdStdout
+	if o.jsonOut != nil {
+		stdout = io.TeeReader(cmdStdout, o.jsonOut)
+	}
+	failed := newPackageFailures()
+	if err := parseGoTestJSONOutput(stdout, o.accepter(failed), os.Stderr, o.observers...); err != nil {
 		_ = cmd.Process.Kill()
 		_ = cmd.Wait()
 		return err
 	}
 	if err := cmd.Wait(); err != nil {
+		if failedErr := failed.err(); failedErr != nil {
+			return failedErr
+		}
+		if failed.rescued > 0 {
+			// Every package that failed passed since the tests that failed
+			// are quarantined.
+			return nil
+		}
 		return fmt.Errorf("go test failed: %w", err)
 	}
 
 	return nil
 }
 
-func parseGoTestJSONOutput(r io.Reader, to resultAccepter) error {
+// Report reports the results of tests from previously saved "go test
+// -json" output, as if Run had run them. Options that only change how
+// "go test" is run (e.g. Race) have no effect. An error matching
+// ErrTestsFailed is returned if any package failed.
+func Report(r io.Reader, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return err
+		}
+	}
+	failed := newPackageFailures()
+	if err := parseGoTestJSONOutput(r, o.accepter(failed), os.Stderr, o.observers...); err != nil {
+		return err
+	}
+	return failed.err()
+}
+
+// packageFailures is a resultAccepter that counts the failed packages, and
+// how many of them failed because a build failed. The packages that
+// passed only because their failed tests are quarantined are counted too.
+type packageFailures struct {
+	count   int
+	builds  int
+	rescued int
+	// quarantined are the packages with quarantined tests that did not
+	// complete yet.
+	quarantined map[string]bool
+}
+
+var _ resultAccepter = (*packageFailures)(nil)
+
+func newPackageFailures() *packageFailures {
+	return &packageFailures{quarantined: make(map[string]bool)}
+}
+
+func (p *packageFailures) Accept(res result) error {
+	switch {
+	case res.Key.Test != "":
+		res.walk(func(res result) {
+			if isQuarantined(res) {
+				p.quarantined[res.Key.Package] = true
+			}
+		})
+	case res.Key.Package != "":
+		switch {
+		case res.Outcome == testFailure:
+			p.count++
+			if res.FailedBuild != "" {
+				p.builds++
+			}
+		case p.quarantined[res.Key.Package]:
+			// The quarantined tests failed, so the package must have
+			// failed too.
+			p.rescued++
+		}
+		delete(p.quarantined, res.Key.Package)
+	}
+	return nil
+}
+
+// err returns a packagesFailedError if any package failed, or nil.
+func (p *packageFailures) err() error {
+	if p.count == 0 {
+		return nil
+	}
+	return packagesFailedError{count: p.count, builds: p.builds}
+}
+
+// packagesFailedError is the error returned when packages failed. It
+// matches ErrTestsFailed, and ErrBuildFailed if any package failed because
+// a build failed.
+type packagesFailedError struct {
+	count  int
+	builds int
+}
+
+func (e packagesFailedError) Error() string {
+	if e.count == 1 {
+		return "1 package failed"
+	}
+	return fmt.Sprintf("%d packages failed", e.count)
+}
+
+// Is reports whether the error matches ErrTestsFailed or ErrBuildFailed.
+func (e packagesFailedError) Is(target error) bool {
+	return target == ErrTestsFailed || (target == ErrBuildFailed && e.builds > 0)
+}
+
+// goVersion returns the version of the go command (e.g. "go1.25.1").
+func goVersion() (string, error) {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// listPackageDirs returns the directories of the packages that "go list"
+// lists with the provided arguments by import path. The environment
+// variables are added to the environment of "go list".
+func listPackageDirs(env []string, args ...string) (map[string]string, error) {
+	cmd := exec.Command("go", append([]string{"list", "-e", "-f", "{{.ImportPath}} {{.Dir}}"}, args...)...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	dirs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if pkg, dir, ok := strings.Cut(line, " "); ok {
+			dirs[pkg] = dir
+		}
+	}
+	return dirs, nil
+}
+
+// parseGoTestJSONOutput parses "go test -json" output and passes the
+// results to the resultAccepter. Each event is also passed to the
+// observers as soon as it is parsed. Warnings about unexpected output are
+// written to warn. Once all results have been accepted the resultAccepter
+// is finished if it is a resultFinisher.
+func parseGoTestJSONOutput(r io.Reader, to resultAccepter, warn io.Writer, observers ...eventAccepter) error {
 	grouper := newResultPackageGrouper(to)
-	aggregator := newResultAggregator(newRemoveCoverageOutput(grouper))
-	parser := newEventStreamParser(aggregator)
+	aggregator := newResultAggregator(newRemoveCoverageOutput(newFindRaces(grouper)))
+	parser := newEventStreamParser(newMultiEventAccepter(append([]eventAccepter{aggregator}, observers...)...), warn)
 	if err := parser.Parse(r); err != nil {
 		return err
 	}
+	if err := aggregator.FlushBuildWarnings(); err != nil {
+		return err
+	}
 	if err := aggregator.CheckAllEventsConsumed(); err != nil {
 		return err
 	}
 	if err := grouper.CheckAllResultsConsumed(); err != nil {
 		return err
 	}
-	return nil
+	return finish(to)
 }
@@ -2,11 +2,17 @@ package gotest
 
 import (
 	"bytes"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/junit"
+	"oss.indeed.com/go/go-opine/internal/summary"
 )
 
 func Test_P_errorLessThanOne(t *testing.T) {
@@ -38,6 +44,9 @@ func Test_Run_allOptions(t *testing.T) {
 	var (
 		quietOutputBuf   bytes.Buffer
 		verboseOutputBuf bytes.Buffer
+		jsonOutputBuf    bytes.Buffer
+		status           statusRecorder
+		jsonSummary      summary.Summary
 	)
 	err = Run(
 		Race(),
@@ -47,6 +56,9 @@ func Test_Run_allOptions(t *testing.T) {
 		P(1),
 		QuietOutput(&quietOutputBuf),
 		VerboseOutput(&verboseOutputBuf),
+		JSONOutput(&jsonOutputBuf),
+		Progress(&status, time.Minute),
+		JSONSummary(&jsonSummary),
 	)
 	require.NoError(t, err)
 	var (
@@ -57,6 +69,20 @@ func Test_Run_allOptions(t *testing.T) {
 	require.Contains(t, verboseOutput, expectedTestOutput)
 	require.Contains(t, quietOutput, expectedPackageOutput)
 	require.Contains(t, verboseOutput, expectedPackageOutput)
+	require.Contains(t, jsonOutputBuf.String(), `"Action":"pass","Package":"`+expectedPackage+`","Test":"Test_Some_test"`)
+	statuses := status.all()
+	require.GreaterOrEqual(t, len(statuses), 2)
+	require.True(t, strings.HasPrefix(statuses[len(statuses)-2], "1/1 packages, 1 passed, 0 failed, 0 skipped, "))
+	require.Equal(t, "", statuses[len(statuses)-1])
+
+	require.Regexp(t, `^go\d`, jsonSummary.GoVersion)
+	require.Equal(
+		t,
+		[]string{"test", "-v", "-json", "-race", "-coverprofile=" + covPath, "-coverpkg=./...", "-covermode=atomic", "-p=1", "./..."},
+		jsonSummary.GoTestArgs,
+	)
+	require.Len(t, jsonSummary.Packages, 1)
+	require.Equal(t, expectedPackage, jsonSummary.Packages[0].ImportPath)
 
 	cov, err := os.ReadFile(covPath)
 	require.NoError(t, err)
@@ -72,23 +98,193 @@ func Test_Run_fail(t *testing.T) {
 	popd := pushd(t, "testdata")
 	defer popd()
 	err := Run()
-	require.Error(t, err)
+	require.EqualError(t, err, "1 package failed")
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.NotErrorIs(t, err, ErrBuildFailed)
+}
+
+func Test_Run_env(t *testing.T) {
+	popd := pushd(t, "testdata")
+	defer popd()
+	err := Run(Env("GOTEST_FAIL=1"))
+	require.ErrorIs(t, err, ErrTestsFailed)
+}
+
+func Test_Run_flagsAndPackages(t *testing.T) {
+	popd := pushd(t, "testdata")
+	defer popd()
+	var (
+		quietOutputBuf bytes.Buffer
+		jsonSummary    summary.Summary
+	)
+	err := Run(Flags("-count=1"), Packages("."), QuietOutput(&quietOutputBuf), JSONSummary(&jsonSummary))
+	require.NoError(t, err)
+	require.Equal(t, []string{"test", "-v", "-json", "-count=1", "."}, jsonSummary.GoTestArgs)
+	require.NotContains(t, quietOutputBuf.String(), "(cached)")
+}
+
+func Test_options_goListArgs(t *testing.T) {
+	o := options{
+		race:     true,
+		flags:    []string{"-count=1", "-tags=integration", "-run", "TestFoo", "-mod", "vendor"},
+		packages: []string{"./a/..."},
+	}
+	require.Equal(t, []string{"-race", "-tags=integration", "-mod", "vendor", "./a/..."}, o.goListArgs())
+	require.Equal(t, []string{"./..."}, (&options{}).goListArgs())
+}
+
+func Test_options_packageDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	o := options{packages: []string{"./testdata"}}
+	dir, err := o.packageDir("oss.indeed.com/go/go-opine/internal/gotest/testdata")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(wd, "testdata"), dir)
+	// Packages that are not tested are not listed.
+	dir, err = o.packageDir("oss.indeed.com/go/go-opine/internal/gotest")
+	require.NoError(t, err)
+	require.Empty(t, dir)
+}
+
+func Test_Report(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "attrs.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var (
+		quietOutputBuf bytes.Buffer
+		report         junit.Testsuites
+	)
+	err = Report(f, Race(), QuietOutput(&quietOutputBuf), JUnitReport(&report))
+	require.EqualError(t, err, "1 package failed")
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.NotErrorIs(t, err, ErrBuildFailed)
+	require.Contains(t, quietOutputBuf.String(), "--- FAIL: TestArtifacts")
+	require.Len(t, report.Suites, 1)
+}
+
+func Test_Report_pass(t *testing.T) {
+	events := `{"Action":"run","Package":"pkg","Test":"TestFoo"}
+{"Action":"pass","Package":"pkg","Test":"TestFoo"}
+{"Action":"pass","Package":"pkg"}
+`
+	require.NoError(t, Report(strings.NewReader(events)))
+}
+
+func Test_Report_buildWarnings(t *testing.T) {
+	for _, warnings := range []bool{false, true} {
+		f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		var quietOutputBuf bytes.Buffer
+		opts := []Option{QuietOutput(&quietOutputBuf)}
+		if warnings {
+			opts = append(opts, BuildWarnings())
+		}
+		err = Report(f, opts...)
+		require.EqualError(t, err, "3 packages failed")
+		require.ErrorIs(t, err, ErrTestsFailed)
+		require.ErrorIs(t, err, ErrBuildFailed)
+		require.Contains(t, quietOutputBuf.String(), "fx/dep/dep.go:2:23: undefined: undefined\n")
+		if warnings {
+			require.Contains(t, quietOutputBuf.String(), "# example.com/fx/warn.test\nld: warning: something is odd\nok  \texample.com/fx/warn\t")
+		} else {
+			require.NotContains(t, quietOutputBuf.String(), "ld: warning")
+		}
+	}
 }
 
-func Test_parseGoTestJSONOutput_parseError(t *testing.T) {
-	err := parseGoTestJSONOutput(strings.NewReader("NOT JSON!"), resultAccepterFunc(func(result) error { return nil }))
+func Test_Report_optionError(t *testing.T) {
+	err := Report(strings.NewReader(""), P(0))
 	require.Error(t, err)
 }
 
+func Test_packageFailures_Accept(t *testing.T) {
+	tested := newPackageFailures()
+	for _, res := range []result{
+		{Key: resultKey{Package: "a", Test: "TestFlaky"}, Outcome: testQuarantined},
+		{Key: resultKey{Package: "a"}, Outcome: "pass"},
+		{Key: resultKey{Package: "b", Test: "TestFoo"}, Outcome: "pass", Subtests: []result{
+			{Key: resultKey{Package: "b", Test: "TestFoo/flaky"}, Outcome: testQuarantined},
+		}},
+		{Key: resultKey{Package: "b"}, Outcome: testFailure},
+		{Key: resultKey{Package: "c"}, Outcome: "pass"},
+	} {
+		require.NoError(t, tested.Accept(res))
+	}
+	// Only package a passed because of its quarantined test, since b
+	// failed anyway.
+	require.Equal(t, 1, tested.count)
+	require.Equal(t, 1, tested.rescued)
+	require.Empty(t, tested.quarantined)
+}
+
+func Test_parseGoTestJSONOutput_notJSON(t *testing.T) {
+	const output = `{"Action":"start","Package":"oss.indeed.com/go/go-opine/internal/cmd"}
+NOT JSON!
+{"Action":"output","Package":"oss.indeed.com/go/go-opine/internal/cmd","Output":"ok  \toss.indeed.com/go/go-opine/internal/cmd\t0.1s\n"}
+{"Action":"pass","Package":"oss.indeed.com/go/go-opine/internal/cmd","Elapsed":0.1}
+`
+	var (
+		results []result
+		warn    bytes.Buffer
+	)
+	err := parseGoTestJSONOutput(
+		strings.NewReader(output),
+		resultAccepterFunc(func(res result) error { results = append(results, res); return nil }),
+		&warn,
+	)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "NOT JSON!\nok  \toss.indeed.com/go/go-opine/internal/cmd\t0.1s\n", results[0].Output)
+	require.Contains(t, warn.String(), "\"NOT JSON!\"")
+}
+
+func Test_parseGoTestJSONOutput_jsonNotEvent(t *testing.T) {
+	const output = `{"Action":"start","Package":"oss.indeed.com/go/go-opine/internal/cmd"}
+{"level":"info","msg":"connected to db"}
+{"Action":"pass","Package":"oss.indeed.com/go/go-opine/internal/cmd","Elapsed":0.1}
+`
+	var (
+		results []result
+		warn    bytes.Buffer
+	)
+	err := parseGoTestJSONOutput(
+		strings.NewReader(output),
+		resultAccepterFunc(func(res result) error { results = append(results, res); return nil }),
+		&warn,
+	)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "{\"level\":\"info\",\"msg\":\"connected to db\"}\n", results[0].Output)
+	require.Contains(t, warn.String(), "connected to db")
+}
+
+func Test_parseGoTestJSONOutput_observers(t *testing.T) {
+	const output = `{"Action":"start","Package":"oss.indeed.com/go/go-opine/internal/cmd"}
+{"Action":"pass","Package":"oss.indeed.com/go/go-opine/internal/cmd","Elapsed":0.1}
+`
+	var actions []string
+	err := parseGoTestJSONOutput(
+		strings.NewReader(output),
+		resultAccepterFunc(func(result) error { return nil }),
+		io.Discard,
+		eventAccepterFunc(func(e event) error { actions = append(actions, e.Action); return nil }),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"start", "pass"}, actions)
+}
+
 func Test_parseGoTestJSONOutput_unconsumedEvents(t *testing.T) {
 	const eventJSON = `{"Time":"2019-09-26T13:27:17.563229183Z","Action":"output","Package":"oss.indeed.com/go/go-opine/internal/cmd","Test":"Test_testCmd_impl","Output":"--- PASS: Test_testCmd_impl (1.93s)\n"}`
-	err := parseGoTestJSONOutput(strings.NewReader(eventJSON), resultAccepterFunc(func(result) error { return nil }))
+	err := parseGoTestJSONOutput(strings.NewReader(eventJSON), resultAccepterFunc(func(result) error { return nil }), io.Discard)
 	require.Error(t, err)
 }
 
 func Test_parseGoTestJSONOutput_unconsumedResults(t *testing.T) {
 	const eventJSON = `{"Time":"2019-09-26T13:27:17.56324465Z","Action":"pass","Package":"oss.indeed.com/go/go-opine/internal/cmd","Test":"Test_testCmd_impl","Elapsed":1.93}`
-	err := parseGoTestJSONOutput(strings.NewReader(eventJSON), resultAccepterFunc(func(result) error { return nil }))
+	err := parseGoTestJSONOutput(strings.NewReader(eventJSON), resultAccepterFunc(func(result) error { return nil }), io.Discard)
 	require.Error(t, err)
 }
 
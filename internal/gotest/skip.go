@@ -0,0 +1,126 @@
+package gotest
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TestCount is the number of tests run in a package, and which of them
+// were skipped.
+type TestCount struct {
+	Package string
+	// Outcome is the outcome of the package: "pass", "fail", or "skip"
+	// (e.g. if it has no test files).
+	Outcome string
+	// Tests is the number of tests run, including subtests and skipped
+	// tests.
+	Tests   int
+	Skipped []SkippedTest
+}
+
+// SkippedTest is a test that was skipped.
+type SkippedTest struct {
+	// Test is the full name of the test (e.g. "TestFoo/case_1").
+	Test string
+	// Reason is the message the test was skipped with (e.g. by t.Skip), or
+	// empty if there is none (e.g. t.SkipNow).
+	Reason string
+}
+
+// logCallRegexp matches a call of testing.T.Log or testing.T.Logf.
+var logCallRegexp = regexp.MustCompile(`\.Logf?\(`)
+
+// testCounter is a resultAccepter that counts the tests of each package,
+// and the tests that were skipped with their reasons.
+type testCounter struct {
+	to         *[]TestCount
+	cur        TestCount
+	packageDir func(pkg string) (string, error)
+}
+
+var _ resultAccepter = (*testCounter)(nil)
+
+// newTestCounter returns a testCounter that adds to the provided slice and
+// finds the directory of each package with packageDir.
+func newTestCounter(to *[]TestCount, packageDir func(pkg string) (string, error)) *testCounter {
+	return &testCounter{to: to, packageDir: packageDir}
+}
+
+func (c *testCounter) Accept(res result) error {
+	switch {
+	case res.Key.ImportPath != "":
+	case res.Key.Test != "":
+		res.walk(func(res result) {
+			c.cur.Tests++
+			if res.Outcome == "skip" {
+				c.cur.Skipped = append(c.cur.Skipped, SkippedTest{Test: res.Key.Test, Reason: skipReason(res, c.dir)})
+			}
+		})
+	case res.Key.Package != "":
+		c.cur.Package = res.Key.Package
+		c.cur.Outcome = res.Outcome
+		*c.to = append(*c.to, c.cur)
+		c.cur = TestCount{}
+	}
+	return nil
+}
+
+// dir returns the directory of the package, or empty if it cannot be
+// found.
+func (c *testCounter) dir(pkg string) string {
+	dir, _ := c.packageDir(pkg)
+	return dir
+}
+
+// skipReason returns the reason a test was skipped: the message logged
+// just before it was skipped (with t.Skip or t.Skipf), without its
+// location. The reason is empty if the test was skipped with t.SkipNow,
+// which is recognized by the last message being followed by other output
+// or having been logged by a call of t.Log or t.Logf. The latter is only
+// known if the source of the test can be read from the directory that
+// packageDir returns for the package of the test.
+func skipReason(res result, packageDir func(pkg string) string) string {
+	var (
+		message []string
+		indent  string
+		file    string
+		line    int
+	)
+	for _, l := range strings.Split(strings.TrimSuffix(removeFrames(res.Output), "\n"), "\n") {
+		if m := testLogLocationRegexp.FindStringSubmatch(l); m != nil {
+			message, indent = []string{m[4]}, m[1]
+			file = m[2]
+			line, _ = strconv.Atoi(m[3])
+			continue
+		}
+		if message != nil && strings.HasPrefix(l, indent+" ") {
+			message = append(message, strings.TrimSpace(l))
+		} else {
+			message = nil
+		}
+	}
+	if message == nil {
+		return ""
+	}
+	if dir := packageDir(res.Key.Package); dir != "" && logCallRegexp.MatchString(sourceLine(filepath.Join(dir, file), line)) {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(message, " "))
+}
+
+// sourceLine returns the line with the provided number (starting at 1) of
+// the file, or empty if it cannot be read.
+func sourceLine(path string, n int) string {
+	content, err := os.ReadFile(path)
+	if err != nil || n < 1 {
+		return ""
+	}
+	lines := strings.Split(string(content), "\n")
+	if n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
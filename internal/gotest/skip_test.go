@@ -0,0 +1,80 @@
+package gotest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Report_testCounts(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var counts []TestCount
+	require.ErrorIs(t, Report(f, TestCounts(&counts)), ErrTestsFailed)
+	require.Equal(
+		t,
+		[]TestCount{{
+			Package: "example.com/fx/subtests",
+			Outcome: "fail",
+			Tests:   6,
+			Skipped: []SkippedTest{
+				{Test: "TestTable/a/deep", Reason: "not today"},
+				{Test: "TestTable/b/deep", Reason: "not today"},
+			},
+		}},
+		counts,
+	)
+}
+
+func Test_skipReason(t *testing.T) {
+	noDir := func(string) string { return "" }
+	for _, tc := range []struct {
+		output, expected string
+	}{
+		{"=== RUN   TestFoo\n--- SKIP: TestFoo (0.00s)\n", ""},
+		{"=== RUN   TestFoo\n    foo_test.go:12: needs a database\n--- SKIP: TestFoo (0.00s)\n", "needs a database"},
+		{"=== RUN   TestFoo\n    foo_test.go:10: starting\n    foo_test.go:12: flaky,\n        see ABC-123\n--- SKIP: TestFoo (0.00s)\n", "flaky, see ABC-123"},
+		{"=== RUN   TestFoo\nprinted by the test\n--- SKIP: TestFoo (0.00s)\n", ""},
+		{"=== RUN   TestFoo\n    foo_test.go:12: setup done\nprinted by the test\n--- SKIP: TestFoo (0.00s)\n", ""},
+	} {
+		res := result{Key: resultKey{Test: "TestFoo"}, Outcome: "skip", Output: tc.output}
+		require.Equal(t, tc.expected, skipReason(res, noDir), tc.output)
+	}
+}
+
+func Test_skipReason_logThenSkipNow(t *testing.T) {
+	tmp := t.TempDir()
+	source := `package foo
+
+import "testing"
+
+func TestLogThenSkipNow(t *testing.T) {
+	t.Log("setup done")
+	t.SkipNow()
+}
+
+func TestSkip(t *testing.T) {
+	t.Skip("needs a database")
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "foo_test.go"), []byte(source), 0600))
+	dir := func(string) string { return tmp }
+
+	res := result{
+		Key:     resultKey{Test: "TestLogThenSkipNow"},
+		Outcome: "skip",
+		Output:  "=== RUN   TestLogThenSkipNow\n    foo_test.go:6: setup done\n--- SKIP: TestLogThenSkipNow (0.00s)\n",
+	}
+	require.Empty(t, skipReason(res, dir))
+
+	res = result{
+		Key:     resultKey{Test: "TestSkip"},
+		Outcome: "skip",
+		Output:  "=== RUN   TestSkip\n    foo_test.go:11: needs a database\n--- SKIP: TestSkip (0.00s)\n",
+	}
+	require.Equal(t, "needs a database", skipReason(res, dir))
+}
@@ -0,0 +1,146 @@
+package gotest
+
+import (
+	"regexp"
+	"strings"
+)
+
+// frameLineRegexp matches the lines "go test -v" prints to frame the
+// output of each test (e.g. "=== RUN   TestFoo" or "--- FAIL: TestFoo (0.01s)").
+// The second group is the test name.
+var frameLineRegexp = regexp.MustCompile(`^\s*(?:=== (RUN|PAUSE|CONT|NAME|ATTR|ARTIFACTS) +|--- (?:PASS|FAIL|SKIP|BENCH): )(\S+)`)
+
+// nestSubtests returns the top level test results from the provided
+// results of a single package, with the subtests of each result in
+// Subtests (recursively). The order of the results is preserved.
+//
+// A subtest is identified by its name: "TestFoo/bar" is a subtest of
+// "TestFoo". If the parent of a subtest is not in the results the subtest
+// is treated as a top level test.
+func nestSubtests(results []result) []result {
+	names := make(map[string]bool, len(results))
+	for _, res := range results {
+		names[res.Key.Test] = true
+	}
+
+	var top []result
+	children := make(map[string][]result)
+	for _, res := range results {
+		if parent, ok := parentTest(res.Key.Test, names); ok {
+			children[parent] = append(children[parent], res)
+		} else {
+			top = append(top, res)
+		}
+	}
+
+	var nest func(res result) result
+	nest = func(res result) result {
+		for _, child := range children[res.Key.Test] {
+			res.Subtests = append(res.Subtests, nest(child))
+		}
+		if len(res.Subtests) > 0 {
+			res.Output = removeSubtestFrames(res.Output, res.Key.Test)
+		}
+		return res
+	}
+	for i := range top {
+		top[i] = nest(top[i])
+	}
+	return top
+}
+
+// parentTest returns the closest ancestor of the named test that is in
+// names. Since subtest names may themselves contain a "/" the closest
+// ancestor is not necessarily the direct parent.
+func parentTest(test string, names map[string]bool) (string, bool) {
+	for i := strings.LastIndexByte(test, '/'); i > 0; i = strings.LastIndexByte(test[:i], '/') {
+		if names[test[:i]] {
+			return test[:i], true
+		}
+	}
+	return "", false
+}
+
+// removeSubtestFrames removes the framing lines of subtests of the named
+// test from its output. Older versions of Go attribute some subtest
+// framing lines (e.g. "    --- FAIL: TestFoo/bar") to the parent.
+func removeSubtestFrames(output, test string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(output, "\n") {
+		if m := frameLineRegexp.FindStringSubmatch(line); m != nil && strings.HasPrefix(m[2], test+"/") {
+			continue
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// removeFrames removes all framing lines from the output, leaving only
+// what the test itself printed.
+func removeFrames(output string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(output, "\n") {
+		if !frameLineRegexp.MatchString(line) {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// nestedOutput returns the output of the result and of its subtests that
+// include returns true for, in the order "go test -v" prints them: the
+// output of subtests comes before the final "--- PASS", "--- FAIL", or
+// "--- SKIP" line of their parent.
+func (res result) nestedOutput(include func(result) bool) string {
+	head, tail := splitFinalFrame(res.Output, res.Key.Test)
+	var sb strings.Builder
+	sb.WriteString(head)
+	for _, subtest := range res.Subtests {
+		if include(subtest) {
+			sb.WriteString(subtest.nestedOutput(include))
+		}
+	}
+	sb.WriteString(tail)
+	return sb.String()
+}
+
+// splitFinalFrame splits the output of the named test just before the
+// line reporting the outcome of the test. If there is no such line
+// the tail is empty.
+func splitFinalFrame(output, test string) (head, tail string) {
+	for _, prefix := range []string{"--- PASS: ", "--- FAIL: ", "--- SKIP: ", "--- BENCH: "} {
+		frame := prefix + test + " "
+		if strings.HasPrefix(output, frame) {
+			return "", output
+		}
+		if i := strings.LastIndex(output, "\n"+frame); i >= 0 {
+			return output[:i+1], output[i+1:]
+		}
+	}
+	return output, ""
+}
+
+// hasFailedSubtest returns true iff any subtest of the result (at any
+// depth) failed.
+func (res result) hasFailedSubtest() bool {
+	for _, subtest := range res.Subtests {
+		if subtest.Outcome == testFailure || subtest.hasFailedSubtest() {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls fn for the result and then for each of its subtests (at any
+// depth), parents before children.
+func (res result) walk(fn func(result)) {
+	fn(res)
+	for _, subtest := range res.Subtests {
+		subtest.walk(fn)
+	}
+}
+
+// isFailed returns true iff the result failed.
+func isFailed(res result) bool {
+	return res.Outcome == testFailure
+}
@@ -0,0 +1,159 @@
+package gotest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nestSubtests(t *testing.T) {
+	var (
+		deep = result{Key: resultKey{Package: "pkg", Test: "TestFoo/a/deep"}}
+		a    = result{Key: resultKey{Package: "pkg", Test: "TestFoo/a"}}
+		b    = result{Key: resultKey{Package: "pkg", Test: "TestFoo/b"}}
+		foo  = result{Key: resultKey{Package: "pkg", Test: "TestFoo"}}
+		bar  = result{Key: resultKey{Package: "pkg", Test: "TestBar"}}
+	)
+	nestedA := a
+	nestedA.Subtests = []result{deep}
+	nestedFoo := foo
+	nestedFoo.Subtests = []result{nestedA, b}
+	require.Equal(t, []result{nestedFoo, bar}, nestSubtests([]result{deep, a, b, foo, bar}))
+}
+
+func Test_nestSubtests_missingParent(t *testing.T) {
+	orphan := result{Key: resultKey{Package: "pkg", Test: "TestFoo/a"}}
+	require.Equal(t, []result{orphan}, nestSubtests([]result{orphan}))
+}
+
+func Test_parentTest(t *testing.T) {
+	names := map[string]bool{"TestFoo": true, "TestFoo/a/b": true}
+	parent, ok := parentTest("TestFoo/a/b/c", names)
+	require.True(t, ok)
+	require.Equal(t, "TestFoo/a/b", parent)
+	parent, ok = parentTest("TestFoo/x/y", names)
+	require.True(t, ok)
+	require.Equal(t, "TestFoo", parent)
+	_, ok = parentTest("TestFoo", names)
+	require.False(t, ok)
+}
+
+func Test_removeSubtestFrames(t *testing.T) {
+	const output = "=== RUN   TestFoo\n" +
+		"    foo_test.go:10: parent log\n" +
+		"--- FAIL: TestFoo (0.00s)\n" +
+		"    --- FAIL: TestFoo/a (0.00s)\n" +
+		"    --- PASS: TestFooBar (0.00s)\n"
+	require.Equal(
+		t,
+		"=== RUN   TestFoo\n    foo_test.go:10: parent log\n--- FAIL: TestFoo (0.00s)\n    --- PASS: TestFooBar (0.00s)\n",
+		removeSubtestFrames(output, "TestFoo"),
+	)
+}
+
+func Test_removeFrames(t *testing.T) {
+	const output = "=== RUN   TestFoo\n=== PAUSE TestFoo\n=== CONT  TestFoo\n    foo_test.go:10: not today\n--- SKIP: TestFoo (0.00s)\n"
+	require.Equal(t, "    foo_test.go:10: not today\n", removeFrames(output))
+}
+
+func Test_splitFinalFrame(t *testing.T) {
+	head, tail := splitFinalFrame("=== RUN   TestFoo\nlog\n--- FAIL: TestFoo (0.00s)\n", "TestFoo")
+	require.Equal(t, "=== RUN   TestFoo\nlog\n", head)
+	require.Equal(t, "--- FAIL: TestFoo (0.00s)\n", tail)
+
+	head, tail = splitFinalFrame("--- PASS: TestFoo (0.00s)\n", "TestFoo")
+	require.Empty(t, head)
+	require.Equal(t, "--- PASS: TestFoo (0.00s)\n", tail)
+
+	head, tail = splitFinalFrame("=== RUN   TestFoo\n", "TestFoo")
+	require.Equal(t, "=== RUN   TestFoo\n", head)
+	require.Empty(t, tail)
+}
+
+func Test_result_nestedOutput(t *testing.T) {
+	res := result{
+		Key:     resultKey{Test: "TestFoo"},
+		Outcome: "fail",
+		Output:  "=== RUN   TestFoo\n--- FAIL: TestFoo (0.00s)\n",
+		Subtests: []result{
+			{
+				Key:     resultKey{Test: "TestFoo/a"},
+				Outcome: "pass",
+				Output:  "=== RUN   TestFoo/a\n--- PASS: TestFoo/a (0.00s)\n",
+			},
+			{
+				Key:     resultKey{Test: "TestFoo/b"},
+				Outcome: "fail",
+				Output:  "=== RUN   TestFoo/b\n    bad\n--- FAIL: TestFoo/b (0.00s)\n",
+			},
+		},
+	}
+	require.Equal(
+		t,
+		"=== RUN   TestFoo\n=== RUN   TestFoo/b\n    bad\n--- FAIL: TestFoo/b (0.00s)\n--- FAIL: TestFoo (0.00s)\n",
+		res.nestedOutput(isFailed),
+	)
+	require.True(t, res.hasFailedSubtest())
+	require.False(t, res.Subtests[0].hasFailedSubtest())
+
+	var walked []string
+	res.walk(func(r result) { walked = append(walked, r.Key.Test) })
+	require.Equal(t, []string{"TestFoo", "TestFoo/a", "TestFoo/b"}, walked)
+}
+
+func Test_parseGoTestJSONOutput_subtests(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var (
+		quiet   bytes.Buffer
+		verbose bytes.Buffer
+	)
+	err = parseGoTestJSONOutput(f, newMultiResultAccepter(newQuietOutput(&quiet), newVerboseOutput(&verbose)), io.Discard)
+	require.NoError(t, err)
+
+	// The failed subtest is reported once, nested in its parent, and
+	// passing or skipped subtests are not reported at all.
+	require.Equal(
+		t,
+		"=== RUN   TestTable\n"+
+			"=== RUN   TestTable/b\n"+
+			"    subtests_test.go:8: in b\n"+
+			"    subtests_test.go:10: bad\n"+
+			"--- FAIL: TestTable/b (0.00s)\n"+
+			"--- FAIL: TestTable (0.00s)\n"+
+			"FAIL\n"+
+			"FAIL\texample.com/fx/subtests\t0.003s\n",
+		quiet.String(),
+	)
+
+	// The verbose output is in the same order "go test -v" prints it.
+	require.Equal(
+		t,
+		"=== RUN   TestTable\n"+
+			"=== RUN   TestTable/a\n"+
+			"    subtests_test.go:8: in a\n"+
+			"=== RUN   TestTable/a/deep\n"+
+			"    subtests_test.go:12: not today\n"+
+			"--- SKIP: TestTable/a/deep (0.00s)\n"+
+			"--- PASS: TestTable/a (0.00s)\n"+
+			"=== RUN   TestTable/b\n"+
+			"    subtests_test.go:8: in b\n"+
+			"    subtests_test.go:10: bad\n"+
+			"=== RUN   TestTable/b/deep\n"+
+			"    subtests_test.go:12: not today\n"+
+			"--- SKIP: TestTable/b/deep (0.00s)\n"+
+			"--- FAIL: TestTable/b (0.00s)\n"+
+			"--- FAIL: TestTable (0.00s)\n"+
+			"=== RUN   TestFlat\n"+
+			"--- PASS: TestFlat (0.00s)\n"+
+			"FAIL\n"+
+			"FAIL\texample.com/fx/subtests\t0.003s\n",
+		verbose.String(),
+	)
+}
@@ -0,0 +1,214 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxSummaryLines is the maximum number of output lines shown for each
+// failure in the failure summary.
+const maxSummaryLines = 5
+
+// failure is a failed test, package, or build in the failure summary.
+type failure struct {
+	res result
+	// pkg is the import path of the package to reproduce the failure in.
+	pkg string
+	// run is the -run flag value that reproduces the failure, or empty to
+	// run all tests.
+	run string
+	// output is the relevant output of the failure.
+	output string
+}
+
+// failureSummary is a resultAccepter that collects failed tests and build
+// failures and writes a summary of them to an io.Writer when finished,
+// with the location of the test function (if known) and a command to
+// reproduce each.
+//
+// Like in the JUnit report, a test that only failed because a subtest
+// failed is not listed since the subtest is. A failed package is only
+// listed if none of its tests failed and it did not fail because a build
+// failed, in which case the build named by its FailedBuild is listed
+// instead. Other failed builds (e.g. of the package itself rather than
+// its test binary, which fail along with it with -coverpkg) are not
+// listed, so that each build failure is only listed once.
+type failureSummary struct {
+	to         io.Writer
+	flags      func() []string
+	packageDir func(pkg string) (string, error)
+	failures   []failure
+	failedPkgs map[string]bool
+	// builds are the failed builds by import path, until a package that
+	// failed because of them is listed.
+	builds map[string]result
+}
+
+var (
+	_ resultAccepter = (*failureSummary)(nil)
+	_ resultFinisher = (*failureSummary)(nil)
+)
+
+// newFailureSummary returns a failureSummary that writes to the provided
+// io.Writer. The flags returns the "go test" flags (e.g. "-race") to
+// include in the reproduction commands, and packageDir finds the
+// directory of each package.
+func newFailureSummary(to io.Writer, flags func() []string, packageDir func(pkg string) (string, error)) *failureSummary {
+	return &failureSummary{
+		to:         to,
+		flags:      flags,
+		packageDir: packageDir,
+		failedPkgs: make(map[string]bool),
+		builds:     make(map[string]result),
+	}
+}
+
+func (s *failureSummary) Accept(res result) error {
+	switch {
+	case res.Key.ImportPath != "":
+		if res.Outcome == buildFailure {
+			s.builds[res.Key.ImportPath] = res
+		}
+	case res.Key.Test != "":
+		res.walk(func(res result) {
+			if res.Outcome != testFailure || res.hasFailedSubtest() {
+				return
+			}
+			s.failedPkgs[res.Key.Package] = true
+			output := res.ErrorOutput
+			if output == "" {
+				output = removeFrames(res.Output)
+			}
+			s.failures = append(s.failures, failure{res: res, pkg: res.Key.Package, run: runRegexp(res.Key.Test), output: output})
+		})
+	case res.Key.Package != "":
+		switch {
+		case res.Outcome != testFailure || s.failedPkgs[res.Key.Package]:
+		case res.FailedBuild != "":
+			if build, ok := s.builds[res.FailedBuild]; ok {
+				delete(s.builds, res.FailedBuild)
+				s.failures = append(s.failures, failure{res: build, pkg: buildPackage(build.Key.ImportPath), run: "^$", output: build.Output})
+			}
+		default:
+			s.failures = append(s.failures, failure{res: res, pkg: res.Key.Package, output: res.Output})
+		}
+		delete(s.failedPkgs, res.Key.Package)
+	}
+	return nil
+}
+
+// Finish writes the summary of the failures. Nothing is written if there
+// were no failures.
+func (s *failureSummary) Finish() error {
+	if len(s.failures) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	if len(s.failures) == 1 {
+		sb.WriteString("\n1 failure:\n")
+	} else {
+		fmt.Fprintf(&sb, "\n%d failures:\n", len(s.failures))
+	}
+	for i, f := range s.failures {
+		fmt.Fprintf(&sb, "\n%d. %s\n", i+1, failureHeader(f.res))
+		if loc := sourceLocation(f.res); loc != "" {
+			sb.WriteString("   at " + loc + "\n")
+		}
+		for _, line := range summaryLines(f.output) {
+			sb.WriteString("   " + line + "\n")
+		}
+		sb.WriteString("   " + s.reproduce(f) + "\n")
+	}
+	_, err := io.WriteString(s.to, sb.String())
+	return err
+}
+
+// failureHeader returns the line that introduces a failure, e.g.
+// "example.com/pkg.TestFoo (1.23s): timed out after 10m0s".
+func failureHeader(res result) string {
+	if res.Key.ImportPath != "" {
+		return buildPackage(res.Key.ImportPath) + ": " + buildFailedMessage(res)
+	}
+	header := fmt.Sprintf("%s (%.2fs)", resultName(res), res.Elapsed.Seconds())
+	switch {
+	case res.Reason != "":
+		header += ": " + res.Reason
+	case len(res.Races) > 0:
+		header += ": data race detected"
+	}
+	return header
+}
+
+// summaryLines returns the first non-blank lines of the output, without
+// their common indentation.
+func summaryLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	truncated := len(lines) > maxSummaryLines
+	if truncated {
+		lines = lines[:maxSummaryLines]
+	}
+	indent := ""
+	for i, line := range lines {
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if i == 0 || strings.HasPrefix(indent, lineIndent) {
+			indent = lineIndent
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+	}
+	if truncated {
+		lines = append(lines, "...")
+	}
+	return lines
+}
+
+// reproduce returns the "go test" command that reproduces the failure.
+func (s *failureSummary) reproduce(f failure) string {
+	args := append([]string{"go", "test"}, s.flags()...)
+	if f.run != "" {
+		args = append(args, "-run", shellQuote(f.run))
+	}
+	return strings.Join(append(args, s.packagePath(f.pkg)), " ")
+}
+
+// packagePath returns the path of the package relative to the working
+// directory (e.g. "./pkg/bar"), or its import path if that is not
+// possible.
+func (s *failureSummary) packagePath(pkg string) string {
+	dir, err := s.packageDir(pkg)
+	if err != nil || dir == "" {
+		return pkg
+	}
+	switch rel := relativePath(dir); {
+	case filepath.IsAbs(rel):
+		return pkg
+	case rel == ".":
+		return rel
+	default:
+		return "./" + rel
+	}
+}
+
+// runRegexp returns the -run flag value that matches only the named test
+// (e.g. "^TestFoo$/^case_1$" for "TestFoo/case_1").
+func runRegexp(test string) string {
+	elems := strings.Split(test, "/")
+	for i, elem := range elems {
+		elems[i] = "^" + regexp.QuoteMeta(elem) + "$"
+	}
+	return strings.Join(elems, "/")
+}
+
+// shellQuote quotes the string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
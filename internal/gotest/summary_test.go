@@ -0,0 +1,270 @@
+package gotest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFailureSummary(t *testing.T, to io.Writer) *failureSummary {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	return newFailureSummary(to, func() []string { return []string{"-race"} }, func(pkg string) (string, error) {
+		return strings.Replace(pkg, "example.com/fx", filepath.Join(wd, "fx"), 1), nil
+	})
+}
+
+func Test_failureSummary(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestFailureSummary(t, &out), io.Discard)
+	require.NoError(t, err)
+	// TestTable is not listed since it only failed because TestTable/b
+	// failed, and neither is the package since its test failed.
+	require.Equal(
+		t,
+		"\n1 failure:\n\n"+
+			"1. example.com/fx/subtests.TestTable/b (0.00s)\n"+
+			"   subtests_test.go:10: bad\n"+
+			"   go test -race -run '^TestTable$/^b$' ./fx/subtests\n",
+		out.String(),
+	)
+}
+
+func Test_failureSummary_race(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "race.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestFailureSummary(t, &out), io.Discard)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "\n1. example.com/fx/race.TestRacyA (0.00s): data race detected\n")
+}
+
+func Test_failureSummary_timeout(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "timeout.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestFailureSummary(t, &out), io.Discard)
+	require.NoError(t, err)
+	require.Contains(
+		t,
+		out.String(),
+		"\n2 failures:\n\n"+
+			"1. example.com/fx/hang.TestHang/sub (1.00s): timed out after 1s\n"+
+			"   test timed out after 1s\n"+
+			"   goroutines running TestHang/sub:\n"+
+			"       goroutine 10 [sleep]:\n",
+	)
+	require.Contains(t, out.String(), "   ...\n   go test -race -run '^TestHang$/^sub$' ./fx/hang\n\n2. ")
+}
+
+func Test_failureSummary_buildAndPackageFailures(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestFailureSummary(t, &out)
+	tested.flags = func() []string { return nil }
+	for _, res := range []result{
+		{
+			Key:     resultKey{ImportPath: "example.com/fx/a [example.com/fx/a.test]"},
+			Outcome: "build-fail",
+			Output:  "# example.com/fx/a\n./a.go:5:2: undefined: b\n",
+		},
+		{
+			Key:         resultKey{Package: "example.com/fx/a"},
+			Outcome:     testFailure,
+			Output:      "FAIL\texample.com/fx/a [build failed]\n",
+			FailedBuild: "example.com/fx/a [example.com/fx/a.test]",
+		},
+		{
+			Key:     resultKey{Package: "example.com/fx/b", Test: "TestOK"},
+			Outcome: "pass",
+		},
+		{
+			Key:     resultKey{Package: "example.com/fx/b"},
+			Outcome: testFailure,
+			Output:  "TestMain failed\nFAIL\texample.com/fx/b\t0.01s\n",
+			Elapsed: 10 * time.Millisecond,
+		},
+		{
+			Key:     resultKey{Package: "other.com/c"},
+			Outcome: testFailure,
+		},
+	} {
+		require.NoError(t, tested.Accept(res))
+	}
+	tested.packageDir = func(pkg string) (string, error) {
+		if strings.HasPrefix(pkg, "other.com/") {
+			return "/elsewhere/c", nil
+		}
+		wd, err := os.Getwd()
+		return strings.Replace(pkg, "example.com/fx", filepath.Join(wd, "fx"), 1), err
+	}
+	require.NoError(t, tested.Finish())
+	require.Equal(
+		t,
+		"\n3 failures:\n\n"+
+			"1. example.com/fx/a: build failed\n"+
+			"   # example.com/fx/a\n"+
+			"   ./a.go:5:2: undefined: b\n"+
+			"   go test -run '^$' ./fx/a\n\n"+
+			"2. example.com/fx/b (0.01s)\n"+
+			"   TestMain failed\n"+
+			"   FAIL\texample.com/fx/b\t0.01s\n"+
+			"   go test ./fx/b\n\n"+
+			"3. other.com/c (0.00s)\n"+
+			"   go test other.com/c\n",
+		out.String(),
+	)
+}
+
+func Test_failureSummary_buildFixture(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "build-fail.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTestFailureSummary(t, &out), io.Discard)
+	require.NoError(t, err)
+	// example.com/fx/a is not listed since it failed because the build of
+	// example.com/fx/dep failed, which is.
+	require.Equal(
+		t,
+		"\n2 failures:\n\n"+
+			"1. example.com/fx/dep: build failed\n"+
+			"   # example.com/fx/dep\n"+
+			"   fx/dep/dep.go:2:23: undefined: undefined\n"+
+			"   go test -race -run '^$' ./fx/dep\n\n"+
+			"2. example.com/fx/vet: vet failed\n"+
+			"   # example.com/fx/vet\n"+
+			"   # [example.com/fx/vet]\n"+
+			"   fx/vet/vet.go:3:24: fmt.Printf format %d has arg \"x\" of wrong type string\n"+
+			"   go test -race -run '^$' ./fx/vet\n",
+		out.String(),
+	)
+}
+
+func Test_failureSummary_buildFailedTwice(t *testing.T) {
+	// With -coverpkg a compile error fails both the package and its test
+	// binary, but the failure is only listed once.
+	var out bytes.Buffer
+	tested := newTestFailureSummary(t, &out)
+	tested.flags = func() []string { return nil }
+	for _, res := range []result{
+		{
+			Key:     resultKey{ImportPath: "example.com/fx/a [example.com/fx/a.test]"},
+			Outcome: "build-fail",
+			Output:  "# example.com/fx/a [example.com/fx/a.test]\n./a.go:5:2: undefined: b\n",
+		},
+		{
+			Key:     resultKey{ImportPath: "example.com/fx/a"},
+			Outcome: "build-fail",
+			Output:  "# example.com/fx/a\n./a.go:5:2: undefined: b\n",
+		},
+		{
+			Key:         resultKey{Package: "example.com/fx/a"},
+			Outcome:     testFailure,
+			FailedBuild: "example.com/fx/a [example.com/fx/a.test]",
+		},
+	} {
+		require.NoError(t, tested.Accept(res))
+	}
+	tested.packageDir = func(string) (string, error) { return "", errors.New("not found") }
+	require.NoError(t, tested.Finish())
+	require.Equal(
+		t,
+		"\n1 failure:\n\n"+
+			"1. example.com/fx/a: build failed\n"+
+			"   # example.com/fx/a [example.com/fx/a.test]\n"+
+			"   ./a.go:5:2: undefined: b\n"+
+			"   go test -run '^$' example.com/fx/a\n",
+		out.String(),
+	)
+}
+
+func Test_failureSummary_location(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	var out bytes.Buffer
+	tested := newTestFailureSummary(t, &out)
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/fx/pkg", Test: "TestFoo"},
+		Outcome: testFailure,
+		Reason:  "timed out after 1s",
+		File:    filepath.Join(wd, "fx", "pkg", "foo_test.go"),
+		Line:    12,
+	}))
+	require.NoError(t, tested.Finish())
+	require.Equal(
+		t,
+		"\n1 failure:\n\n"+
+			"1. example.com/fx/pkg.TestFoo (0.00s): timed out after 1s\n"+
+			"   at fx/pkg/foo_test.go:12\n"+
+			"   go test -race -run '^TestFoo$' ./fx/pkg\n",
+		out.String(),
+	)
+}
+
+func Test_failureSummary_Finish_noFailures(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTestFailureSummary(t, &out)
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "pkg", Test: "TestFoo"}, Outcome: "pass"}))
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "pkg"}, Outcome: "pass"}))
+	require.NoError(t, tested.Finish())
+	require.Empty(t, out.String())
+}
+
+func Test_failureSummary_Finish_error(t *testing.T) {
+	expectedErr := errors.New("blah")
+	tested := newTestFailureSummary(t, &errorWriter{err: expectedErr})
+	require.NoError(t, tested.Accept(result{Key: resultKey{Package: "pkg", Test: "TestFoo"}, Outcome: testFailure}))
+	require.Equal(t, expectedErr, tested.Finish())
+}
+
+func Test_failureSummary_packagePath(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	tested := newFailureSummary(io.Discard, nil, unknownPackageDir)
+	for dir, expected := range map[string]string{
+		wd:                          ".",
+		filepath.Join(wd, "a", "b"): "./a/b",
+		filepath.Dir(wd):            "pkg",
+		"":                          "pkg",
+	} {
+		tested.packageDir = func(string) (string, error) { return dir, nil }
+		require.Equal(t, expected, tested.packagePath("pkg"), dir)
+	}
+	tested.packageDir = func(string) (string, error) { return "", errors.New("blah") }
+	require.Equal(t, "pkg", tested.packagePath("pkg"))
+}
+
+func Test_summaryLines(t *testing.T) {
+	require.Equal(
+		t,
+		[]string{"a", "  b", "c", "d", "e", "..."},
+		summaryLines("\n    a\n      b\n\n    c\n    d\n    e\n    f\n"),
+	)
+	require.Nil(t, summaryLines("\n \n"))
+}
+
+func Test_runRegexp(t *testing.T) {
+	require.Equal(t, "^TestFoo$", runRegexp("TestFoo"))
+	require.Equal(t, `^TestFoo$/^case_1\.x$/^#01$`, runRegexp("TestFoo/case_1.x/#01"))
+}
+
+func Test_shellQuote(t *testing.T) {
+	require.Equal(t, `'^a$'`, shellQuote("^a$"))
+	require.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
@@ -0,0 +1,160 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tapIndent is the indentation of TAP subtests relative to their parent.
+const tapIndent = "    "
+
+// tapOutput is a resultAccepter that writes TAP version 14 (see
+// https://testanything.org/tap-version-14-specification.html).
+//
+// Each package is a test point with its tests in a subtest, and each test
+// with subtests is a test point with its subtests in a subtest. Skipped
+// tests have a SKIP directive and quarantined tests a TODO directive.
+// Failed and quarantined tests have a YAML diagnostic block with the
+// failure message, the duration, and the output. Since the number of
+// packages is not known in advance the plan is written when finished.
+type tapOutput struct {
+	to          io.Writer
+	started     bool
+	count       int
+	tests       []result
+	buildOutput map[string]string
+}
+
+var (
+	_ resultAccepter = (*tapOutput)(nil)
+	_ resultFinisher = (*tapOutput)(nil)
+)
+
+func newTAPOutput(to io.Writer) *tapOutput {
+	return &tapOutput{to: to, buildOutput: make(map[string]string)}
+}
+
+func (t *tapOutput) Accept(res result) error {
+	if res.Key.ImportPath != "" {
+		if res.Outcome == buildFailure {
+			t.buildOutput[res.Key.ImportPath] = res.Output
+		}
+		return nil
+	}
+	if res.Key.Test != "" {
+		t.tests = append(t.tests, res)
+		return nil
+	}
+	if res.Key.Package == "" {
+		return nil
+	}
+
+	var sb strings.Builder
+	if !t.started {
+		sb.WriteString("TAP version 14\n")
+		t.started = true
+	}
+	t.count++
+	writeTAPSubtest(&sb, "", res.Key.Package, t.tests, "")
+	writeTAPTestPoint(&sb, "", t.count, res.Key.Package, res, t.buildOutput[res.FailedBuild]+res.Output)
+	t.tests = nil
+	_, err := io.WriteString(t.to, sb.String())
+	return err
+}
+
+// Finish writes the plan.
+func (t *tapOutput) Finish() error {
+	var sb strings.Builder
+	if !t.started {
+		sb.WriteString("TAP version 14\n")
+	}
+	fmt.Fprintf(&sb, "1..%d\n", t.count)
+	_, err := io.WriteString(t.to, sb.String())
+	return err
+}
+
+// writeTAPSubtest writes a subtest named name containing a test point for
+// each of the tests, indented one level more than indent. The test points
+// are named relative to the parent test, if any. Nothing is written if
+// there are no tests.
+func writeTAPSubtest(sb *strings.Builder, indent, name string, tests []result, parent string) {
+	if len(tests) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "%s%s# Subtest: %s\n", indent, tapIndent, name)
+	for i, res := range tests {
+		testName := strings.TrimPrefix(res.Key.Test, parent+"/")
+		writeTAPSubtest(sb, indent+tapIndent, testName, res.Subtests, res.Key.Test)
+		writeTAPTestPoint(sb, indent+tapIndent, i+1, testName, res, removeFrames(res.Output))
+	}
+	fmt.Fprintf(sb, "%s%s1..%d\n", indent, tapIndent, len(tests))
+}
+
+// writeTAPTestPoint writes the test point for a test or package result. The
+// output is included in the diagnostics of a failure.
+func writeTAPTestPoint(sb *strings.Builder, indent string, n int, name string, res result, output string) {
+	status := "ok"
+	if isFailedOrQuarantined(res) {
+		status = "not ok"
+	}
+	fmt.Fprintf(sb, "%s%s %d - %s", indent, status, n, escapeTAPDescription(name))
+	switch res.Outcome {
+	case "skip":
+		sb.WriteString(" # SKIP")
+		if reason := tapSkipReason(res); reason != "" {
+			sb.WriteString(" " + escapeTAPDescription(reason))
+		}
+	case testQuarantined:
+		// A TODO test point that is not ok does not fail the test run.
+		sb.WriteString(" # TODO " + escapeTAPDescription("quarantined ("+res.Quarantine.String()+")"))
+	}
+	sb.WriteString("\n")
+	if !isFailedOrQuarantined(res) {
+		return
+	}
+
+	message := res.Reason
+	if message == "" {
+		message = "Failed"
+		if len(res.Races) > 0 {
+			message = "data race detected"
+		}
+	}
+	yamlIndent := indent + "  "
+	fmt.Fprintf(sb, "%s---\n", yamlIndent)
+	fmt.Fprintf(sb, "%smessage: %s\n", yamlIndent, strconv.Quote(message))
+	fmt.Fprintf(sb, "%sduration_ms: %s\n", yamlIndent, strconv.FormatFloat(float64(res.Elapsed)/float64(time.Millisecond), 'f', -1, 64))
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		// The indentation indicator is needed since the output may itself
+		// start with indentation.
+		fmt.Fprintf(sb, "%soutput: |2\n", yamlIndent)
+		for _, line := range strings.Split(output, "\n") {
+			fmt.Fprintf(sb, "%s  %s\n", yamlIndent, line)
+		}
+	}
+	fmt.Fprintf(sb, "%s...\n", yamlIndent)
+}
+
+// tapSkipReason returns the reason a test or package was skipped, on a
+// single line.
+func tapSkipReason(res result) string {
+	if res.Key.Test == "" {
+		if strings.Contains(res.Output, "[no test files]") {
+			return "no test files"
+		}
+		return ""
+	}
+	return strings.Join(strings.Fields(removeFrames(res.Output)), " ")
+}
+
+// tapDescriptionEscaper escapes the characters that have a special meaning
+// in the description of a TAP test point.
+var tapDescriptionEscaper = strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ")
+
+// escapeTAPDescription escapes a TAP test point description.
+func escapeTAPDescription(s string) string {
+	return tapDescriptionEscaper.Replace(s)
+}
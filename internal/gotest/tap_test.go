@@ -0,0 +1,140 @@
+package gotest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_tapOutput_Accept(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "subtests.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out bytes.Buffer
+	err = parseGoTestJSONOutput(f, newTAPOutput(&out), io.Discard)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"TAP version 14\n"+
+			"    # Subtest: example.com/fx/subtests\n"+
+			"        # Subtest: TestTable\n"+
+			"            # Subtest: a\n"+
+			"            ok 1 - deep # SKIP subtests_test.go:12: not today\n"+
+			"            1..1\n"+
+			"        ok 1 - a\n"+
+			"            # Subtest: b\n"+
+			"            ok 1 - deep # SKIP subtests_test.go:12: not today\n"+
+			"            1..1\n"+
+			"        not ok 2 - b\n"+
+			"          ---\n"+
+			"          message: \"Failed\"\n"+
+			"          duration_ms: 0\n"+
+			"          output: |2\n"+
+			"                subtests_test.go:8: in b\n"+
+			"                subtests_test.go:10: bad\n"+
+			"          ...\n"+
+			"        1..2\n"+
+			"    not ok 1 - TestTable\n"+
+			"      ---\n"+
+			"      message: \"Failed\"\n"+
+			"      duration_ms: 0\n"+
+			"      ...\n"+
+			"    ok 2 - TestFlat\n"+
+			"    1..2\n"+
+			"not ok 1 - example.com/fx/subtests\n"+
+			"  ---\n"+
+			"  message: \"Failed\"\n"+
+			"  duration_ms: 4\n"+
+			"  output: |2\n"+
+			"    FAIL\n"+
+			"    FAIL\texample.com/fx/subtests\t0.003s\n"+
+			"  ...\n"+
+			"1..1\n",
+		out.String(),
+	)
+}
+
+func Test_tapOutput_Finish_noPackages(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTAPOutput(&out)
+	require.NoError(t, tested.Finish())
+	require.Equal(t, "TAP version 14\n1..0\n", out.String())
+}
+
+func Test_tapOutput_Accept_noTestFiles(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTAPOutput(&out)
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/a"},
+		Outcome: "skip",
+		Output:  "?   \texample.com/a\t[no test files]\n",
+	}))
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/b", Test: "TestFoo#01"},
+		Outcome: "pass",
+		Output:  "=== RUN   TestFoo#01\n--- PASS: TestFoo#01 (0.00s)\n",
+	}))
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{Package: "example.com/b"},
+		Outcome: "pass",
+		Output:  "PASS\nok  \texample.com/b\t0.01s\n",
+	}))
+	require.NoError(t, tested.Finish())
+	require.Equal(
+		t,
+		"TAP version 14\n"+
+			"ok 1 - example.com/a # SKIP no test files\n"+
+			"    # Subtest: example.com/b\n"+
+			"    ok 1 - TestFoo\\#01\n"+
+			"    1..1\n"+
+			"ok 2 - example.com/b\n"+
+			"1..2\n",
+		out.String(),
+	)
+}
+
+func Test_tapOutput_Accept_buildFail(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTAPOutput(&out)
+	require.NoError(t, tested.Accept(result{
+		Key:     resultKey{ImportPath: "example.com/a [example.com/a.test]"},
+		Outcome: "build-fail",
+		Output:  "# example.com/a\n./a.go:5:2: undefined: b\n",
+	}))
+	require.NoError(t, tested.Accept(result{
+		Key:         resultKey{Package: "example.com/a"},
+		Outcome:     testFailure,
+		Output:      "FAIL\texample.com/a [build failed]\n",
+		Elapsed:     1500 * time.Microsecond,
+		FailedBuild: "example.com/a [example.com/a.test]",
+	}))
+	require.Equal(
+		t,
+		"TAP version 14\n"+
+			"not ok 1 - example.com/a\n"+
+			"  ---\n"+
+			"  message: \"Failed\"\n"+
+			"  duration_ms: 1.5\n"+
+			"  output: |2\n"+
+			"    # example.com/a\n"+
+			"    ./a.go:5:2: undefined: b\n"+
+			"    FAIL\texample.com/a [build failed]\n"+
+			"  ...\n",
+		out.String(),
+	)
+}
+
+func Test_tapOutput_Accept_error(t *testing.T) {
+	expectedErr := errors.New("blah")
+	tested := newTAPOutput(&errorWriter{err: expectedErr})
+	err := tested.Accept(result{Key: resultKey{Package: "pkg"}, Outcome: "pass"})
+	require.Equal(t, expectedErr, err)
+	require.Equal(t, expectedErr, tested.Finish())
+}
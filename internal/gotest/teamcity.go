@@ -0,0 +1,271 @@
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/teamcity"
+)
+
+// teamcityOutput is an eventAccepter that writes TeamCity service
+// messages as the events arrive, so that TeamCity shows a live tree of the
+// tests. Each package is a test suite containing its tests and subtests.
+// Every message of a package has the package as its flowId, so that
+// TeamCity can tell apart the messages of packages tested in parallel.
+//
+// A test is started when it starts running, and finished with its outcome
+// once it completes. Tests that are still running when their package
+// completes (e.g. because the test binary crashed) fail. A failed test
+// that is quarantined, or that failed only because of quarantined
+// subtests, is ignored instead. The attributes (see testing.T.Attr) and
+// artifact directory of a test are its metadata.
+type teamcityOutput struct {
+	to         io.Writer
+	quarantine func() []quarantinedTest
+	// builds is the output of each build by import path, and packages are
+	// the packages that started but did not complete.
+	builds   map[string]string
+	packages map[string]*teamcityPackage
+}
+
+// teamcityPackage is a package that started but did not complete.
+type teamcityPackage struct {
+	output string
+	// running are the tests of the package that started but did not
+	// complete.
+	running map[string]*teamcityTest
+}
+
+// teamcityTest is a test that started but did not complete.
+type teamcityTest struct {
+	output string
+	// failedSubtests and quarantinedSubtests are the number of subtests
+	// that failed and that failed but are quarantined.
+	failedSubtests      int
+	quarantinedSubtests []*QuarantinedTest
+}
+
+var _ eventAccepter = (*teamcityOutput)(nil)
+
+func newTeamCityOutput(to io.Writer, quarantine func() []quarantinedTest) *teamcityOutput {
+	return &teamcityOutput{
+		to:         to,
+		quarantine: quarantine,
+		builds:     make(map[string]string),
+		packages:   make(map[string]*teamcityPackage),
+	}
+}
+
+func (tc *teamcityOutput) Accept(e event) error {
+	if e.ImportPath != "" {
+		return tc.acceptBuild(e)
+	}
+	if e.Package == "" {
+		return nil
+	}
+	pkg, ok := tc.packages[e.Package]
+	if !ok {
+		// Older versions of Go do not send "start" events.
+		pkg = &teamcityPackage{running: make(map[string]*teamcityTest)}
+		tc.packages[e.Package] = pkg
+		if err := tc.write("testSuiteStarted", e.Package, teamcity.Attr{Name: "name", Value: e.Package}); err != nil {
+			return err
+		}
+	}
+	if e.Test == "" {
+		if e.Action == "output" {
+			pkg.output += e.Output
+		}
+		if !isTestOrPackageComplete(e.Action) {
+			return nil
+		}
+		delete(tc.packages, e.Package)
+		if err := tc.finishDanglingTests(e.Package, pkg); err != nil {
+			return err
+		}
+		return tc.write("testSuiteFinished", e.Package, teamcity.Attr{Name: "name", Value: e.Package})
+	}
+
+	test := pkg.running[e.Test]
+	switch e.Action {
+	case "run":
+		pkg.running[e.Test] = &teamcityTest{}
+		return tc.write("testStarted", e.Package, teamcity.Attr{Name: "name", Value: e.Test})
+	case "output":
+		if test != nil {
+			test.output += e.Output
+		}
+	case "attr":
+		return tc.write("testMetadata", e.Package, teamcity.Attr{Name: "testName", Value: e.Test},
+			teamcity.Attr{Name: "name", Value: e.Key},
+			teamcity.Attr{Name: "value", Value: e.Value},
+		)
+	case "artifacts":
+		return tc.write("testMetadata", e.Package, teamcity.Attr{Name: "testName", Value: e.Test},
+			teamcity.Attr{Name: "type", Value: "artifact"},
+			teamcity.Attr{Name: "value", Value: e.Path},
+		)
+	case "pass", testFailure, "skip":
+		if test == nil {
+			return nil
+		}
+		delete(pkg.running, e.Test)
+		elapsed := time.Duration(e.Elapsed * float64(time.Second))
+		return tc.finishTest(e.Package, pkg, e.Test, test, e.Action, "", elapsed)
+	}
+	return nil
+}
+
+// acceptBuild writes a build problem for a failed build.
+func (tc *teamcityOutput) acceptBuild(e event) error {
+	switch e.Action {
+	case "build-output":
+		tc.builds[e.ImportPath] += e.Output
+	case buildFailure:
+		res := result{Diagnostics: parseBuildDiagnostics(tc.builds[e.ImportPath])}
+		delete(tc.builds, e.ImportPath)
+		return tc.write("buildProblem", "",
+			teamcity.Attr{Name: "description", Value: buildFailedMessage(res) + ": " + buildPackage(e.ImportPath)},
+		)
+	}
+	return nil
+}
+
+// finishDanglingTests fails the tests of the package that are still
+// running when it completes, subtests before their parents.
+func (tc *teamcityOutput) finishDanglingTests(pkgName string, pkg *teamcityPackage) error {
+	if len(pkg.running) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(pkg.running))
+	outputs := make([]string, 0, len(pkg.running)+1)
+	for name := range pkg.running {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := strings.Count(names[i], "/"), strings.Count(names[j], "/")
+		if di != dj {
+			return di > dj
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		outputs = append(outputs, pkg.running[name].output)
+	}
+	outputs = append(outputs, pkg.output)
+
+	reason := findTestCrash(outputs...).Reason
+	if timeout, _, _, ok := findTimeoutIn(outputs); ok {
+		reason = "timed out after " + timeout.After
+	}
+	for _, name := range names {
+		test := pkg.running[name]
+		delete(pkg.running, name)
+		if err := tc.finishTest(pkgName, pkg, name, test, testFailure, reason, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishTest writes the messages for a test that completed with the
+// outcome, and records the outcome in its parent, if it is running.
+func (tc *teamcityOutput) finishTest(pkgName string, pkg *teamcityPackage, name string, test *teamcityTest, outcome, reason string, elapsed time.Duration) error {
+	nameAttr := teamcity.Attr{Name: "name", Value: name}
+	var quarantine *QuarantinedTest
+	if outcome == testFailure {
+		quarantine = tc.findQuarantine(pkgName, name)
+		if quarantine == nil && test.failedSubtests == 0 && len(test.quarantinedSubtests) > 0 && !hasOwnOutput(test.output) {
+			// The test failed only because of its quarantined subtests.
+			quarantine = test.quarantinedSubtests[0]
+		}
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 && outcome == testFailure {
+		if parent := pkg.running[name[:i]]; parent != nil {
+			if quarantine != nil {
+				parent.quarantinedSubtests = append(parent.quarantinedSubtests, quarantine)
+			} else {
+				parent.failedSubtests++
+			}
+		}
+	}
+
+	var err error
+	switch {
+	case quarantine != nil:
+		if err = tc.write("testStdOut", pkgName, nameAttr, teamcity.Attr{Name: "out", Value: test.output}); err != nil {
+			return err
+		}
+		err = tc.write("testIgnored", pkgName, nameAttr,
+			teamcity.Attr{Name: "message", Value: "quarantined (" + quarantine.String() + ")"},
+		)
+	case outcome == testFailure:
+		message := reason
+		if message == "" {
+			message = "Failed"
+			if strings.Contains(test.output, raceDetectedMessage) {
+				message = "data race detected"
+			}
+		}
+		err = tc.write("testFailed", pkgName, nameAttr,
+			teamcity.Attr{Name: "message", Value: message},
+			teamcity.Attr{Name: "details", Value: test.output},
+		)
+	case outcome == "skip":
+		err = tc.write("testIgnored", pkgName, nameAttr,
+			teamcity.Attr{Name: "message", Value: strings.TrimSpace(removeFrames(test.output))},
+		)
+	default:
+		if output := removeFrames(test.output); output != "" {
+			err = tc.write("testStdOut", pkgName, nameAttr, teamcity.Attr{Name: "out", Value: output})
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tc.write("testFinished", pkgName, nameAttr,
+		teamcity.Attr{Name: "duration", Value: strconv.FormatInt(elapsed.Milliseconds(), 10)},
+	)
+}
+
+// findQuarantine returns the quarantine of the test of the package, which
+// may be the quarantine of one of its ancestors, or nil if it is not
+// quarantined.
+func (tc *teamcityOutput) findQuarantine(pkg, test string) *QuarantinedTest {
+	if tc.quarantine == nil {
+		return nil
+	}
+	tests := tc.quarantine()
+	for name := test; ; {
+		for i := range tests {
+			if tests[i].matches(pkg, name) {
+				return &tests[i].QuarantinedTest
+			}
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+	}
+}
+
+// hasOwnOutput returns true iff the output of a test has lines other than
+// the "=== RUN" and "--- FAIL" lines and the like that "go test" prints.
+func hasOwnOutput(output string) bool {
+	return strings.TrimSpace(removeFrames(output)) != ""
+}
+
+// write writes a service message. The flowId is set to the package, if
+// any.
+func (tc *teamcityOutput) write(name, pkg string, attrs ...teamcity.Attr) error {
+	if pkg != "" {
+		attrs = append(attrs, teamcity.Attr{Name: "flowId", Value: pkg})
+	}
+	_, err := fmt.Fprintln(tc.to, teamcity.Message{Name: name, Attrs: attrs})
+	return err
+}
@@ -0,0 +1,215 @@
+package gotest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// teamcityMessages returns the TeamCity service messages written for the
+// "go test -json" output, with the provided tests quarantined.
+func teamcityMessages(t *testing.T, output io.Reader, quarantined ...QuarantinedTest) string {
+	var compiled []quarantinedTest
+	for _, q := range quarantined {
+		c, err := compileQuarantine(q)
+		require.NoError(t, err)
+		compiled = append(compiled, c)
+	}
+	var out bytes.Buffer
+	tested := newTeamCityOutput(&out, func() []quarantinedTest { return compiled })
+	discard := resultAccepterFunc(func(result) error { return nil })
+	require.NoError(t, parseGoTestJSONOutput(output, discard, io.Discard, tested))
+	return out.String()
+}
+
+// teamcityFixtureMessages returns the TeamCity service messages written for
+// the fixture, with the provided tests quarantined.
+func teamcityFixtureMessages(t *testing.T, fixture string, quarantined ...QuarantinedTest) string {
+	f, err := os.Open(filepath.Join("testdata", fixture))
+	require.NoError(t, err)
+	defer f.Close()
+	return teamcityMessages(t, f, quarantined...)
+}
+
+func Test_teamcityOutput_Accept(t *testing.T) {
+	const flow = " flowId='example.com/fx/subtests']\n"
+	require.Equal(
+		t,
+		"##teamcity[testSuiteStarted name='example.com/fx/subtests'"+flow+
+			"##teamcity[testStarted name='TestTable'"+flow+
+			"##teamcity[testStarted name='TestTable/a'"+flow+
+			"##teamcity[testStarted name='TestTable/a/deep'"+flow+
+			"##teamcity[testIgnored name='TestTable/a/deep' message='subtests_test.go:12: not today'"+flow+
+			"##teamcity[testFinished name='TestTable/a/deep' duration='0'"+flow+
+			"##teamcity[testStdOut name='TestTable/a' out='    subtests_test.go:8: in a|n'"+flow+
+			"##teamcity[testFinished name='TestTable/a' duration='0'"+flow+
+			"##teamcity[testStarted name='TestTable/b'"+flow+
+			"##teamcity[testStarted name='TestTable/b/deep'"+flow+
+			"##teamcity[testIgnored name='TestTable/b/deep' message='subtests_test.go:12: not today'"+flow+
+			"##teamcity[testFinished name='TestTable/b/deep' duration='0'"+flow+
+			"##teamcity[testFailed name='TestTable/b' message='Failed' details='=== RUN   TestTable/b|n    subtests_test.go:8: in b|n    subtests_test.go:10: bad|n--- FAIL: TestTable/b (0.00s)|n'"+flow+
+			"##teamcity[testFinished name='TestTable/b' duration='0'"+flow+
+			"##teamcity[testFailed name='TestTable' message='Failed' details='=== RUN   TestTable|n--- FAIL: TestTable (0.00s)|n'"+flow+
+			"##teamcity[testFinished name='TestTable' duration='0'"+flow+
+			"##teamcity[testStarted name='TestFlat'"+flow+
+			"##teamcity[testFinished name='TestFlat' duration='0'"+flow+
+			"##teamcity[testSuiteFinished name='example.com/fx/subtests'"+flow,
+		teamcityFixtureMessages(t, "subtests.json"),
+	)
+}
+
+func Test_teamcityOutput_Accept_live(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTeamCityOutput(&out, nil)
+	require.NoError(t, tested.Accept(event{Action: "start", Package: "pkg"}))
+	require.NoError(t, tested.Accept(event{Action: "run", Package: "pkg", Test: "TestFoo"}))
+	// The test is reported as started before it completes.
+	require.Equal(
+		t,
+		"##teamcity[testSuiteStarted name='pkg' flowId='pkg']\n"+
+			"##teamcity[testStarted name='TestFoo' flowId='pkg']\n",
+		out.String(),
+	)
+}
+
+func Test_teamcityOutput_Accept_parallelPackages(t *testing.T) {
+	const output = `{"Action":"start","Package":"a"}
+{"Action":"start","Package":"b"}
+{"Action":"run","Package":"a","Test":"TestA"}
+{"Action":"run","Package":"b","Test":"TestB"}
+{"Action":"pass","Package":"b","Test":"TestB","Elapsed":0.5}
+{"Action":"pass","Package":"a","Test":"TestA","Elapsed":1.5}
+{"Action":"pass","Package":"b","Elapsed":0.6}
+{"Action":"pass","Package":"a","Elapsed":1.6}
+`
+	require.Equal(
+		t,
+		"##teamcity[testSuiteStarted name='a' flowId='a']\n"+
+			"##teamcity[testSuiteStarted name='b' flowId='b']\n"+
+			"##teamcity[testStarted name='TestA' flowId='a']\n"+
+			"##teamcity[testStarted name='TestB' flowId='b']\n"+
+			"##teamcity[testFinished name='TestB' duration='500' flowId='b']\n"+
+			"##teamcity[testFinished name='TestA' duration='1500' flowId='a']\n"+
+			"##teamcity[testSuiteFinished name='b' flowId='b']\n"+
+			"##teamcity[testSuiteFinished name='a' flowId='a']\n",
+		teamcityMessages(t, strings.NewReader(output)),
+	)
+}
+
+func Test_teamcityOutput_Accept_crash(t *testing.T) {
+	out := teamcityFixtureMessages(t, "crash-panic.json")
+	// The tests that were running when the test binary crashed fail once
+	// the package completes.
+	for _, test := range []string{"TestCrash", "TestSlow"} {
+		require.Contains(t, out, "##teamcity[testFailed name='"+test+"' message='panic: boom' details='")
+		require.Contains(t, out, "##teamcity[testFinished name='"+test+"' duration='0' flowId='example.com/fx/crash']\n")
+	}
+	require.True(t, strings.HasSuffix(out, "##teamcity[testSuiteFinished name='example.com/fx/crash' flowId='example.com/fx/crash']\n"))
+}
+
+func Test_teamcityOutput_Accept_quarantined(t *testing.T) {
+	out := teamcityFixtureMessages(t, "subtests.json", flakyTable)
+	require.Contains(
+		t,
+		out,
+		"##teamcity[testStdOut name='TestTable/b' out='=== RUN   TestTable/b|n    subtests_test.go:8: in b|n    subtests_test.go:10: bad|n--- FAIL: TestTable/b (0.00s)|n' flowId='example.com/fx/subtests']\n"+
+			"##teamcity[testIgnored name='TestTable/b' message='quarantined (owner @team, expires 2026-12-31: flaky)' flowId='example.com/fx/subtests']\n"+
+			"##teamcity[testFinished name='TestTable/b' duration='0' flowId='example.com/fx/subtests']\n",
+	)
+	// TestTable failed only because of its quarantined subtest.
+	require.Contains(t, out, "##teamcity[testIgnored name='TestTable' message='quarantined (owner @team, expires 2026-12-31: flaky)' flowId='example.com/fx/subtests']\n")
+	require.NotContains(t, out, "testFailed")
+}
+
+func Test_teamcityOutput_Accept_metadata(t *testing.T) {
+	const flow = " flowId='example.com/fx/attrs']\n"
+	out := teamcityFixtureMessages(t, "attrs.json")
+	require.Contains(
+		t,
+		out,
+		"##teamcity[testStarted name='TestTagged'"+flow+
+			"##teamcity[testMetadata testName='TestTagged' name='ticket' value='GO-123'"+flow+
+			"##teamcity[testMetadata testName='TestTagged' name='owner' value='gophers'"+flow+
+			"##teamcity[testStarted name='TestTagged/sub'"+flow+
+			"##teamcity[testMetadata testName='TestTagged/sub' name='link' value='https://example.com/x'"+flow,
+	)
+	require.Contains(
+		t,
+		out,
+		"##teamcity[testMetadata testName='TestArtifacts' type='artifact' value='/src/fx/_artifacts/attrs/TestArtifacts/3065154586'"+flow,
+	)
+}
+
+func Test_teamcityOutput_Accept_passedOutput(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTeamCityOutput(&out, nil)
+	for _, e := range []event{
+		{Action: "run", Package: "pkg", Test: "TestFoo"},
+		{Action: "output", Package: "pkg", Test: "TestFoo", Output: "=== RUN   TestFoo\n", OutputType: "frame"},
+		{Action: "output", Package: "pkg", Test: "TestFoo", Output: "    foo_test.go:5: hello\n"},
+		{Action: "output", Package: "pkg", Test: "TestFoo", Output: "--- PASS: TestFoo (1.50s)\n", OutputType: "frame"},
+		{Action: "pass", Package: "pkg", Test: "TestFoo", Elapsed: 1.5},
+	} {
+		require.NoError(t, tested.Accept(e))
+	}
+	require.Equal(
+		t,
+		"##teamcity[testSuiteStarted name='pkg' flowId='pkg']\n"+
+			"##teamcity[testStarted name='TestFoo' flowId='pkg']\n"+
+			"##teamcity[testStdOut name='TestFoo' out='    foo_test.go:5: hello|n' flowId='pkg']\n"+
+			"##teamcity[testFinished name='TestFoo' duration='1500' flowId='pkg']\n",
+		out.String(),
+	)
+}
+
+func Test_teamcityOutput_Accept_race(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTeamCityOutput(&out, nil)
+	for _, e := range []event{
+		{Action: "run", Package: "pkg", Test: "TestFoo"},
+		{Action: "output", Package: "pkg", Test: "TestFoo", Output: "    testing.go:1490: " + raceDetectedMessage + "\n"},
+		{Action: "fail", Package: "pkg", Test: "TestFoo"},
+	} {
+		require.NoError(t, tested.Accept(e))
+	}
+	require.Contains(t, out.String(), "##teamcity[testFailed name='TestFoo' message='data race detected' details='")
+}
+
+func Test_teamcityOutput_Accept_buildFail(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTeamCityOutput(&out, nil)
+	for _, e := range []event{
+		{Action: "build-output", ImportPath: "example.com/pkg [example.com/pkg.test]", Output: "# example.com/pkg\n"},
+		{Action: "build-output", ImportPath: "example.com/pkg [example.com/pkg.test]", Output: "./foo.go:5:24: undefined: bar\n"},
+		{Action: "build-fail", ImportPath: "example.com/pkg [example.com/pkg.test]"},
+		{Action: "build-output", ImportPath: "example.com/other", Output: "warning\n"},
+	} {
+		require.NoError(t, tested.Accept(e))
+	}
+	require.Equal(t, "##teamcity[buildProblem description='build failed: example.com/pkg']\n", out.String())
+}
+
+func Test_teamcityOutput_Accept_packageWithoutTests(t *testing.T) {
+	var out bytes.Buffer
+	tested := newTeamCityOutput(&out, nil)
+	require.NoError(t, tested.Accept(event{Action: "start", Package: "pkg"}))
+	require.NoError(t, tested.Accept(event{Action: "skip", Package: "pkg"}))
+	require.Equal(
+		t,
+		"##teamcity[testSuiteStarted name='pkg' flowId='pkg']\n##teamcity[testSuiteFinished name='pkg' flowId='pkg']\n",
+		out.String(),
+	)
+}
+
+func Test_teamcityOutput_Accept_error(t *testing.T) {
+	expectedErr := errors.New("blah")
+	tested := newTeamCityOutput(&errorWriter{err: expectedErr}, nil)
+	err := tested.Accept(event{Action: "run", Package: "pkg", Test: "TestFoo"})
+	require.Equal(t, expectedErr, err)
+}
@@ -0,0 +1,72 @@
+package gotest
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// timeoutPanicRegexp matches the panic printed by the testing package
+	// when a test binary runs for longer than "go test -timeout".
+	timeoutPanicRegexp = regexp.MustCompile(`(?m)^panic: test timed out after (\S+)\n`)
+
+	// runningTestRegexp matches an entry of the "running tests:" list that
+	// follows the timeout panic (e.g. "\t\tTestFoo (10m0s)").
+	runningTestRegexp = regexp.MustCompile(`(?m)^\t\t(\S+) \((\S+)\)$`)
+)
+
+// testTimeout is a "go test -timeout" panic found in test output.
+type testTimeout struct {
+	// After is the timeout as printed by the testing package (e.g. "10m0s").
+	After string
+	// Running maps each test the testing package listed as running to how
+	// long it had been running.
+	Running map[string]time.Duration
+	// Goroutines are the goroutines from the goroutine dump.
+	Goroutines []goroutine
+}
+
+// findTestTimeout looks for a "go test -timeout" panic in the provided
+// output. If one is found the returned index is the offset of the panic,
+// everything from which is the panic message and goroutine dump.
+func findTestTimeout(output string) (testTimeout, int, bool) {
+	loc := timeoutPanicRegexp.FindStringSubmatchIndex(output)
+	if loc == nil {
+		return testTimeout{}, 0, false
+	}
+	dump := output[loc[0]:]
+	timeout := testTimeout{
+		After:      output[loc[2]:loc[3]],
+		Running:    make(map[string]time.Duration),
+		Goroutines: parseGoroutines(dump),
+	}
+	// The running tests list ends at the first blank line.
+	running, _, _ := strings.Cut(dump, "\n\n")
+	for _, m := range runningTestRegexp.FindAllStringSubmatch(running, -1) {
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			continue
+		}
+		timeout.Running[m[1]] = d
+	}
+	return timeout, loc[0], true
+}
+
+// timedOutOutput returns the output to append to a test that was still
+// running when the test binary timed out. It explains the failure and
+// includes the goroutines belonging to the test, and ends with a
+// "--- FAIL" line like the one "go test" would have printed.
+func (t testTimeout) timedOutOutput(test string, hasSubtests bool) string {
+	var sb strings.Builder
+	sb.WriteString("    test timed out after " + t.After + "\n")
+	goroutines := goroutinesForTest(t.Goroutines, test, hasSubtests)
+	if len(goroutines) > 0 {
+		sb.WriteString("    goroutines running " + test + ":\n")
+	}
+	for _, g := range goroutines {
+		sb.WriteString("\n" + indent(g.Trace, "        "))
+	}
+	sb.WriteString(failLine(test, t.Running[test]))
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package gotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findTestTimeout(t *testing.T) {
+	const output = "=== RUN   TestHang\n" +
+		"panic: test timed out after 1s\n" +
+		"\trunning tests:\n" +
+		"\t\tTestHang (1s)\n" +
+		"\t\tTestHang/sub (900ms)\n" +
+		"\n" +
+		"goroutine 9 [sleep]:\n" +
+		"example.com/fx/hang.TestHang.func1(0x206ce7602908?)\n" +
+		"\t/src/fx/hang/hang_test.go:17 +0x1d\n"
+	timeout, i, ok := findTestTimeout(output)
+	require.True(t, ok)
+	require.Equal(t, len("=== RUN   TestHang\n"), i)
+	require.Equal(t, "1s", timeout.After)
+	require.Equal(
+		t,
+		map[string]time.Duration{"TestHang": time.Second, "TestHang/sub": 900 * time.Millisecond},
+		timeout.Running,
+	)
+	require.Len(t, timeout.Goroutines, 1)
+
+	require.Equal(
+		t,
+		"    test timed out after 1s\n"+
+			"    goroutines running TestHang/sub:\n"+
+			"\n"+
+			"        goroutine 9 [sleep]:\n"+
+			"        example.com/fx/hang.TestHang.func1(0x206ce7602908?)\n"+
+			"        \t/src/fx/hang/hang_test.go:17 +0x1d\n"+
+			"--- FAIL: TestHang/sub (0.90s)\n",
+		timeout.timedOutOutput("TestHang/sub", false),
+	)
+	require.Equal(
+		t,
+		"    test timed out after 1s\n--- FAIL: TestHang (1.00s)\n",
+		timeout.timedOutOutput("TestHang", true),
+	)
+}
+
+func Test_findTestTimeout_noTimeout(t *testing.T) {
+	_, _, ok := findTestTimeout("panic: boom\n")
+	require.False(t, ok)
+}
@@ -2,24 +2,72 @@
 package junit
 
 import (
-	"io"
+	"encoding/xml"
 	"os"
-
-	"oss.indeed.com/go/go-opine/internal/run"
 )
 
-// Write a JUnit XML file from the provided Go test output.
-func Write(goTestOutput, outPath string) error {
-	junitOut, _, err := run.Cmd(
-		"go",
-		run.Args("run", "github.com/jstemmer/go-junit-report@v0.9.1"),
-		run.Stdin(goTestOutput),
-		run.Log(io.Discard),
-	)
+// Testsuites is the root element of a JUnit XML report.
+type Testsuites struct {
+	XMLName xml.Name    `xml:"testsuites"`
+	Suites  []Testsuite `xml:"testsuite"`
+}
+
+// Testsuite is the result of testing a single Go package.
+type Testsuite struct {
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Errors    int        `xml:"errors,attr"`
+	Skipped   int        `xml:"skipped,attr"`
+	Time      string     `xml:"time,attr"`
+	Testcases []Testcase `xml:"testcase"`
+}
+
+// Testcase is the result of a single test.
+type Testcase struct {
+	Classname  string      `xml:"classname,attr"`
+	Name       string      `xml:"name,attr"`
+	Time       string      `xml:"time,attr"`
+	File       string      `xml:"file,attr,omitempty"`
+	Line       int         `xml:"line,attr,omitempty"`
+	Properties *Properties `xml:"properties,omitempty"`
+	Failure    *Failure    `xml:"failure,omitempty"`
+	Skipped    *Skipped    `xml:"skipped,omitempty"`
+	SystemOut  string      `xml:"system-out,omitempty"`
+}
+
+// Properties are the properties of a Testcase.
+type Properties struct {
+	Properties []Property `xml:"property"`
+}
+
+// Property is a name/value pair describing a Testcase.
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Failure indicates that a Testcase failed.
+type Failure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// Skipped indicates that a Testcase was skipped.
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Write a JUnit XML file from the provided report.
+func Write(report *Testsuites, outPath string) error {
+	out, err := xml.MarshalIndent(report, "", "\t")
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(outPath, []byte(junitOut), 0666); err != nil { //nolint:gosec
+	out = append([]byte(xml.Header), out...)
+	out = append(out, '\n')
+	if err := os.WriteFile(outPath, out, 0666); err != nil { //nolint:gosec
 		return err
 	}
 	return nil
@@ -6,21 +6,50 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
-
-	"oss.indeed.com/go/go-opine/internal/run"
 )
 
 func Test_Write(t *testing.T) {
 	outDir, err := os.MkdirTemp("", "go-opine-junit-test.")
 	require.NoError(t, err)
 	defer os.RemoveAll(outDir)
-	goTestOutput, _, err := run.Cmd("go", run.Args("test", "-v", "./testdata"))
-	require.NoError(t, err)
+	report := &Testsuites{
+		Suites: []Testsuite{
+			{
+				Name:     "oss.indeed.com/go/go-opine/internal/junit/testdata",
+				Tests:    2,
+				Failures: 1,
+				Time:     "0.010",
+				Testcases: []Testcase{
+					{
+						Classname:  "testdata",
+						Name:       "Test_Data",
+						Time:       "0.000",
+						Properties: &Properties{Properties: []Property{{Name: "ticket", Value: "GO-1"}}},
+					},
+					{
+						Classname: "testdata",
+						Name:      "Test_Data/fails",
+						Time:      "0.000",
+						Failure:   &Failure{Message: "Failed", Contents: "data_test.go:12: <bad>\n"},
+					},
+				},
+			},
+		},
+	}
 	outPath := filepath.Join(outDir, "junit.xml")
-	err = Write(goTestOutput, outPath)
+	err = Write(report, outPath)
 	require.NoError(t, err)
 	outBytes, err := os.ReadFile(outPath)
 	require.NoError(t, err)
 	out := string(outBytes)
+	require.Contains(t, out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<testsuites>\n")
+	require.Contains(t, out, "<testsuite name=\"oss.indeed.com/go/go-opine/internal/junit/testdata\" tests=\"2\" failures=\"1\" errors=\"0\" skipped=\"0\" time=\"0.010\">")
 	require.Contains(t, out, "\"Test_Data\"")
+	require.Contains(t, out, "<properties>\n\t\t\t\t<property name=\"ticket\" value=\"GO-1\"></property>\n\t\t\t</properties>")
+	require.Contains(t, out, "<failure message=\"Failed\" type=\"\">data_test.go:12: &lt;bad&gt;&#xA;</failure>")
+}
+
+func Test_Write_badPath(t *testing.T) {
+	err := Write(&Testsuites{}, filepath.Join("does", "not", "exist", "junit.xml"))
+	require.Error(t, err)
 }
@@ -1,5 +0,0 @@
-package testdata
-
-func data() {
-	println("DATA!")
-}
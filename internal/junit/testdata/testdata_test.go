@@ -1,9 +0,0 @@
-package testdata
-
-import (
-	"testing"
-)
-
-func Test_Data(t *testing.T) {
-	data()
-}
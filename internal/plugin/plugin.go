@@ -0,0 +1,155 @@
+// Package plugin is for running external reporter executables, which are
+// sent the results of a test run as newline-delimited JSON on stdin.
+//
+// Each line is a Message. The first is a "start" message with the
+// ProtocolVersion. Within a version fields are only ever added, never
+// removed, renamed, or changed in meaning. The end of the results is
+// signaled by closing stdin.
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/run"
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// ProtocolVersion is the version of the messages sent to reporters.
+const ProtocolVersion = 1
+
+// The types of messages.
+const (
+	// StartMessage is the first message, which has the ProtocolVersion and
+	// the GoOpineVersion.
+	StartMessage = "start"
+	// TestMessage is sent when a test or subtest completes, with its
+	// ImportPath and Test.
+	TestMessage = "test"
+	// PackageMessage is sent when a package completes, after the messages
+	// of its tests, with the Package.
+	PackageMessage = "package"
+	// CoverageMessage is sent once the coverage is known, with the
+	// Coverage. It is not sent if coverage was not measured.
+	CoverageMessage = "coverage"
+)
+
+// Message is a message sent to reporters. The Type determines which other
+// fields are set. The Test, Package, and Coverage have the same schema as
+// in the JSON summary.
+type Message struct {
+	Type            string `json:"type"`
+	ProtocolVersion int    `json:"protocolVersion,omitempty"`
+	GoOpineVersion  string `json:"goOpineVersion,omitempty"`
+	// ImportPath is the package of the test of a TestMessage.
+	ImportPath string        `json:"importPath,omitempty"`
+	Test       *summary.Test `json:"test,omitempty"`
+	// Output is the output of a failed test, without the output of its
+	// subtests.
+	Output   string            `json:"output,omitempty"`
+	Package  *summary.Package  `json:"package,omitempty"`
+	Coverage *summary.Coverage `json:"coverage,omitempty"`
+}
+
+// Write writes the message as a line of JSON.
+func Write(w io.Writer, m Message) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+// writeTimeout is how long Write waits for a reporter to read from its
+// stdin before giving up on it.
+var writeTimeout = 30 * time.Second
+
+// Reporter is a running reporter executable. Messages are written to it
+// with Write, and once all have been Wait waits for it to exit.
+type Reporter struct {
+	// Command is the command line the reporter was started with.
+	Command string
+	// Required is true if the run fails when the reporter fails.
+	Required bool
+
+	stdin   *os.File
+	process *os.Process
+	stalled error
+	done    chan error
+}
+
+var _ io.Writer = (*Reporter)(nil)
+
+// Start starts the reporter with the command line, which is split into
+// the executable and its arguments at spaces. The command and its output
+// are logged to the provided writer.
+//
+// Start never fails: if the reporter could not be started Wait returns
+// why.
+func Start(command string, required bool, log io.Writer) *Reporter {
+	r := &Reporter{Command: command, Required: required, done: make(chan error, 1)}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		r.done <- fmt.Errorf("reporter %q failed: empty command", command)
+		return r
+	}
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		r.done <- fmt.Errorf("reporter %q failed: %w", command, err)
+		return r
+	}
+	r.stdin = w
+	started := make(chan struct{})
+	go func() {
+		_, _, err := run.Cmd(args[0], args[1:], run.StdinReader(stdin), run.Log(log), run.Started(func(p *os.Process) {
+			r.process = p
+			// Once only the reporter has the read end, messages written
+			// after it exited fail instead of blocking, and are dropped.
+			_ = stdin.Close()
+			close(started)
+		}))
+		if r.process == nil {
+			_ = stdin.Close()
+			close(started)
+		}
+		if err != nil {
+			err = fmt.Errorf("reporter %q failed: %w", command, err)
+		}
+		r.done <- err
+	}()
+	<-started
+	return r
+}
+
+// Write sends p to the stdin of the reporter. It never fails: if the
+// reporter has exited p is dropped, and Wait returns why it exited. If
+// the reporter does not read p within a while it is killed, so that a
+// stuck reporter cannot stall the run, and Wait returns an error.
+func (r *Reporter) Write(p []byte) (int, error) {
+	if r.stdin == nil || r.stalled != nil {
+		return len(p), nil
+	}
+	_ = r.stdin.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := r.stdin.Write(p); errors.Is(err, os.ErrDeadlineExceeded) {
+		r.stalled = fmt.Errorf("reporter %q failed: stopped reading its stdin for %v", r.Command, writeTimeout)
+		if r.process != nil {
+			_ = r.process.Kill()
+		}
+	}
+	return len(p), nil
+}
+
+// Wait closes the stdin of the reporter and waits for it to exit. An
+// error is returned if it could not be started, exited with a non-zero
+// status, or was killed because it stopped reading its stdin.
+func (r *Reporter) Wait() error {
+	if r.stdin != nil {
+		_ = r.stdin.Close()
+	}
+	err := <-r.done
+	if r.stalled != nil {
+		return r.stalled
+	}
+	return err
+}
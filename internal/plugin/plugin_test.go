@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/internal/summary"
+)
+
+// writeScript writes an executable shell script to a temporary directory
+// and returns its path.
+func writeScript(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "reporter.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)) //nolint:gosec
+	return path
+}
+
+func Test_Reporter(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "out.json")
+	script := writeScript(t, `cat > "$1"`)
+
+	var log bytes.Buffer
+	r := Start(script+" "+outPath, true, &log)
+	require.Equal(t, script+" "+outPath, r.Command)
+	require.True(t, r.Required)
+	require.NoError(t, Write(r, Message{Type: StartMessage, ProtocolVersion: ProtocolVersion, GoOpineVersion: "(devel)"}))
+	require.NoError(t, Write(r, Message{Type: TestMessage, ImportPath: "pkg", Test: &summary.Test{Name: "TestFoo", Outcome: "fail", FailedItself: true}, Output: "oops\n"}))
+	require.NoError(t, r.Wait())
+	require.Contains(t, log.String(), "Command completed successfully")
+
+	out, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`{"type":"start","protocolVersion":1,"goOpineVersion":"(devel)"}
+{"type":"test","importPath":"pkg","test":{"name":"TestFoo","outcome":"fail","elapsedSeconds":0,"dataRace":false,"failedItself":true},"output":"oops\n"}
+`,
+		string(out),
+	)
+}
+
+func Test_Reporter_failed(t *testing.T) {
+	script := writeScript(t, "cat > /dev/null\nexit 3\n")
+
+	r := Start(script, false, io.Discard)
+	require.False(t, r.Required)
+	require.NoError(t, Write(r, Message{Type: StartMessage}))
+	err := r.Wait()
+	require.EqualError(t, err, "reporter \""+script+"\" failed: exit status 3")
+}
+
+func Test_Reporter_exitsEarly(t *testing.T) {
+	script := writeScript(t, "exit 0\n")
+
+	r := Start(script, true, io.Discard)
+	// Much more than fits in a pipe buffer, none of which is read.
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < 1024; i++ {
+		n, err := r.Write(line)
+		require.NoError(t, err)
+		require.Equal(t, len(line), n)
+	}
+	require.NoError(t, r.Wait())
+}
+
+func Test_Reporter_neverReads(t *testing.T) {
+	defer func(timeout time.Duration) { writeTimeout = timeout }(writeTimeout)
+	writeTimeout = 100 * time.Millisecond
+	script := writeScript(t, "exec sleep 60\n")
+
+	r := Start(script, true, io.Discard)
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < 1024; i++ {
+		n, err := r.Write(line)
+		require.NoError(t, err)
+		require.Equal(t, len(line), n)
+	}
+	require.EqualError(t, r.Wait(), fmt.Sprintf("reporter %q failed: stopped reading its stdin for 100ms", script))
+}
+
+func Test_Reporter_notFound(t *testing.T) {
+	r := Start(filepath.Join(t.TempDir(), "missing"), true, io.Discard)
+	require.NoError(t, Write(r, Message{Type: StartMessage}))
+	require.ErrorContains(t, r.Wait(), "no such file or directory")
+}
+
+func Test_Reporter_emptyCommand(t *testing.T) {
+	r := Start(" ", true, io.Discard)
+	require.NoError(t, Write(r, Message{Type: StartMessage}))
+	require.EqualError(t, r.Wait(), `reporter " " failed: empty command`)
+}
@@ -0,0 +1,138 @@
+package printing
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Color modes accepted by NewPalette.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Palette styles text using ANSI escape sequences. The zero Palette
+// returns all text unchanged.
+type Palette struct {
+	// Color enables colors.
+	Color bool
+	// Hyperlinks enables OSC 8 hyperlinks.
+	Hyperlinks bool
+}
+
+// NewPalette returns the Palette to use for text written to w. The mode
+// must be one of ColorAuto, ColorAlways, or ColorNever. An empty mode is
+// the same as ColorAuto.
+//
+// In ColorAuto mode colors are disabled if the NO_COLOR environment
+// variable is set (see https://no-color.org). Otherwise they are enabled
+// if FORCE_COLOR is set, unless it is set to "0" or "false", and if
+// FORCE_COLOR is not set they are enabled iff w is a terminal. Hyperlinks
+// are only enabled along with colors, when w is a terminal that is known
+// to support them or FORCE_HYPERLINK=1.
+func NewPalette(mode string, w io.Writer) (Palette, error) {
+	return newPalette(mode, IsTerminal(w), os.Getenv)
+}
+
+func newPalette(mode string, terminal bool, getenv func(string) string) (Palette, error) {
+	var p Palette
+	switch mode {
+	case ColorAlways:
+		p.Color = true
+	case ColorNever:
+	case ColorAuto, "":
+		switch force := getenv("FORCE_COLOR"); {
+		case getenv("NO_COLOR") != "":
+		case force == "0" || force == "false":
+		case force != "":
+			p.Color = true
+		default:
+			p.Color = terminal && getenv("TERM") != "dumb"
+		}
+	default:
+		return Palette{}, fmt.Errorf("invalid color mode %q (must be %s, %s, or %s)", mode, ColorAuto, ColorAlways, ColorNever)
+	}
+	p.Hyperlinks = p.Color && supportsHyperlinks(terminal, getenv)
+	return p, nil
+}
+
+// supportsHyperlinks returns true iff the terminal is known to support
+// OSC 8 hyperlinks. There is no standard way to detect support, so this
+// is based on the environment variables set by popular terminals.
+func supportsHyperlinks(terminal bool, getenv func(string) string) bool {
+	switch getenv("FORCE_HYPERLINK") {
+	case "1":
+		return true
+	case "0":
+		return false
+	}
+	if !terminal {
+		return false
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty":
+		return true
+	}
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return getenv("KITTY_WINDOW_ID") != "" || getenv("WT_SESSION") != ""
+}
+
+// Red returns the text in red.
+func (p Palette) Red(text string) string {
+	return p.sgr("31", text)
+}
+
+// BoldRed returns the text in bold red.
+func (p Palette) BoldRed(text string) string {
+	return p.sgr("1;31", text)
+}
+
+// Green returns the text in green.
+func (p Palette) Green(text string) string {
+	return p.sgr("32", text)
+}
+
+// Yellow returns the text in yellow.
+func (p Palette) Yellow(text string) string {
+	return p.sgr("33", text)
+}
+
+// sgr wraps the text in a "Select Graphic Rendition" escape sequence
+// with the provided parameters, followed by a reset.
+func (p Palette) sgr(params, text string) string {
+	if !p.Color || text == "" {
+		return text
+	}
+	return "\x1b[" + params + "m" + text + "\x1b[0m"
+}
+
+// FileLink returns the text as a hyperlink to the file at the provided
+// absolute path.
+func (p Palette) FileLink(path, text string) string {
+	if !p.Hyperlinks || !filepath.IsAbs(path) {
+		return text
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	if host, err := os.Hostname(); err == nil {
+		u.Host = host
+	}
+	return "\x1b]8;;" + u.String() + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// IsTerminal returns true iff the writer is a file connected to a
+// terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
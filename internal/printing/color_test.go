@@ -0,0 +1,123 @@
+package printing
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newPalette(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mode     string
+		terminal bool
+		env      map[string]string
+		expected Palette
+	}{
+		{name: "auto terminal", mode: ColorAuto, terminal: true, expected: Palette{Color: true}},
+		{name: "auto not terminal", mode: ColorAuto},
+		{name: "auto dumb terminal", mode: ColorAuto, terminal: true, env: map[string]string{"TERM": "dumb"}},
+		{name: "auto NO_COLOR", mode: ColorAuto, terminal: true, env: map[string]string{"NO_COLOR": "1"}},
+		{name: "auto NO_COLOR beats FORCE_COLOR", mode: ColorAuto, env: map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}},
+		{name: "auto FORCE_COLOR", mode: ColorAuto, env: map[string]string{"FORCE_COLOR": "1"}, expected: Palette{Color: true}},
+		{name: "auto FORCE_COLOR=0", mode: ColorAuto, terminal: true, env: map[string]string{"FORCE_COLOR": "0"}},
+		{name: "empty is auto", mode: "", terminal: true, expected: Palette{Color: true}},
+		{name: "always", mode: ColorAlways, env: map[string]string{"NO_COLOR": "1"}, expected: Palette{Color: true}},
+		{name: "never", mode: ColorNever, terminal: true, env: map[string]string{"FORCE_COLOR": "1"}},
+		{
+			name:     "hyperlinks",
+			mode:     ColorAuto,
+			terminal: true,
+			env:      map[string]string{"TERM_PROGRAM": "iTerm.app"},
+			expected: Palette{Color: true, Hyperlinks: true},
+		},
+		{
+			name:     "hyperlinks VTE",
+			mode:     ColorAuto,
+			terminal: true,
+			env:      map[string]string{"VTE_VERSION": "6003"},
+			expected: Palette{Color: true, Hyperlinks: true},
+		},
+		{
+			name:     "hyperlinks old VTE",
+			mode:     ColorAuto,
+			terminal: true,
+			env:      map[string]string{"VTE_VERSION": "4000"},
+			expected: Palette{Color: true},
+		},
+		{
+			name:     "hyperlinks not terminal",
+			mode:     ColorAlways,
+			env:      map[string]string{"TERM_PROGRAM": "iTerm.app"},
+			expected: Palette{Color: true},
+		},
+		{
+			name:     "FORCE_HYPERLINK",
+			mode:     ColorAlways,
+			env:      map[string]string{"FORCE_HYPERLINK": "1"},
+			expected: Palette{Color: true, Hyperlinks: true},
+		},
+		{
+			name:     "FORCE_HYPERLINK=0",
+			mode:     ColorAuto,
+			terminal: true,
+			env:      map[string]string{"FORCE_HYPERLINK": "0", "WT_SESSION": "1"},
+			expected: Palette{Color: true},
+		},
+		{
+			name: "no hyperlinks without color",
+			mode: ColorNever,
+			env:  map[string]string{"FORCE_HYPERLINK": "1"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := newPalette(tc.mode, tc.terminal, func(key string) string { return tc.env[key] })
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func Test_newPalette_invalidMode(t *testing.T) {
+	_, err := newPalette("sometimes", true, func(string) string { return "" })
+	require.EqualError(t, err, `invalid color mode "sometimes" (must be auto, always, or never)`)
+}
+
+func Test_NewPalette_notTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	p, err := NewPalette(ColorAuto, &bytes.Buffer{})
+	require.NoError(t, err)
+	require.Equal(t, Palette{}, p)
+}
+
+func Test_Palette_colors(t *testing.T) {
+	p := Palette{Color: true}
+	require.Equal(t, "\x1b[31mred\x1b[0m", p.Red("red"))
+	require.Equal(t, "\x1b[1;31mbold red\x1b[0m", p.BoldRed("bold red"))
+	require.Equal(t, "\x1b[32mgreen\x1b[0m", p.Green("green"))
+	require.Equal(t, "\x1b[33myellow\x1b[0m", p.Yellow("yellow"))
+	require.Equal(t, "", p.Red(""))
+	require.Equal(t, "plain", Palette{}.Red("plain"))
+}
+
+func Test_Palette_FileLink(t *testing.T) {
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	p := Palette{Color: true, Hyperlinks: true}
+	require.Equal(t, "\x1b]8;;file://"+host+"/src/foo%20bar/foo.go\x1b\\foo.go:42\x1b]8;;\x1b\\", p.FileLink("/src/foo bar/foo.go", "foo.go:42"))
+	require.Equal(t, "foo.go:42", p.FileLink("foo.go", "foo.go:42"))
+	require.Equal(t, "foo.go:42", Palette{Color: true}.FileLink("/src/foo.go", "foo.go:42"))
+}
+
+func Test_IsTerminal(t *testing.T) {
+	f, err := os.CreateTemp("", "go-opine-printing-test.")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	require.False(t, IsTerminal(f))
+	require.False(t, IsTerminal(&bytes.Buffer{}))
+}
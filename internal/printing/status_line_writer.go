@@ -0,0 +1,97 @@
+package printing
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// clearLine moves the cursor to the start of the line and erases it.
+const clearLine = "\r\x1b[K"
+
+// NewStatusLineWriter creates and returns a new StatusLineWriter.
+func NewStatusLineWriter(to io.Writer) *StatusLineWriter {
+	return &StatusLineWriter{to: to}
+}
+
+// StatusLineWriter wraps an io.Writer connected to a terminal and keeps a
+// status line below everything written to it. The status line is erased
+// before each write and redrawn after it, so the status line always stays
+// at the bottom.
+//
+// It is safe to use a StatusLineWriter from multiple goroutines.
+type StatusLineWriter struct {
+	mu      sync.Mutex
+	to      io.Writer
+	status  string
+	shown   bool
+	midLine bool
+}
+
+var _ io.Writer = (*StatusLineWriter)(nil)
+
+// Write to the underlying io.Writer. The status line is not redrawn until
+// a write ends with a newline.
+func (w *StatusLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := w.clear(); err != nil {
+		return 0, err
+	}
+	n, err := w.to.Write(p)
+	if err != nil {
+		return n, err
+	}
+	w.midLine = !bytes.HasSuffix(p, []byte{'\n'})
+	return n, w.show()
+}
+
+// SetStatus replaces the status line. The status must not contain a
+// newline.
+func (w *StatusLineWriter) SetStatus(status string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if status == w.status && (w.shown || w.midLine) {
+		return nil
+	}
+	if err := w.clear(); err != nil {
+		return err
+	}
+	w.status = status
+	return w.show()
+}
+
+// Clear erases the status line.
+func (w *StatusLineWriter) Clear() error {
+	return w.SetStatus("")
+}
+
+// clear erases the status line if it is shown.
+func (w *StatusLineWriter) clear() error {
+	if !w.shown {
+		return nil
+	}
+	if _, err := io.WriteString(w.to, clearLine); err != nil {
+		return err
+	}
+	w.shown = false
+	return nil
+}
+
+// show draws the status line unless there is no status or the last write
+// did not end with a newline.
+func (w *StatusLineWriter) show() error {
+	if w.status == "" || w.midLine {
+		return nil
+	}
+	if _, err := io.WriteString(w.to, w.status); err != nil {
+		return err
+	}
+	w.shown = true
+	return nil
+}
@@ -0,0 +1,53 @@
+package printing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StatusLineWriter_Write(t *testing.T) {
+	var b bytes.Buffer
+	tested := NewStatusLineWriter(&b)
+	n, err := tested.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	require.NoError(t, tested.SetStatus("1/2 done"))
+	require.NoError(t, tested.SetStatus("1/2 done"))
+	_, err = tested.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, tested.SetStatus("2/2 done"))
+	require.NoError(t, tested.Clear())
+	require.Equal(t, "first\n1/2 done\r\x1b[Ksecond\n1/2 done\r\x1b[K2/2 done\r\x1b[K", b.String())
+}
+
+func Test_StatusLineWriter_Write_midLine(t *testing.T) {
+	var b bytes.Buffer
+	tested := NewStatusLineWriter(&b)
+	require.NoError(t, tested.SetStatus("status"))
+	_, err := tested.Write([]byte("no newline"))
+	require.NoError(t, err)
+	require.NoError(t, tested.SetStatus("new status"))
+	_, err = tested.Write([]byte(" yet\n"))
+	require.NoError(t, err)
+	require.Equal(t, "status\r\x1b[Kno newline yet\nnew status", b.String())
+}
+
+func Test_StatusLineWriter_Write_empty(t *testing.T) {
+	var b bytes.Buffer
+	tested := NewStatusLineWriter(&b)
+	require.NoError(t, tested.SetStatus("status"))
+	n, err := tested.Write(nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	require.Equal(t, "status", b.String())
+}
+
+func Test_StatusLineWriter_Write_error(t *testing.T) {
+	tested := NewStatusLineWriter(errorWriter{n: 2})
+	n, err := tested.Write([]byte("test\n"))
+	require.ErrorIs(t, err, errorWriterErr)
+	require.Equal(t, 2, n)
+	require.ErrorIs(t, tested.SetStatus("status"), errorWriterErr)
+}
@@ -21,6 +21,7 @@ type cmdinfo struct {
 	log       *printing.LogWriter
 	logStdout bool
 	logStderr bool
+	started   func(*os.Process)
 }
 
 // Cmd runs the provided command (with the provided args) and returns the
@@ -75,7 +76,13 @@ func Cmd(command string, args []string, opts ...Option) (string, string, error)
 	sp.cmd.Stderr = io.MultiWriter(stderrs...)
 
 	sp.log.Logf("Running %q with args %q...", sp.cmd.Path, sp.cmd.Args[1:])
-	err := sp.cmd.Run()
+	err := sp.cmd.Start()
+	if err == nil {
+		if sp.started != nil {
+			sp.started(sp.cmd.Process)
+		}
+		err = sp.cmd.Wait()
+	}
 	if err != nil {
 		sp.log.Logf("Command failed: %v", err)
 	} else {
@@ -119,6 +126,24 @@ func Stdin(in string) Option {
 	}
 }
 
+// StdinReader causes Cmd to send what is read from the provided
+// io.Reader to the command as stdin, until it returns io.EOF or the
+// command exits.
+func StdinReader(in io.Reader) Option {
+	return func(s *cmdinfo) {
+		s.cmd.Stdin = in
+	}
+}
+
+// Started causes Cmd to call the provided function with the process of
+// the command once it has started, e.g. so that it can be killed. It is
+// not called if the command could not be started.
+func Started(f func(*os.Process)) Option {
+	return func(s *cmdinfo) {
+		s.started = f
+	}
+}
+
 // Stdout causes Cmd to tee the Stdout of the process to the provided io.Writer.
 func Stdout(out io.Writer) Option {
 	return func(s *cmdinfo) {
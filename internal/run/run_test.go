@@ -2,6 +2,8 @@ package run
 
 import (
 	"bytes"
+	"io"
+	"os"
 	"testing"
 	"time"
 
@@ -53,6 +55,29 @@ func Test_Cmd_optionStdin(t *testing.T) {
 	require.Empty(t, stderr)
 }
 
+func Test_Cmd_optionStdinReader(t *testing.T) {
+	in, w := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(w, "hello\n")
+		_, _ = io.WriteString(w, "world\n")
+		_ = w.Close()
+	}()
+	stdout, stderr, err := Cmd("cat", Args(), StdinReader(in))
+	require.NoError(t, err)
+	require.Equal(t, "hello\nworld\n", stdout)
+	require.Empty(t, stderr)
+}
+
+func Test_Cmd_optionStarted(t *testing.T) {
+	var pid int
+	_, _, err := Cmd("true", Args(), Started(func(p *os.Process) { pid = p.Pid }))
+	require.NoError(t, err)
+	require.NotZero(t, pid)
+
+	_, _, err = Cmd("does-not-exist", Args(), Started(func(*os.Process) { t.Error("called for a command that did not start") }))
+	require.Error(t, err)
+}
+
 func Test_Cmd_optionLog(t *testing.T) {
 	var log bytes.Buffer
 	stdout, stderr, err := Cmd("sh", Args("-c", "echo hello; >&2 echo 'world'"), Log(&log))
@@ -0,0 +1,171 @@
+// Package summary is for writing machine-readable JSON summaries of test
+// runs.
+//
+// The JSON is versioned by SchemaVersion. Within a version fields are only
+// ever added, never removed, renamed, or changed in meaning. Fields that
+// are not known (e.g. the Go version of saved output) are omitted.
+package summary
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// SchemaVersion is the version of the JSON schema of a Summary.
+const SchemaVersion = 1
+
+// Summary is the summary of a test run.
+type Summary struct {
+	SchemaVersion int `json:"schemaVersion"`
+	// GoOpineVersion is the version of go-opine, or "(devel)" if it was
+	// not built from a released module.
+	GoOpineVersion string `json:"goOpineVersion"`
+	// GoVersion is the version of Go that ran the tests (e.g. "go1.25.1").
+	GoVersion string `json:"goVersion,omitempty"`
+	// GoTestArgs are the arguments "go" was run with (e.g. "test", "-v",
+	// "-json", "./...").
+	GoTestArgs []string `json:"goTestArgs,omitempty"`
+	// Packages are the packages tested, in the order they completed.
+	Packages []Package `json:"packages"`
+	// Coverage is the code coverage, if it was measured.
+	Coverage *Coverage `json:"coverage,omitempty"`
+	// Checks are the evaluations of the thresholds (e.g. the minimum code
+	// coverage).
+	Checks []Check `json:"checks"`
+	// Errors are the errors that led to the exit status, if any.
+	Errors []Error `json:"errors"`
+	// ExitStatus is the exit status of go-opine.
+	ExitStatus int `json:"exitStatus"`
+}
+
+// Package is the result of testing a package.
+type Package struct {
+	ImportPath string `json:"importPath"`
+	// Outcome is "pass", "fail", or "skip" (e.g. when there are no test
+	// files).
+	Outcome        string  `json:"outcome"`
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	// Cached is true if the result was cached by "go test".
+	Cached bool `json:"cached"`
+	// Reason explains a failure that go-opine determined (e.g. that the
+	// test binary crashed).
+	Reason string `json:"reason,omitempty"`
+	// FailedBuild is the import path of the build that failed, if the
+	// package failed because a build failed.
+	FailedBuild string `json:"failedBuild,omitempty"`
+	// BuildErrors are the compiler and vet diagnostics of FailedBuild.
+	BuildErrors []Diagnostic `json:"buildErrors,omitempty"`
+	// Tests are the tests of the package, with each test followed by its
+	// subtests (see Test.Parent).
+	Tests []Test `json:"tests"`
+}
+
+// Diagnostic is an error reported by the compiler or "go vet".
+type Diagnostic struct {
+	// File is the path of the file relative to the working directory of
+	// "go test", unless it is outside of it.
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+	// Vet is true if "go vet" reported the diagnostic.
+	Vet bool `json:"vet"`
+}
+
+// Test is the result of a test or subtest.
+type Test struct {
+	// Name is the full name of the test (e.g. "TestFoo/case_1").
+	Name string `json:"name"`
+	// Parent is the full name of the test a subtest belongs to (e.g.
+	// "TestFoo"), or empty for a top-level test.
+	Parent string `json:"parent,omitempty"`
+	// Outcome is "pass", "fail", "skip", or "quarantined" (failed, but
+	// quarantined).
+	Outcome        string  `json:"outcome"`
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	// Reason explains a failure that go-opine determined (e.g. "timed out
+	// after 10m0s").
+	Reason string `json:"reason,omitempty"`
+	// DataRace is true if the race detector detected a data race during
+	// the test.
+	DataRace bool `json:"dataRace"`
+	// FailedItself is true if the test failed or is quarantined other than
+	// only because one of its subtests did.
+	FailedItself bool `json:"failedItself"`
+	// File and Line are the location of the test function, relative to the
+	// working directory, if known.
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Attrs []Attr `json:"attrs,omitempty"`
+	// Quarantine is the quarantine of a test with the "quarantined"
+	// outcome.
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+}
+
+// Quarantine is the quarantine of a test.
+type Quarantine struct {
+	Owner  string `json:"owner"`
+	Reason string `json:"reason"`
+	// Expires is the date (e.g. "2026-12-31") the quarantine expires.
+	Expires string `json:"expires"`
+}
+
+// Attr is an attribute of a test (see testing.T.Attr).
+type Attr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Coverage is the code coverage of a test run.
+type Coverage struct {
+	Statements
+	Packages []CoverageOf `json:"packages"`
+	Files    []CoverageOf `json:"files"`
+}
+
+// CoverageOf is the code coverage of a package or file, identified by its
+// import path (e.g. "example.com/pkg" or "example.com/pkg/foo.go").
+type CoverageOf struct {
+	Path string `json:"path"`
+	Statements
+}
+
+// Statements is the number of covered statements and the number of all
+// statements, and the ratio of the two as a percentage (100 if there are
+// no statements).
+type Statements struct {
+	Covered int     `json:"coveredStatements"`
+	Total   int     `json:"totalStatements"`
+	Percent float64 `json:"percent"`
+}
+
+// Check is the evaluation of a threshold.
+type Check struct {
+	// Name identifies the threshold: "min-coverage", "max-skip-percent",
+	// "require-tests", or "test-count-baseline". The Actual value of the
+	// latter two is the number of packages that violate them, which must
+	// not exceed the Threshold of 0.
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+// Error is an error that led to the exit status.
+type Error struct {
+	Message string `json:"message"`
+	// Category is the kind of error, which determines the exit status:
+	// "build-failed", "tests-failed", "internal-error", "usage-error",
+	// "no-tests", "report-failed", or "coverage-check-failed".
+	Category string `json:"category"`
+}
+
+// Write writes the summary as JSON to a file.
+func Write(s *Summary, outPath string) error {
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+	return os.WriteFile(outPath, out, 0666) //nolint:gosec
+}
@@ -0,0 +1,55 @@
+package summary
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Write(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "summary.json")
+	s := &Summary{
+		SchemaVersion:  SchemaVersion,
+		GoOpineVersion: "v1.2.3",
+		Packages: []Package{{
+			ImportPath: "example.com/pkg",
+			Outcome:    "pass",
+			Tests:      []Test{{Name: "TestFoo", Outcome: "pass", ElapsedSeconds: 0.5}},
+		}},
+		Coverage: &Coverage{
+			Statements: Statements{Covered: 1, Total: 2, Percent: 50},
+			Packages:   []CoverageOf{{Path: "example.com/pkg", Statements: Statements{Covered: 1, Total: 2, Percent: 50}}},
+			Files:      []CoverageOf{},
+		},
+		Checks: []Check{{Name: "min-coverage", Threshold: 40, Actual: 50, Passed: true}},
+		Errors: []Error{},
+	}
+	require.NoError(t, Write(s, outPath))
+
+	out, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(out, &got))
+	require.Equal(t, float64(1), got["schemaVersion"])
+	require.Equal(t, "v1.2.3", got["goOpineVersion"])
+	require.NotContains(t, got, "goVersion")
+	require.Equal(
+		t,
+		map[string]any{"coveredStatements": float64(1), "totalStatements": float64(2), "percent": float64(50), "packages": []any{map[string]any{"path": "example.com/pkg", "coveredStatements": float64(1), "totalStatements": float64(2), "percent": float64(50)}}, "files": []any{}},
+		got["coverage"],
+	)
+	require.Equal(t, []any{}, got["errors"])
+	require.Equal(t, float64(0), got["exitStatus"])
+
+	var roundTrip Summary
+	require.NoError(t, json.Unmarshal(out, &roundTrip))
+	require.Equal(t, *s, roundTrip)
+}
+
+func Test_Write_badPath(t *testing.T) {
+	err := Write(&Summary{}, filepath.Join(t.TempDir(), "missing", "summary.json"))
+	require.Error(t, err)
+}
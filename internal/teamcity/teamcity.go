@@ -0,0 +1,50 @@
+// Package teamcity is for writing TeamCity service messages.
+package teamcity
+
+import "strings"
+
+// Attr is a named attribute of a service message.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Message is a service message, which TeamCity reads from the output of
+// a build step.
+//
+// See https://www.jetbrains.com/help/teamcity/service-messages.html.
+type Message struct {
+	// Name is the type of message (e.g. "testStarted").
+	Name  string
+	Attrs []Attr
+}
+
+// String returns the service message, without a trailing newline.
+func (m Message) String() string {
+	var sb strings.Builder
+	sb.WriteString("##teamcity[" + m.Name)
+	for _, a := range m.Attrs {
+		sb.WriteString(" " + a.Name + "='" + escape(a.Value) + "'")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// escaper escapes the characters that have a special meaning in service
+// message attribute values.
+var escaper = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+	"\u0085", "|x",
+	"\u2028", "|l",
+	"\u2029", "|p",
+)
+
+// escape escapes an attribute value.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,27 @@
+package teamcity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Message_String(t *testing.T) {
+	m := Message{
+		Name:  "testFailed",
+		Attrs: []Attr{{Name: "name", Value: "TestFoo"}, {Name: "message", Value: "Failed"}},
+	}
+	require.Equal(t, "##teamcity[testFailed name='TestFoo' message='Failed']", m.String())
+}
+
+func Test_Message_String_noAttrs(t *testing.T) {
+	require.Equal(t, "##teamcity[enableServiceMessages]", Message{Name: "enableServiceMessages"}.String())
+}
+
+func Test_Message_String_escaping(t *testing.T) {
+	m := Message{
+		Name:  "testStdOut",
+		Attrs: []Attr{{Name: "out", Value: "it's [a|b]\r\n\u0085\u2028\u2029"}},
+	}
+	require.Equal(t, "##teamcity[testStdOut out='it|'s |[a||b|]|r|n|x|l|p']", m.String())
+}
@@ -13,6 +13,8 @@ import (
 func main() {
 	subcommands.Register(subcommands.HelpCommand(), "")
 	subcommands.Register(cmd.TestCmd(), "")
+	subcommands.Register(cmd.ReportCmd(), "")
+	subcommands.Register(cmd.ConfigCmd(), "")
 
 	flag.Parse()
 	ctx := context.Background()
@@ -0,0 +1,290 @@
+// Package opine runs Go tests the way the go-opine command does, and
+// reports their results.
+//
+// The results of the tests can be written as the go-opine command writes
+// them (e.g. QuietOutput or JUnitReport) and passed to custom reporters
+// (see Results). For example:
+//
+//	err := opine.Run(
+//	    opine.Race(),
+//	    opine.CoverProfile("cover.out"),
+//	    opine.QuietOutput(os.Stdout),
+//	    opine.JUnitReport("junit.xml"),
+//	    opine.Results(myReporter),
+//	)
+//	if errors.Is(err, opine.ErrTestsFailed) {
+//	    ...
+//	}
+//
+// Use the coverage package to check or report the coverage.
+//
+// This package, and the coverage and testresult packages, are the public
+// API of go-opine and follow semantic versioning.
+package opine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"oss.indeed.com/go/go-opine/internal/gotest"
+	"oss.indeed.com/go/go-opine/internal/junit"
+	"oss.indeed.com/go/go-opine/internal/printing"
+	"oss.indeed.com/go/go-opine/testresult"
+)
+
+var (
+	// ErrTestsFailed is matched (see errors.Is) by the error Run and
+	// Report return when a test or package failed.
+	ErrTestsFailed = gotest.ErrTestsFailed
+
+	// ErrBuildFailed is matched (see errors.Is) by the error Run and
+	// Report return when a package failed because a build failed. Such an
+	// error matches ErrTestsFailed too.
+	ErrBuildFailed = gotest.ErrBuildFailed
+)
+
+// Option can be passed to Run or Report to change how they behave (e.g.
+// test with -race, or write verbose output somewhere).
+type Option func(o *options) error
+
+type options struct {
+	gotest      []gotest.Option
+	junit       string
+	junitReport junit.Testsuites
+}
+
+// gotestOption returns an Option that passes the gotest.Option on.
+func gotestOption(opt gotest.Option) Option {
+	return func(o *options) error {
+		o.gotest = append(o.gotest, opt)
+		return nil
+	}
+}
+
+// Race runs tests with -race.
+func Race() Option {
+	return gotestOption(gotest.Race())
+}
+
+// CoverProfile runs tests with -coverprofile=<path>.
+func CoverProfile(path string) Option {
+	return gotestOption(gotest.CoverProfile(path))
+}
+
+// CoverPkg runs tests with -coverpkg=<patterns>.
+func CoverPkg(patterns string) Option {
+	return gotestOption(gotest.CoverPkg(patterns))
+}
+
+// CoverMode runs tests with -covermode=<mode>.
+func CoverMode(mode string) Option {
+	return gotestOption(gotest.CoverMode(mode))
+}
+
+// P runs tests with -p=<p>. This controls the number of test binaries
+// that can be run in parallel.
+//
+// See the -p option of "go help build" for more information.
+func P(p int) Option {
+	return gotestOption(gotest.P(p))
+}
+
+// Flags runs tests with additional flags (e.g. "-tags=integration").
+func Flags(flags ...string) Option {
+	return gotestOption(gotest.Flags(flags...))
+}
+
+// Env runs tests with additional environment variables, each of the form
+// "key=value".
+func Env(env ...string) Option {
+	return gotestOption(gotest.Env(env...))
+}
+
+// Packages tests the packages matching the patterns (e.g.
+// "./internal/...") instead of all packages (./...).
+func Packages(patterns ...string) Option {
+	return gotestOption(gotest.Packages(patterns...))
+}
+
+// JSONOutput writes the unmodified "go test -json" output to the provided
+// writer as it is read.
+func JSONOutput(to io.Writer) Option {
+	return gotestOption(gotest.JSONOutput(to))
+}
+
+// BuildWarnings reports the results of builds that did not fail but had
+// output, which is usually warnings (e.g. from the linker or cgo). By
+// default they are dropped.
+func BuildWarnings() Option {
+	return gotestOption(gotest.BuildWarnings())
+}
+
+// Quarantine quarantines tests: they still run, but their failures are
+// reported with the testresult.Quarantined outcome and do not fail the
+// run. Whether the quarantines expired is not checked.
+func Quarantine(quarantines ...testresult.Quarantine) Option {
+	tests := make([]gotest.QuarantinedTest, len(quarantines))
+	for i, q := range quarantines {
+		tests[i] = gotest.QuarantinedTest(q)
+	}
+	return gotestOption(gotest.Quarantine(tests...))
+}
+
+// QuietOutput writes output similar to "go test" (without "-v") to the
+// provided writer.
+func QuietOutput(to io.Writer) Option {
+	return gotestOption(gotest.QuietOutput(to))
+}
+
+// VerboseOutput writes output similar to "go test -v" to the provided
+// writer.
+func VerboseOutput(to io.Writer) Option {
+	return gotestOption(gotest.VerboseOutput(to))
+}
+
+// JUnitReport writes a JUnit XML report, with a testsuite for each package
+// tested, to a file once all tests complete.
+func JUnitReport(path string) Option {
+	return func(o *options) error {
+		o.junit = path
+		o.gotest = append(o.gotest, gotest.JUnitReport(&o.junitReport))
+		return nil
+	}
+}
+
+// TAPReport writes TAP version 14 test results to the provided writer,
+// with a subtest for each package.
+func TAPReport(to io.Writer) Option {
+	return gotestOption(gotest.TAPReport(to))
+}
+
+// FailureSummary writes a summary of the failed tests and packages (if
+// any) to the provided writer once all tests complete. Each failure is
+// listed with the start of its output and a "go test" command that
+// reproduces it.
+func FailureSummary(to io.Writer) Option {
+	return gotestOption(gotest.FailureSummary(to))
+}
+
+// RaceSummary writes a summary of the data races detected (if any) to the
+// provided writer once all tests complete.
+func RaceSummary(to io.Writer) Option {
+	return gotestOption(gotest.RaceSummary(to))
+}
+
+// GitHubAnnotations writes GitHub Actions workflow commands to the
+// provided writer that annotate failed tests, build failures, and data
+// races with their location. File paths in the annotations are relative to
+// root, which should be the root of the repository.
+func GitHubAnnotations(to io.Writer, root string) Option {
+	return gotestOption(gotest.GitHubAnnotations(to, root))
+}
+
+// TeamCityMessages writes TeamCity service messages to the provided
+// writer that report each package as a test suite containing its tests.
+func TeamCityMessages(to io.Writer) Option {
+	return gotestOption(gotest.TeamCityMessages(to))
+}
+
+// StatusLine is an io.Writer, usually connected to a terminal, that keeps
+// a status line (see Progress) below everything written to it. Other
+// output to the same terminal (e.g. QuietOutput) should be written to the
+// StatusLine so that the status line stays at the bottom.
+//
+// It is safe to use a StatusLine from multiple goroutines.
+type StatusLine struct {
+	w *printing.StatusLineWriter
+}
+
+var _ io.Writer = (*StatusLine)(nil)
+
+// NewStatusLine returns a StatusLine that writes to the provided writer.
+func NewStatusLine(to io.Writer) *StatusLine {
+	return &StatusLine{w: printing.NewStatusLineWriter(to)}
+}
+
+// Write writes to the underlying writer, keeping the status line below
+// what is written.
+func (s *StatusLine) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+// Clear erases the status line.
+func (s *StatusLine) Clear() error {
+	return s.w.Clear()
+}
+
+// Progress keeps a live status line showing the progress of the tests on
+// the provided StatusLine. Tests that have been running for at least slow
+// are listed in the status line. For example:
+//
+//	status := opine.NewStatusLine(os.Stdout)
+//	err := opine.Run(opine.QuietOutput(status), opine.Progress(status, 10*time.Second))
+func Progress(to *StatusLine, slow time.Duration) Option {
+	return gotestOption(gotest.Progress(to.w, slow))
+}
+
+// Results passes each result to the provided testresult.ResultAccepter,
+// which is finished once all tests complete if it is a
+// testresult.ResultFinisher. If it returns an error the tests are stopped
+// and the error is returned.
+func Results(to testresult.ResultAccepter) Option {
+	return gotestOption(gotest.Results(to))
+}
+
+// Events passes each "go test -json" event to the provided
+// testresult.EventAccepter as soon as it is read, before the results are
+// determined. If it returns an error the tests are stopped and the error
+// is returned.
+func Events(to testresult.EventAccepter) Option {
+	return gotestOption(gotest.Events(to))
+}
+
+// Run runs "go test" on all packages in the current directory (./...)
+// and reports the results. An error matching ErrTestsFailed is returned if
+// any package failed.
+func Run(opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+	return o.write(gotest.Run(o.gotest...))
+}
+
+// Report reports the results of tests from previously saved "go test
+// -json" output, as if Run had run them. Options that only change how
+// "go test" is run (e.g. Race) have no effect. An error matching
+// ErrTestsFailed is returned if any package failed.
+func Report(r io.Reader, opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+	return o.write(gotest.Report(r, o.gotest...))
+}
+
+// newOptions applies the options.
+func newOptions(opts []Option) (*options, error) {
+	var o options
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	return &o, nil
+}
+
+// write writes the reports that are written once all tests complete
+// (e.g. JUnitReport), unless the tests could not be run, and returns
+// testErr combined with any error writing them.
+func (o *options) write(testErr error) error {
+	if o.junit == "" || (testErr != nil && !errors.Is(testErr, ErrTestsFailed)) {
+		return testErr
+	}
+	if err := junit.Write(&o.junitReport, o.junit); err != nil {
+		return errors.Join(testErr, fmt.Errorf("failed to write JUnit XML: %w", err))
+	}
+	return testErr
+}
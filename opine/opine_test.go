@@ -0,0 +1,126 @@
+package opine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/go-opine/testresult"
+)
+
+const failedEvents = `{"Action":"run","Package":"pkg","Test":"TestFoo"}
+{"Action":"attr","Package":"pkg","Test":"TestFoo","Key":"issue","Value":"123"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"=== RUN   TestFoo\n"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"    foo_test.go:5: oops\n"}
+{"Action":"output","Package":"pkg","Test":"TestFoo","Output":"--- FAIL: TestFoo (0.50s)\n"}
+{"Action":"fail","Package":"pkg","Test":"TestFoo","Elapsed":0.5}
+{"Action":"output","Package":"pkg","Output":"FAIL\n"}
+{"Action":"fail","Package":"pkg","Elapsed":1}
+`
+
+type recorder struct {
+	results  []testresult.Result
+	events   []testresult.Event
+	finished bool
+}
+
+func (r *recorder) Accept(res testresult.Result) error {
+	r.results = append(r.results, res)
+	return nil
+}
+
+func (r *recorder) Finish() error {
+	r.finished = true
+	return nil
+}
+
+type eventRecorder struct {
+	r *recorder
+}
+
+func (e eventRecorder) Accept(ev testresult.Event) error {
+	e.r.events = append(e.r.events, ev)
+	return nil
+}
+
+func Test_Report(t *testing.T) {
+	var (
+		rec   recorder
+		quiet bytes.Buffer
+	)
+	junitPath := filepath.Join(t.TempDir(), "junit.xml")
+	err := Report(
+		strings.NewReader(failedEvents),
+		QuietOutput(&quiet),
+		JUnitReport(junitPath),
+		Results(&rec),
+		Events(eventRecorder{&rec}),
+	)
+	require.EqualError(t, err, "1 package failed")
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.NotErrorIs(t, err, ErrBuildFailed)
+
+	require.Contains(t, quiet.String(), "--- FAIL: TestFoo")
+	require.True(t, rec.finished)
+	require.Len(t, rec.results, 2)
+	require.Equal(t, "TestFoo", rec.results[0].Test)
+	require.Equal(t, testresult.Fail, rec.results[0].Outcome)
+	require.Equal(t, []testresult.Attr{{Key: "issue", Value: "123"}}, rec.results[0].Attrs)
+	require.Equal(t, "pkg", rec.results[1].Package)
+	require.Empty(t, rec.results[1].Test)
+	require.Len(t, rec.events, 8)
+	require.Equal(t, "run", rec.events[0].Action)
+
+	junit, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	require.Contains(t, string(junit), `<testcase classname="pkg" name="TestFoo"`)
+}
+
+func Test_Report_quarantine(t *testing.T) {
+	var rec recorder
+	quarantine := testresult.Quarantine{Package: "pkg", Test: "TestFoo", Owner: "@team", Reason: "flaky"}
+	err := Report(strings.NewReader(failedEvents), Quarantine(quarantine), Results(&rec))
+	require.NoError(t, err)
+	require.Equal(t, testresult.Quarantined, rec.results[0].Outcome)
+	require.Equal(t, &quarantine, rec.results[0].Quarantine)
+	require.Equal(t, testresult.Pass, rec.results[1].Outcome)
+}
+
+func Test_Report_junitWriteError(t *testing.T) {
+	junitPath := filepath.Join(t.TempDir(), "missing", "junit.xml")
+	err := Report(strings.NewReader(failedEvents), JUnitReport(junitPath))
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.ErrorContains(t, err, "failed to write JUnit XML: ")
+}
+
+func Test_Report_optionError(t *testing.T) {
+	err := Report(strings.NewReader(failedEvents), P(0))
+	require.EqualError(t, err, "gotest: invalid option -p: '\\x00'")
+}
+
+func Test_Run_flagsEnvAndPackages(t *testing.T) {
+	var quiet bytes.Buffer
+	err := Run(
+		Packages("../internal/gotest/testdata"),
+		Env("GOTEST_FAIL=1"),
+		Flags("-count=1"),
+		QuietOutput(&quiet),
+	)
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.Contains(t, quiet.String(), "--- FAIL: Test_Some_test (")
+	require.Contains(t, quiet.String(), "FAIL\toss.indeed.com/go/go-opine/internal/gotest/testdata\t")
+}
+
+func Test_Progress(t *testing.T) {
+	var out bytes.Buffer
+	status := NewStatusLine(&out)
+	err := Report(strings.NewReader(failedEvents), QuietOutput(status), Progress(status, time.Second))
+	require.ErrorIs(t, err, ErrTestsFailed)
+	require.NoError(t, status.Clear())
+	require.Contains(t, out.String(), "--- FAIL: TestFoo (0.50s)\n")
+}
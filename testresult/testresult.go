@@ -0,0 +1,191 @@
+// Package testresult defines the test results and events that go-opine
+// reports, for use by custom reporters (see opine.Results and
+// opine.Events).
+package testresult
+
+import (
+	"time"
+)
+
+// The outcomes of results.
+const (
+	Pass = "pass"
+	Fail = "fail"
+	Skip = "skip"
+	// Quarantined is the outcome of a test that failed but is quarantined
+	// (see opine.Quarantine), which does not fail the run.
+	Quarantined = "quarantined"
+	// BuildFail is the outcome of a build result of a build that failed.
+	BuildFail = "build-fail"
+	// BuildOutput is the outcome of a build result of a build that did not
+	// fail but had output, which is usually warnings (e.g. from the
+	// linker). These are only reported with opine.BuildWarnings.
+	BuildOutput = "build-output"
+)
+
+// Result is the result of a test, a package, or a build.
+//
+// A test result has a Package and a Test. A package result only has a
+// Package, and a build result only has an ImportPath.
+type Result struct {
+	Package string
+	// Test is the full name of the test (e.g. "TestFoo/case_1").
+	Test string
+	// ImportPath identifies the build of a build result (e.g.
+	// "example.com/pkg [example.com/pkg.test]").
+	ImportPath string
+	// Outcome is Pass, Fail, or Skip for test and package results (or
+	// Quarantined for test results), and BuildFail or BuildOutput for build
+	// results.
+	Outcome string
+	// Output is the output of the result, without the output of its
+	// subtests.
+	Output string
+	// ErrorOutput is the part of the Output that "go test" categorized as
+	// errors (e.g. from t.Error). Older versions of Go do not categorize
+	// output, in which case it is empty.
+	ErrorOutput string
+	Elapsed     time.Duration
+	// Reason explains a failure that go-opine determined rather than "go
+	// test" reported (e.g. "timed out after 10m0s").
+	Reason string
+	// Crashed is true for a package result when the test binary exited
+	// before reporting the outcome of every test (e.g. a test panicked or
+	// called os.Exit). Reason explains why.
+	Crashed bool
+	// Attrs are the attributes the test set using testing.T.Attr. The
+	// attributes of its parent tests are not included.
+	Attrs []Attr
+	// ArtifactDir is the directory the test stored artifacts in, if any
+	// (see testing.T.ArtifactDir).
+	ArtifactDir string
+	// Races are the data races reported by the race detector in the
+	// Output.
+	Races []DataRace
+	// FailedBuild is the ImportPath of the build that failed, for a
+	// package result that failed because of it.
+	FailedBuild string
+	// Diagnostics are the compiler and vet diagnostics in the Output of a
+	// build result.
+	Diagnostics []Diagnostic
+	// File and Line are the location of the declaration of the test
+	// function, or of the top level test function for a subtest, if it
+	// was found.
+	File string
+	Line int
+	// Quarantine is the quarantine of a test with the Quarantined outcome.
+	Quarantine *Quarantine
+	// Subtests are the results of the subtests of a test, in the order
+	// they completed.
+	Subtests []Result
+}
+
+// Quarantine is a quarantine of known-broken or flaky tests.
+type Quarantine struct {
+	// Package is the import path of the package of the tests, or a
+	// pattern ending in "/..." that matches the package and all packages
+	// below it.
+	Package string
+	// Test is a regular expression that must match the whole name of a
+	// test (e.g. "TestFoo" or "TestFoo/case_.*"). All tests of the package
+	// match if it is empty.
+	Test    string
+	Owner   string
+	Reason  string
+	Expires time.Time
+}
+
+// Attr is an attribute of a test set using testing.T.Attr.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// DataRace is a data race report printed by the race detector.
+type DataRace struct {
+	Current  RaceAccess
+	Previous RaceAccess
+	// Goroutines are the creation sites of the goroutines that made the
+	// accesses.
+	Goroutines []RaceGoroutine
+}
+
+// RaceAccess is one of the two memory accesses of a data race.
+type RaceAccess struct {
+	// Op describes the access (e.g. "Read" or "Previous write").
+	Op string
+	// Goroutine is the goroutine that made the access (e.g. "goroutine 9"
+	// or "main goroutine").
+	Goroutine string
+	// Stack is the stack of the access, from the innermost frame to the
+	// outermost. It is empty if the race detector could not restore it.
+	Stack []StackFrame
+}
+
+// RaceGoroutine is the creation site of a goroutine involved in a data
+// race.
+type RaceGoroutine struct {
+	ID    int
+	Stack []StackFrame
+}
+
+// StackFrame is a single frame of a stack trace.
+type StackFrame struct {
+	Func string
+	File string
+	Line int
+}
+
+// Diagnostic is an error reported by the compiler or by "go vet", which
+// "go test" runs before running the tests.
+type Diagnostic struct {
+	// File is the path of the file as printed, which is relative to the
+	// working directory of "go test" unless it is outside of it.
+	File    string
+	Line    int
+	Col     int
+	Message string
+	// Vet is true if the diagnostic was reported by "go vet" rather than
+	// the compiler.
+	Vet bool
+}
+
+// Event is a test event printed by "go test -json". See "go doc
+// test2json" for more details.
+type Event struct {
+	Time        time.Time // encodes as an RFC3339-format string
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64 // seconds
+	Output      string
+	OutputType  string
+	FailedBuild string
+	ImportPath  string
+	// Key and Value are set for "attr" events (see testing.T.Attr).
+	Key   string
+	Value string
+	// Path is set for "artifacts" events (see testing.T.ArtifactDir).
+	Path string
+}
+
+// ResultAccepter accepts results.
+//
+// The results of a package are accepted together: first the result of
+// each top level test of the package (with its subtests), then the
+// package result. A build result is accepted before the results of the
+// packages that failed because of it.
+type ResultAccepter interface {
+	Accept(res Result) error
+}
+
+// ResultFinisher is implemented by ResultAccepters that need to know when
+// all results have been accepted (e.g. to write a summary).
+type ResultFinisher interface {
+	Finish() error
+}
+
+// EventAccepter accepts events as soon as "go test" prints them.
+type EventAccepter interface {
+	Accept(e Event) error
+}